@@ -34,6 +34,9 @@ type Expression interface {
 type Program struct {
     Statements []Statement
     Path string
+    Warnings []string
+    // Doc is the doc comment of the module
+    Doc string
     PosInfo PositionalInfo
 }
 
@@ -48,3 +51,17 @@ func (p *Program) String() string {
 func (p *Program) Position() PositionalInfo {
     return p.PosInfo
 }
+
+// Exports returns the exported names of a module in the order they are declared, the second result is false
+// if the module has no export statements, then all its top level names are visible to importers
+func (p *Program) Exports() ([]string, bool) {
+    names := []string{}
+    hasExports := false
+    for _, stmt := range p.Statements {
+        if export, ok := stmt.(*ExportStatement); ok {
+            hasExports = true
+            names = append(names, export.Names()...)
+        }
+    }
+    return names, hasExports
+}
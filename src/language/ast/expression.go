@@ -3,6 +3,8 @@ package ast
 import (
     "bytes"
     "fmt"
+    "math/big"
+    "sort"
     "strconv"
     "strings"
     "language/token"
@@ -24,6 +26,39 @@ func (i *IntegerLiteralExpression) Position() PositionalInfo {
 }
 
 
+type BigIntLiteralExpression struct {
+    Value *big.Int
+    PosInfo PositionalInfo
+}
+
+func (b *BigIntLiteralExpression) expressionNode() {}
+
+func (b *BigIntLiteralExpression) String() string {
+    return b.Value.String() + "n"
+}
+
+func (b *BigIntLiteralExpression) Position() PositionalInfo {
+    return b.PosInfo
+}
+
+
+// DecimalLiteralExpression keeps the digits of the literal, so the scale of the decimal is preserved
+type DecimalLiteralExpression struct {
+    Value string
+    PosInfo PositionalInfo
+}
+
+func (d *DecimalLiteralExpression) expressionNode() {}
+
+func (d *DecimalLiteralExpression) String() string {
+    return d.Value + "d"
+}
+
+func (d *DecimalLiteralExpression) Position() PositionalInfo {
+    return d.PosInfo
+}
+
+
 type FloatLiteralExpression struct {
     Value float64
     PosInfo PositionalInfo
@@ -149,7 +184,11 @@ func (i *InfixExpression) String() string {
 
     out.WriteString("(")
     out.WriteString(i.Lhs.String())
-    out.WriteString(string(i.Op.Type))
+    if keyword, ok := token.KeywordFromType(i.Op.Type); ok {
+        out.WriteString(" " + keyword + " ")
+    } else {
+        out.WriteString(string(i.Op.Type))
+    }
     out.WriteString(i.Rhs.String())
     out.WriteString(")")
 
@@ -189,9 +228,58 @@ func (c *ConditionalExpression) Position() PositionalInfo {
 }
 
 
+// Parameter is a plain name or, if Pattern is set, a destructuring pattern.
+// A variadic parameter collects the remaining arguments in an array, its Type is the type of each of them.
+type Parameter struct {
+    Name string
+    Pattern Pattern
+    Type *TypeAnnotation
+    Default Expression
+    Variadic bool
+    PosInfo PositionalInfo
+}
+
+func (p *Parameter) String() string {
+    var out bytes.Buffer
+
+    if p.Variadic {
+        out.WriteString("...")
+    }
+    if p.Pattern != nil {
+        out.WriteString(p.Pattern.String())
+    } else {
+        out.WriteString(p.Name)
+        out.WriteString(annotationString(p.Type))
+    }
+    if p.Default != nil {
+        out.WriteString(" = ")
+        out.WriteString(p.Default.String())
+    }
+
+    return out.String()
+}
+
+func (p *Parameter) Position() PositionalInfo {
+    return p.PosInfo
+}
+
+func ParametersString(parameters []*Parameter) string {
+    params := []string{}
+    for _, p := range parameters {
+        params = append(params, p.String())
+    }
+    return strings.Join(params, ", ")
+}
+
+
 type FunctionLiteralExpression struct {
-    Parameters []string
+    Name string
+    Parameters []*Parameter
+    ReturnType *TypeAnnotation
     Body *BlockStatement
+    IsGenerator bool
+    // Doc is the doc comment of the function or of the declaration binding it
+    Doc string
     PosInfo PositionalInfo
 }
 
@@ -200,9 +288,15 @@ func (f *FunctionLiteralExpression) expressionNode() {}
 func (f *FunctionLiteralExpression) String() string {
     var out bytes.Buffer
 
-    out.WriteString("fun(")
-    out.WriteString(strings.Join(f.Parameters, ", "))
+    out.WriteString("fun")
+    if f.Name != "" {
+        out.WriteString(" ")
+        out.WriteString(f.Name)
+    }
+    out.WriteString("(")
+    out.WriteString(ParametersString(f.Parameters))
     out.WriteString(")")
+    out.WriteString(annotationString(f.ReturnType))
     out.WriteString(f.Body.String())
 
     return out.String()
@@ -216,6 +310,7 @@ func (f *FunctionLiteralExpression) Position() PositionalInfo {
 type CallExpression struct  {
     Function Expression
     Arguments []Expression
+    IsTailCall bool
     PosInfo PositionalInfo
 }
 
@@ -243,8 +338,43 @@ func (c *CallExpression) Position() PositionalInfo {
 }
 
 
+// SpreadExpression passes the elements of Value as separate arguments
+type SpreadExpression struct {
+    Value Expression
+    PosInfo PositionalInfo
+}
+
+func (s *SpreadExpression) expressionNode() {}
+
+func (s *SpreadExpression) String() string {
+    return "..." + s.Value.String()
+}
+
+func (s *SpreadExpression) Position() PositionalInfo {
+    return s.PosInfo
+}
+
+
+type NamedArgumentExpression struct {
+    Name string
+    Value Expression
+    PosInfo PositionalInfo
+}
+
+func (n *NamedArgumentExpression) expressionNode() {}
+
+func (n *NamedArgumentExpression) String() string {
+    return n.Name + ": " + n.Value.String()
+}
+
+func (n *NamedArgumentExpression) Position() PositionalInfo {
+    return n.PosInfo
+}
+
+
 type ArrayLiteral struct {
     Elements []Expression
+    Frozen bool
     PosInfo PositionalInfo
 }
 
@@ -258,6 +388,9 @@ func (a *ArrayLiteral) String() string {
     for _, e := range a.Elements {
         elements = append(elements, e.String())
     }
+    if a.Frozen {
+        out.WriteString("#")
+    }
     out.WriteString("[")
     out.WriteString(strings.Join(elements, ", "))
     out.WriteString("]")
@@ -299,6 +432,8 @@ func (a *AssignExpression) Position() PositionalInfo {
 type IndexExpression struct {
     Left Expression
     Index Expression
+    // Property is set for a.b, which is parsed as a["b"]
+    Property bool
     PosInfo PositionalInfo
 }
 
@@ -323,6 +458,7 @@ func (i *IndexExpression) Position() PositionalInfo {
 
 type HashLiteral struct {
     Pairs map[Expression]Expression
+    Frozen bool
     PosInfo PositionalInfo
 }
 
@@ -336,7 +472,12 @@ func (h *HashLiteral) String() string {
     for k, v := range h.Pairs {
         pairs = append(pairs, k.String() + ": " + v.String())
     }
+    // the pairs are sorted to print the same literal the same way
+    sort.Strings(pairs)
 
+    if h.Frozen {
+        out.WriteString("#")
+    }
     out.WriteString("{")
     out.WriteString(strings.Join(pairs, ", "))
     out.WriteString("}")
@@ -348,3 +489,94 @@ func (h *HashLiteral) Position() PositionalInfo {
     return h.PosInfo
 }
 
+
+
+type RangeExpression struct {
+    Start Expression
+    End Expression
+    Step Expression
+    Inclusive bool
+    PosInfo PositionalInfo
+}
+
+func (r *RangeExpression) expressionNode() {}
+
+func (r *RangeExpression) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("(")
+    out.WriteString(r.Start.String())
+    if r.Inclusive {
+        out.WriteString("..=")
+    } else {
+        out.WriteString("..")
+    }
+    out.WriteString(r.End.String())
+    if r.Step != nil {
+        out.WriteString(" step ")
+        out.WriteString(r.Step.String())
+    }
+    out.WriteString(")")
+
+    return out.String()
+}
+
+func (r *RangeExpression) Position() PositionalInfo {
+    return r.PosInfo
+}
+
+
+type MatchArm struct {
+    Pattern Pattern
+    Guard Expression
+    Body Statement
+    PosInfo PositionalInfo
+}
+
+func (m *MatchArm) String() string {
+    var out bytes.Buffer
+
+    out.WriteString(m.Pattern.String())
+    if m.Guard != nil {
+        out.WriteString(" if ")
+        out.WriteString(m.Guard.String())
+    }
+    out.WriteString(" => ")
+    out.WriteString(m.Body.String())
+
+    return out.String()
+}
+
+func (m *MatchArm) Position() PositionalInfo {
+    return m.PosInfo
+}
+
+
+type MatchExpression struct {
+    Subject Expression
+    Arms []*MatchArm
+    PosInfo PositionalInfo
+}
+
+func (m *MatchExpression) expressionNode() {}
+
+func (m *MatchExpression) String() string {
+    var out bytes.Buffer
+
+    arms := []string{}
+    for _, a := range m.Arms {
+        arms = append(arms, a.String())
+    }
+
+    out.WriteString("match ")
+    out.WriteString(m.Subject.String())
+    out.WriteString(" { ")
+    out.WriteString(strings.Join(arms, " "))
+    out.WriteString(" }")
+
+    return out.String()
+}
+
+func (m *MatchExpression) Position() PositionalInfo {
+    return m.PosInfo
+}
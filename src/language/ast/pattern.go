@@ -0,0 +1,196 @@
+package ast
+
+import (
+    "bytes"
+    "strings"
+)
+
+type Pattern interface {
+    Node
+    patternNode()
+}
+
+
+type WildcardPattern struct {
+    PosInfo PositionalInfo
+}
+
+func (w *WildcardPattern) patternNode() {}
+
+func (w *WildcardPattern) String() string {
+    return "_"
+}
+
+func (w *WildcardPattern) Position() PositionalInfo {
+    return w.PosInfo
+}
+
+
+type IdentifierPattern struct {
+    Name string
+    PosInfo PositionalInfo
+}
+
+func (i *IdentifierPattern) patternNode() {}
+
+func (i *IdentifierPattern) String() string {
+    return i.Name
+}
+
+func (i *IdentifierPattern) Position() PositionalInfo {
+    return i.PosInfo
+}
+
+
+type LiteralPattern struct {
+    Value Expression
+    PosInfo PositionalInfo
+}
+
+func (l *LiteralPattern) patternNode() {}
+
+func (l *LiteralPattern) String() string {
+    return l.Value.String()
+}
+
+func (l *LiteralPattern) Position() PositionalInfo {
+    return l.PosInfo
+}
+
+
+type RangePattern struct {
+    Start Expression
+    End Expression
+    Inclusive bool
+    PosInfo PositionalInfo
+}
+
+func (r *RangePattern) patternNode() {}
+
+func (r *RangePattern) String() string {
+    var out bytes.Buffer
+
+    out.WriteString(r.Start.String())
+    if r.Inclusive {
+        out.WriteString("..=")
+    } else {
+        out.WriteString("..")
+    }
+    out.WriteString(r.End.String())
+
+    return out.String()
+}
+
+func (r *RangePattern) Position() PositionalInfo {
+    return r.PosInfo
+}
+
+
+// TypePattern matches values of a type and binds them to Name, unless Name is _
+type TypePattern struct {
+    Name string
+    TypeName string
+    PosInfo PositionalInfo
+}
+
+func (t *TypePattern) patternNode() {}
+
+func (t *TypePattern) String() string {
+    return t.Name + ": " + t.TypeName
+}
+
+func (t *TypePattern) Position() PositionalInfo {
+    return t.PosInfo
+}
+
+
+// ArrayPattern matches arrays element by element, Rest collects the remaining elements if HasRest is set
+type ArrayPattern struct {
+    Elements []Pattern
+    HasRest bool
+    Rest string
+    PosInfo PositionalInfo
+}
+
+func (a *ArrayPattern) patternNode() {}
+
+func (a *ArrayPattern) String() string {
+    var out bytes.Buffer
+
+    elements := []string{}
+    for _, e := range a.Elements {
+        elements = append(elements, e.String())
+    }
+    if a.HasRest {
+        elements = append(elements, "..." + a.Rest)
+    }
+
+    out.WriteString("[")
+    out.WriteString(strings.Join(elements, ", "))
+    out.WriteString("]")
+
+    return out.String()
+}
+
+func (a *ArrayPattern) Position() PositionalInfo {
+    return a.PosInfo
+}
+
+
+// HashPattern matches hashes which contain all Keys and whose values match the corresponding Values
+type HashPattern struct {
+    Keys []Expression
+    Values []Pattern
+    PosInfo PositionalInfo
+}
+
+func (h *HashPattern) patternNode() {}
+
+func (h *HashPattern) String() string {
+    var out bytes.Buffer
+
+    pairs := []string{}
+    for i, k := range h.Keys {
+        pairs = append(pairs, k.String() + ": " + h.Values[i].String())
+    }
+
+    out.WriteString("{")
+    out.WriteString(strings.Join(pairs, ", "))
+    out.WriteString("}")
+
+    return out.String()
+}
+
+func (h *HashPattern) Position() PositionalInfo {
+    return h.PosInfo
+}
+
+// PatternNames returns the names a pattern binds
+func PatternNames(pattern Pattern) []string {
+    names := []string{}
+    switch pattern := pattern.(type) {
+    case *IdentifierPattern:
+        names = append(names, pattern.Name)
+    case *TypePattern:
+        names = append(names, pattern.Name)
+    case *ArrayPattern:
+        for _, element := range pattern.Elements {
+            names = append(names, PatternNames(element)...)
+        }
+        if pattern.HasRest {
+            names = append(names, pattern.Rest)
+        }
+    case *HashPattern:
+        for _, value := range pattern.Values {
+            names = append(names, PatternNames(value)...)
+        }
+    }
+
+    result := []string{}
+    for _, name := range names {
+        if name != "_" {
+            result = append(result, name)
+        }
+    }
+    return result
+}
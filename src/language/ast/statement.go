@@ -2,6 +2,7 @@ package ast
 
 import (
     "bytes"
+    "strings"
 )
 
 type ExpressionStatement struct {
@@ -81,7 +82,10 @@ func (t *TryCatchStatement) Position() PositionalInfo {
 
 type LetStatement struct {
     Name string
+    Pattern Pattern
+    Type *TypeAnnotation
     Initializer Expression
+    Doc string
     PosInfo PositionalInfo
 }
 
@@ -91,7 +95,12 @@ func (l *LetStatement) String() string {
     var out bytes.Buffer
 
     out.WriteString("let ")
-    out.WriteString(l.Name)
+    if l.Pattern != nil {
+        out.WriteString(l.Pattern.String())
+    } else {
+        out.WriteString(l.Name)
+        out.WriteString(annotationString(l.Type))
+    }
     out.WriteString(" = ")
     out.WriteString(l.Initializer.String())
     out.WriteString(";")
@@ -106,7 +115,10 @@ func (l *LetStatement) Position() PositionalInfo {
 
 type ConstStatement struct {
     Name string
+    Pattern Pattern
+    Type *TypeAnnotation
     Initializer Expression
+    Doc string
     PosInfo PositionalInfo
 }
 
@@ -116,7 +128,12 @@ func (c *ConstStatement) String() string {
     var out bytes.Buffer
 
     out.WriteString("const ")
-    out.WriteString(c.Name)
+    if c.Pattern != nil {
+        out.WriteString(c.Pattern.String())
+    } else {
+        out.WriteString(c.Name)
+        out.WriteString(annotationString(c.Type))
+    }
     out.WriteString(" = ")
     out.WriteString(c.Initializer.String())
     out.WriteString(";")
@@ -176,6 +193,28 @@ func (r *ReturnStatement) Position() PositionalInfo {
 }
 
 
+type YieldStatement struct {
+    Value Expression
+    PosInfo PositionalInfo
+}
+
+func (y *YieldStatement) statementNode() {}
+
+func (y *YieldStatement) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("yield ")
+    out.WriteString(y.Value.String())
+    out.WriteString(";")
+
+    return out.String()
+}
+
+func (y *YieldStatement) Position() PositionalInfo {
+    return y.PosInfo
+}
+
+
 type BreakStatement struct {
     PosInfo PositionalInfo
 }
@@ -231,6 +270,7 @@ func (w *WhileStatement) Position() PositionalInfo {
 
 type RangeLoopStatement struct {
     Name string
+    Pattern Pattern
     RangeExpr Expression
     Body *BlockStatement
     PosInfo PositionalInfo
@@ -242,7 +282,11 @@ func (r *RangeLoopStatement) String() string {
     var out bytes.Buffer
 
     out.WriteString("loop ")
-    out.WriteString(r.Name)
+    if r.Pattern != nil {
+        out.WriteString(r.Pattern.String())
+    } else {
+        out.WriteString(r.Name)
+    }
     out.WriteString(" in ")
     out.WriteString(r.RangeExpr.String())
     out.WriteString(r.Body.String())
@@ -258,6 +302,7 @@ func (r *RangeLoopStatement) Position() PositionalInfo {
 type KVRangeLoopStatement struct {
     IndexName string
     ElementName string
+    ElementPattern Pattern
     RangeExpr Expression
     Body *BlockStatement
     PosInfo PositionalInfo
@@ -271,7 +316,11 @@ func (k *KVRangeLoopStatement) String() string {
     out.WriteString("loop ")
     out.WriteString(k.IndexName)
     out.WriteString(", ")
-    out.WriteString(k.ElementName)
+    if k.ElementPattern != nil {
+        out.WriteString(k.ElementPattern.String())
+    } else {
+        out.WriteString(k.ElementName)
+    }
     out.WriteString(" in ")
     out.WriteString(k.RangeExpr.String())
     out.WriteString(k.Body.String())
@@ -284,9 +333,34 @@ func (k *KVRangeLoopStatement) Position() PositionalInfo {
 }
 
 
+// ImportName is a name imported by a from-import, Alias is empty if the name is not renamed
+type ImportName struct {
+    Name string
+    Alias string
+}
+
+// Binding returns the name the import is bound to in the importing module
+func (i ImportName) Binding() string {
+    if i.Alias != "" {
+        return i.Alias
+    }
+    return i.Name
+}
+
+func (i ImportName) String() string {
+    if i.Alias != "" {
+        return i.Name + " as " + i.Alias
+    }
+    return i.Name
+}
+
+// ImportStatement binds the module at Path to Name, or only the given Names of it for a from-import
 type ImportStatement struct {
     Path string
     Name string
+    Names []ImportName
+    // Wildcard imports all names visible to importers, like from "path" import *
+    Wildcard bool
     PosInfo PositionalInfo
 }
 
@@ -295,6 +369,21 @@ func (i *ImportStatement) statementNode() {}
 func(i *ImportStatement) String() string {
     var out bytes.Buffer
 
+    if i.Wildcard {
+        return "from " + i.Path + " import *"
+    }
+    if i.IsSelective() {
+        names := []string{}
+        for _, name := range i.Names {
+            names = append(names, name.String())
+        }
+        out.WriteString("from ")
+        out.WriteString(i.Path)
+        out.WriteString(" import ")
+        out.WriteString(strings.Join(names, ", "))
+        return out.String()
+    }
+
     out.WriteString("import ")
     out.WriteString(i.Path)
     out.WriteString(" as ")
@@ -307,3 +396,81 @@ func (i *ImportStatement) Position() PositionalInfo {
     return i.PosInfo
 }
 
+// IsSelective reports whether only some names of the module are imported
+func (i *ImportStatement) IsSelective() bool {
+    return i.Names != nil
+}
+
+// ImportGroupStatement holds the imports of a grouped import like import ("a.fml", "b.fml" as b)
+type ImportGroupStatement struct {
+    Imports []*ImportStatement
+    PosInfo PositionalInfo
+}
+
+func (i *ImportGroupStatement) statementNode() {}
+
+func (i *ImportGroupStatement) String() string {
+    imports := []string{}
+    for _, imp := range i.Imports {
+        imports = append(imports, imp.Path + " as " + imp.Name)
+    }
+    return "import (" + strings.Join(imports, ", ") + ")"
+}
+
+func (i *ImportGroupStatement) Position() PositionalInfo {
+    return i.PosInfo
+}
+
+
+
+// ExportStatement makes the names declared by a top level let, const or function declaration visible to importers
+type ExportStatement struct {
+    Statement Statement
+    PosInfo PositionalInfo
+}
+
+func (e *ExportStatement) statementNode() {}
+
+func (e *ExportStatement) String() string {
+    return "export " + e.Statement.String()
+}
+
+func (e *ExportStatement) Position() PositionalInfo {
+    return e.PosInfo
+}
+
+// Names returns the names declared by the exported statement
+func (e *ExportStatement) Names() []string {
+    switch stmt := e.Statement.(type) {
+    case *LetStatement:
+        if stmt.Pattern != nil {
+            return PatternNames(stmt.Pattern)
+        }
+        return []string{stmt.Name}
+    case *ConstStatement:
+        if stmt.Pattern != nil {
+            return PatternNames(stmt.Pattern)
+        }
+        return []string{stmt.Name}
+    case *FunctionDeclarationStatement:
+        return []string{stmt.Function.Name}
+    }
+    return []string{}
+}
+
+
+// FunctionDeclarationStatement binds a named function in the enclosing block, the binding is hoisted to the start of the block
+type FunctionDeclarationStatement struct {
+    Function *FunctionLiteralExpression
+    PosInfo PositionalInfo
+}
+
+func (f *FunctionDeclarationStatement) statementNode() {}
+
+func (f *FunctionDeclarationStatement) String() string {
+    return f.Function.String()
+}
+
+func (f *FunctionDeclarationStatement) Position() PositionalInfo {
+    return f.PosInfo
+}
@@ -0,0 +1,38 @@
+package ast
+
+import (
+    "strings"
+)
+
+// TypeAnnotation is the declared type of a name, parameter or return value like int, string | null or any,
+// a value has the type if its type is one of Names
+type TypeAnnotation struct {
+    Names []string
+    PosInfo PositionalInfo
+}
+
+func (t *TypeAnnotation) String() string {
+    return strings.Join(t.Names, " | ")
+}
+
+func (t *TypeAnnotation) Position() PositionalInfo {
+    return t.PosInfo
+}
+
+// IsAny reports whether every value has the type
+func (t *TypeAnnotation) IsAny() bool {
+    for _, name := range t.Names {
+        if name == "any" {
+            return true
+        }
+    }
+    return false
+}
+
+// annotationString returns the annotation of a name, it is empty if the type is not declared
+func annotationString(t *TypeAnnotation) string {
+    if t == nil {
+        return ""
+    }
+    return ": " + t.String()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "language/bundle"
+    "language/frontend"
+    "language/run"
+)
+
+var buildCommand = &command{
+    usage: "build [-o executable] main.fml",
+    description: "bundle a program with all of its imports into a copy of the interpreter",
+    setup: func(flags *flag.FlagSet) func(args []string) int {
+        target := flags.String("o", "", "name of the executable, defaults to the name of the program")
+        return func(args []string) int {
+            args, err := parseInterspersed(flags, args)
+            if err != nil {
+                return 2
+            }
+            if len(args) != 1 {
+                return usageError(flags)
+            }
+            return exitCode(build(args[0], *target))
+        }
+    },
+}
+
+// build bundles a program with all of its imports into a copy of the interpreter
+func build(path string, target string) error {
+    if target == "" {
+        base := filepath.Base(path)
+        target = strings.TrimSuffix(base, filepath.Ext(base))
+    }
+
+    collected, errs := bundle.Collect(path)
+    if len(errs) > 0 {
+        messages := []string{}
+        for _, err := range errs {
+            messages = append(messages, err.Error())
+        }
+        return errors.New(strings.Join(messages, "\n"))
+    }
+    interpreter, err := os.Executable()
+    if err != nil {
+        return err
+    }
+    if err := bundle.Write(collected, interpreter, target); err != nil {
+        return err
+    }
+    fmt.Printf("bundled %d modules into %s\n", len(collected.Sources), target)
+    return nil
+}
+
+// runBundle runs the program bundled into the executable, it returns false if there is none
+func runBundle() bool {
+    executable, err := os.Executable()
+    if err != nil {
+        return false
+    }
+    collected, err := bundle.Read(executable)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+        os.Exit(1)
+    }
+    if collected == nil {
+        return false
+    }
+    frontend.BUNDLE = collected
+    os.Exit(run.Run(collected.Main, os.Args[1:]))
+    return true
+}
@@ -0,0 +1,148 @@
+package bundle
+
+import (
+    "bytes"
+    "encoding/binary"
+    "encoding/gob"
+    "errors"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "language/ast"
+    "language/eval"
+    "language/frontend"
+    "language/project"
+)
+
+// MAGIC ends an executable with an appended bundle, it is preceded by the length of the encoded bundle
+const MAGIC = "FMLBUNDLE"
+
+const trailerLength = 8 + len(MAGIC)
+
+// Collect reads the module at path and all modules it imports transitively
+func Collect(path string) (*frontend.Bundle, []error) {
+    path, err := filepath.Abs(path)
+    if err != nil {
+        return nil, []error{err}
+    }
+    manifest, err := project.Find(filepath.Dir(path))
+    if err != nil {
+        return nil, []error{err}
+    }
+    oldProject := eval.PROJECT
+    eval.PROJECT = manifest
+    defer func() {
+        eval.PROJECT = oldProject
+    }()
+
+    bundle := frontend.NewBundle(path)
+    pending := []string{path}
+    for len(pending) > 0 {
+        current := pending[0]
+        pending = pending[1:]
+        if _, ok := bundle.Sources[current]; ok {
+            continue
+        }
+        code, err := ioutil.ReadFile(current)
+        if err != nil {
+            return nil, []error{err}
+        }
+        program, errs := frontend.Build(current)
+        if len(errs) > 0 {
+            return nil, errs
+        }
+        bundle.Sources[current] = string(code)
+        dir := filepath.Dir(current)
+        for _, imp := range imports(program) {
+            if eval.IsNativeModule(imp.Path) {
+                continue
+            }
+            resolved, err := filepath.Abs(eval.ResolveImport(imp.Path, dir))
+            if err != nil {
+                return nil, []error{err}
+            }
+            bundle.AddImport(dir, imp.Path, resolved)
+            pending = append(pending, resolved)
+        }
+    }
+    return bundle, nil
+}
+
+func imports(program *ast.Program) []*ast.ImportStatement {
+    result := []*ast.ImportStatement{}
+    for _, stmt := range program.Statements {
+        switch stmt := stmt.(type) {
+        case *ast.ImportStatement:
+            result = append(result, stmt)
+        case *ast.ImportGroupStatement:
+            result = append(result, stmt.Imports...)
+        }
+    }
+    return result
+}
+
+// Write copies the interpreter at interpreter to target and appends the bundle to it
+func Write(bundle *frontend.Bundle, interpreter string, target string) error {
+    executable, err := ioutil.ReadFile(interpreter)
+    if err != nil {
+        return err
+    }
+    // an interpreter which is a bundle itself is reused without its bundle
+    if length, ok := bundleLength(executable); ok && length <= uint64(len(executable) - trailerLength) {
+        executable = executable[:len(executable) - trailerLength - int(length)]
+    }
+
+    var encoded bytes.Buffer
+    if err := gob.NewEncoder(&encoded).Encode(bundle); err != nil {
+        return err
+    }
+    var out bytes.Buffer
+    out.Write(executable)
+    out.Write(encoded.Bytes())
+    binary.Write(&out, binary.LittleEndian, uint64(encoded.Len()))
+    out.WriteString(MAGIC)
+    return ioutil.WriteFile(target, out.Bytes(), 0755)
+}
+
+// Read returns the bundle appended to the executable at path, it returns nil if there is none
+func Read(path string) (*frontend.Bundle, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+    info, err := file.Stat()
+    if err != nil {
+        return nil, err
+    }
+    if info.Size() < int64(trailerLength) {
+        return nil, nil
+    }
+    trailer := make([]byte, trailerLength)
+    if _, err := file.ReadAt(trailer, info.Size() - int64(trailerLength)); err != nil {
+        return nil, err
+    }
+    length, ok := bundleLength(trailer)
+    if !ok {
+        return nil, nil
+    }
+    if length > uint64(info.Size() - int64(trailerLength)) {
+        return nil, errors.New("the bundle of the executable is broken")
+    }
+    start := info.Size() - int64(trailerLength) - int64(length)
+    bundle := &frontend.Bundle{}
+    if err := gob.NewDecoder(io.NewSectionReader(file, start, int64(length))).Decode(bundle); err != nil {
+        return nil, fmt.Errorf("the bundle of the executable is broken: %s", err)
+    }
+    return bundle, nil
+}
+
+// bundleLength returns the length of the bundle in front of the trailer at the end of content
+func bundleLength(content []byte) (uint64, bool) {
+    if len(content) < trailerLength || string(content[len(content) - len(MAGIC):]) != MAGIC {
+        return 0, false
+    }
+    return binary.LittleEndian.Uint64(content[len(content) - trailerLength:]), true
+}
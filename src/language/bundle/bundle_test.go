@@ -0,0 +1,136 @@
+package bundle
+
+import (
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "testing"
+    "language/eval"
+    "language/frontend"
+    "language/object"
+)
+
+func init() {
+    frontend.CACHE = false
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+    for name, content := range files {
+        path := filepath.Join(root, filepath.FromSlash(name))
+        if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+            t.Fatalf("could not create directory: %s", err)
+        }
+        if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+            t.Fatalf("could not write file: %s", err)
+        }
+    }
+}
+
+func TestCollect(t *testing.T) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    defer os.RemoveAll(dir)
+    writeFiles(t, dir, map[string]string{
+        "app/main.fml": "import (\"lib/a.fml\", \"core.fml\");\nfrom \"lib/a.fml\" import value;\nlet result = value + core.value;",
+        "app/lib/a.fml": "import \"b.fml\" as b;\nexport let value = b.value;",
+        "app/lib/b.fml": "let value = 40;",
+        "core/core.fml": "let value = 2;",
+    })
+    oldFmlpath := eval.FMLPATH
+    eval.FMLPATH = filepath.Join(dir, "core")
+    defer func() {
+        eval.FMLPATH = oldFmlpath
+    }()
+
+    main := filepath.Join(dir, "app", "main.fml")
+    collected, errs := Collect(main)
+    if len(errs) > 0 {
+        t.Fatalf("unexpected errors: %v", errs)
+    }
+    for _, name := range []string{"app/main.fml", "app/lib/a.fml", "app/lib/b.fml", "core/core.fml"} {
+        if _, ok := collected.Sources[filepath.Join(dir, filepath.FromSlash(name))]; !ok {
+            t.Fatalf("expected %s to be bundled", name)
+        }
+    }
+    if len(collected.Sources) != 4 || collected.Main != main {
+        t.Fatalf("expected 4 modules with main %s but got %d with %s", main, len(collected.Sources), collected.Main)
+    }
+
+    // the bundle runs without the files
+    if err := os.RemoveAll(filepath.Join(dir, "app")); err != nil {
+        t.Fatalf("could not remove sources: %s", err)
+    }
+    frontend.BUNDLE = collected
+    defer func() {
+        frontend.BUNDLE = nil
+    }()
+    program, errs := frontend.Build(main)
+    if len(errs) > 0 {
+        t.Fatalf("unexpected errors: %v", errs)
+    }
+    eval.MODULEPATH = filepath.Dir(main)
+    env := object.NewEnvironment()
+    modules := map[string]*object.Module{main: &object.Module{Path: main, Env: env}}
+    if result := eval.Eval(program, env, modules); result.Type() == object.ERROR_OBJECT {
+        t.Fatalf("unexpected error: %s", result.String())
+    }
+    result, _ := env.Get("result")
+    if result == nil || result.String() != "42" {
+        t.Fatalf("expected 42 but got %v", result)
+    }
+}
+
+func TestWriteAndRead(t *testing.T) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    defer os.RemoveAll(dir)
+    interpreter := filepath.Join(dir, "interpreter")
+    writeFiles(t, dir, map[string]string{"interpreter": "not really an executable"})
+
+    if bundle, err := Read(interpreter); bundle != nil || err != nil {
+        t.Fatalf("expected no bundle but got %v, %v", bundle, err)
+    }
+
+    first := frontend.NewBundle("/app/main.fml")
+    first.Sources["/app/main.fml"] = "import \"a.fml\";"
+    first.Sources["/app/a.fml"] = "let a = 1;"
+    first.AddImport("/app", "a.fml", "/app/a.fml")
+    tool := filepath.Join(dir, "tool")
+    if err := Write(first, interpreter, tool); err != nil {
+        t.Fatalf("could not write bundle: %s", err)
+    }
+    read, err := Read(tool)
+    if err != nil || read == nil {
+        t.Fatalf("expected a bundle but got %v", err)
+    }
+    if read.Main != first.Main || read.Sources["/app/a.fml"] != "let a = 1;" {
+        t.Fatalf("expected the written bundle but got %v", read)
+    }
+    if resolved, ok := read.Resolve("/app", "a.fml"); !ok || resolved != "/app/a.fml" {
+        t.Fatalf("expected a.fml to resolve to /app/a.fml but got %s", resolved)
+    }
+
+    // the bundle of an executable used as the tool is replaced, it does not end up in the new bundle
+    second := frontend.NewBundle("/other/main.fml")
+    second.Sources["/other/main.fml"] = "let b = 2;"
+    other := filepath.Join(dir, "other")
+    if err := Write(second, tool, other); err != nil {
+        t.Fatalf("could not write bundle: %s", err)
+    }
+    content, err := ioutil.ReadFile(other)
+    if err != nil {
+        t.Fatalf("could not read bundle: %s", err)
+    }
+    toolLength, _ := bundleLength(content)
+    if string(content[:len(content) - trailerLength - int(toolLength)]) != "not really an executable" {
+        t.Fatalf("expected the previous bundle to be replaced")
+    }
+    read, err = Read(other)
+    if err != nil || read == nil || read.Main != "/other/main.fml" || len(read.Sources) != 1 {
+        t.Fatalf("expected the second bundle but got %v, %v", read, err)
+    }
+}
@@ -0,0 +1,97 @@
+package check
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "language/ast"
+)
+
+// the rules of the checker, they are used to disable and suppress diagnostics
+const (
+    UNUSED_VARIABLE = "unused-variable"
+    UNUSED_IMPORT = "unused-import"
+    CONST_ASSIGNMENT = "const-assignment"
+    ARITY = "arity"
+    UNREACHABLE = "unreachable"
+    SHADOW = "shadow"
+    UNKNOWN_BUILTIN = "unknown-builtin"
+    LITERAL_COMPARISON = "literal-comparison"
+    TYPE_MISMATCH = "type"
+)
+
+var RULES = map[string]string{
+    UNUSED_VARIABLE: "local variables and constants which are never read",
+    UNUSED_IMPORT: "imported modules and names which are never used",
+    CONST_ASSIGNMENT: "assignments to constants, functions and modules",
+    ARITY: "calls of known functions with the wrong number of arguments",
+    UNREACHABLE: "statements after return, break and continue",
+    SHADOW: "local declarations hiding a name of an enclosing function or block, module level declarations hiding a builtin",
+    UNKNOWN_BUILTIN: "unknown names which are similar to a builtin",
+    LITERAL_COMPARISON: "== and != between literals of different types",
+    TYPE_MISMATCH: "values which do not have the annotated type of a declaration, parameter or return value",
+}
+
+// errorRules fail at runtime, the other rules only report suspicious code
+var errorRules = map[string]bool{CONST_ASSIGNMENT: true, ARITY: true, TYPE_MISMATCH: true}
+
+// Diagnostic is a finding of a rule at a position in the checked program
+type Diagnostic struct {
+    Rule string
+    Message string
+    PosInfo ast.PositionalInfo
+}
+
+func (d Diagnostic) Severity() string {
+    if errorRules[d.Rule] {
+        return "error"
+    }
+    return "warning"
+}
+
+func (d Diagnostic) String() string {
+    return fmt.Sprintf("%s: %s: %s [%s]", d.PosInfo.String(), d.Severity(), d.Message, d.Rule)
+}
+
+// Config selects the rules which are checked
+type Config struct {
+    Disabled map[string]bool
+}
+
+// ParseRules parses a comma separated list of rules
+func ParseRules(list string) (map[string]bool, error) {
+    rules := map[string]bool{}
+    for _, rule := range strings.Split(list, ",") {
+        rule = strings.TrimSpace(rule)
+        if rule == "" {
+            continue
+        }
+        if _, ok := RULES[rule]; !ok {
+            return nil, fmt.Errorf("unknown rule %s", rule)
+        }
+        rules[rule] = true
+    }
+    return rules, nil
+}
+
+// Check analyses program without running it, source is the code of the program and is searched for suppressions
+func Check(program *ast.Program, source string, config Config) []Diagnostic {
+    c := newChecker()
+    c.checkProgram(program)
+
+    suppressions := parseSuppressions(source)
+    result := []Diagnostic{}
+    for _, diagnostic := range c.diagnostics {
+        if config.Disabled[diagnostic.Rule] || suppressions.suppresses(diagnostic) {
+            continue
+        }
+        result = append(result, diagnostic)
+    }
+    sort.SliceStable(result, func(i, j int) bool {
+        if result[i].PosInfo.Line != result[j].PosInfo.Line {
+            return result[i].PosInfo.Line < result[j].PosInfo.Line
+        }
+        return result[i].PosInfo.Column < result[j].PosInfo.Column
+    })
+    return result
+}
@@ -0,0 +1,156 @@
+package check
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+    "language/parser"
+    "language/scanner"
+)
+
+func checkCode(t *testing.T, input string, config Config) []string {
+    t.Helper()
+    p := parser.New(scanner.New(input), "test")
+    program, errs := p.Parse()
+    if len(errs) > 0 {
+        t.Fatalf("unexpected parser errors in %s: %v", input, errs)
+    }
+    result := []string{}
+    for _, diagnostic := range Check(program, input, config) {
+        result = append(result, fmt.Sprintf("%d:%d %s", diagnostic.PosInfo.Line, diagnostic.PosInfo.Column, diagnostic.Rule))
+    }
+    return result
+}
+
+func TestRules(t *testing.T) {
+    tests := []struct {
+        input string
+        expected []string
+    }{
+        // unused variables and imports
+        {"let a = 1; const f = fun() { let b = 2; const c = 3; return c; };", []string{"1:30 unused-variable"}},
+        {"const f = fun(unused) { let _ignored = 1; fun helper() {} };", []string{"1:43 unused-variable"}},
+        {"import \"a.fml\" as a;\nimport \"b.fml\" as b;\nb.run();", []string{"1:1 unused-import"}},
+        {"from \"a.fml\" import x, y as z;\nprintln(z);", []string{"1:1 unused-import"}},
+        {"import \"os\";\nprintln(os.args);", []string{}},
+        {"from \"a.fml\" import *;\nprintln(lenn);", []string{}},
+        {"const f = fun() { let a = 1; a = 2; };", []string{"1:19 unused-variable"}},
+        {"const f = fun() { let a = 1; a += 2; };", []string{}},
+        // assignments to constants
+        {"const a = 1;\na = 2;", []string{"2:1 const-assignment"}},
+        {"fun f() {}\nf = 1;", []string{"2:1 const-assignment"}},
+        {"import \"a.fml\" as m;\nm = 1;", []string{"1:1 unused-import", "2:1 const-assignment"}},
+        {"len = 1;", []string{"1:1 const-assignment"}},
+        {"let a = 1;\na = 2;\nconst f = fun(b) { a = b; };", []string{}},
+        {"const f = fun(a) {\na = 3;\n};", []string{"2:1 const-assignment"}},
+        // arity of known functions
+        {"fun f(a, b) {}\nf(1);\nf(1, 2);\nf(1, 2, 3);", []string{"2:2 arity", "4:2 arity"}},
+        {"const f = fun(a, b = 1, ...c) {};\nf();\nf(1);\nf(1, 2, 3, 4);", []string{"2:2 arity"}},
+        {"fun f(a, b = 1) {}\nf(1, 2, 3);\nf(...[1, 2, 3]);\nf(b = 2);", []string{"2:2 arity"}},
+        {"let f = fun(a) {};\nf();", []string{}},
+        // unreachable code
+        {"const f = fun() {\nreturn 1;\nprintln(1);\nprintln(2);\n};", []string{"3:8 unreachable"}},
+        {"const f = fun(x) {\nif x { return 1; } else { return 2; }\nreturn 3;\n};", []string{"3:1 unreachable"}},
+        {"const f = fun(x) {\nif x { return 1; }\nreturn 3;\n};", []string{}},
+        {"loop x in [1] {\ncontinue;\nprintln(x);\n}", []string{"3:8 unreachable"}},
+        {"const f = fun() {\nreturn g();\nfun g() { return 1; }\n};", []string{}},
+        // shadowed names
+        {"const f = fun(a) {\nlet a = 1;\nreturn a;\n};", []string{"2:1 shadow"}},
+        {"const f = fun(x) {\nloop x in [1] { println(x); }\n};", []string{"2:1 shadow"}},
+        {"let a = 1;\nconst f = fun(a) { return a; };\nprintln(a);", []string{}},
+        {"fun first(x) { return x; }", []string{"1:1 shadow"}},
+        // typos in builtin names
+        {"prinln(1);", []string{"1:1 unknown-builtin"}},
+        {"println(lenn([1]));", []string{"1:9 unknown-builtin"}},
+        {"println(somethingElse);", []string{}},
+        {"let prinln = println;\nprinln(1);", []string{}},
+        // comparisons of literals
+        {"println(1 == \"1\", true != null, 1 == 1.0, \"a\" == \"b\");", []string{"1:11 literal-comparison", "1:24 literal-comparison"}},
+        // functions can use names declared after them
+        {"const f = fun() { return g(1); };\nconst g = fun(x) { return x; };\nf();", []string{}},
+        {"const f = fun() { return g(); };\nconst g = fun(x) { return x; };", []string{"1:27 arity"}},
+        // patterns and match arms
+        {"const f = fun(x) {\nreturn match x {\n[a, b] => a,\n_ => x\n};\n};", []string{}},
+        {"const f = fun() {\nlet [a, b] = [1, 2];\nreturn a;\n};", []string{"2:1 unused-variable"}},
+    }
+
+    for _, tt := range tests {
+        result := checkCode(t, tt.input, Config{})
+        if strings.Join(result, ", ") != strings.Join(tt.expected, ", ") {
+            t.Errorf("expected %v for %s but got %v", tt.expected, tt.input, result)
+        }
+    }
+}
+
+func TestTypes(t *testing.T) {
+    tests := []struct {
+        input string
+        expected []string
+    }{
+        // declarations
+        {"let a: int = 1;\nconst b: string = 2;\nlet c: float? = null;", []string{"2:19 type"}},
+        {"let a: int | string = 1.5;\nlet b: any = 1;", []string{"1:23 type"}},
+        {"let a: int = f();\nlet b: int = 1 < 2;", []string{"2:16 type"}},
+        // inference for locals
+        {"const a = \"text\";\nlet b: int = a;", []string{"2:14 type"}},
+        {"let a = 1;\nlet b: int = a;\nlet c: string = a + a;", []string{"3:19 type"}},
+        {"let a = 1;\na = \"text\";\nlet b: int = a;", []string{}},
+        {"let a: int = 1 + 2;\nlet b: int = a * a - 1;", []string{}},
+        {"const f = fun(): string { return \"s\"; };\nlet a: int = f();", []string{"2:15 type"}},
+        {"let a: bool = isInt(1);\nlet b: array = [1];\nlet c: int = len(\"s\") ? 1 : \"s\";", []string{"3:23 type"}},
+        // assignments
+        {"let a: int = 1;\na = \"text\";\na += 1;", []string{"2:5 type"}},
+        {"let a: int? = 1;\na = null;", []string{}},
+        // arguments
+        {"fun f(a: int, b: string = \"\") {}\nf(\"1\");\nf(1, 2);\nf(1, \"2\");", []string{"2:3 type", "3:6 type"}},
+        {"fun f(a, ...rest: int) {}\nf(\"a\", 1, 2.5);", []string{"2:11 type"}},
+        {"fun f(a: int = \"1\") {}\nf();", []string{"1:16 type"}},
+        {"const f = fun(a: int) { return a; };\nconst g = fun(x) { return f(x); };", []string{}},
+        // return values
+        {"fun f(a: int): string {\nif a > 0 { return a; }\nreturn \"a\";\n}", []string{"2:12 type"}},
+        {"fun f(): int {\nconst g = fun() { return \"nested\"; };\ng();\nreturn 1;\n}", []string{}},
+        {"fun f(): int {\nreturn;\n}", []string{"2:1 type"}},
+        {"fun add2(x: int, y: int): int {\nreturn x + y;\n}", []string{}},
+        {"fun f(): iterator {\nyield 1;\nreturn \"done\";\n}\nlet g: iterator = f();", []string{}},
+    }
+
+    for _, tt := range tests {
+        result := checkCode(t, tt.input, Config{})
+        if strings.Join(result, ", ") != strings.Join(tt.expected, ", ") {
+            t.Errorf("expected %v for %s but got %v", tt.expected, tt.input, result)
+        }
+    }
+}
+
+func TestSuppressions(t *testing.T) {
+    tests := []struct {
+        input string
+        config Config
+        expected []string
+    }{
+        {"len = 1; // fml:ignore\nfirst = 1;", Config{}, []string{"2:1 const-assignment"}},
+        {"// fml:ignore const-assignment\nlen = 1;\nfirst = 1;", Config{}, []string{"3:1 const-assignment"}},
+        {"len = 1; // fml:ignore arity\n", Config{}, []string{"1:1 const-assignment"}},
+        {"len = 1; // fml:ignore arity, const-assignment\n", Config{}, []string{}},
+        {"// fml:ignore-file const-assignment\nlen = 1;\nprinln(1);", Config{}, []string{"3:1 unknown-builtin"}},
+        {"prinln(1);\n// fml:ignore-file\n", Config{}, []string{}},
+        {"len = 1;\nprinln(1);", Config{Disabled: map[string]bool{UNKNOWN_BUILTIN: true}}, []string{"1:1 const-assignment"}},
+    }
+
+    for _, tt := range tests {
+        result := checkCode(t, tt.input, tt.config)
+        if strings.Join(result, ", ") != strings.Join(tt.expected, ", ") {
+            t.Errorf("expected %v for %s but got %v", tt.expected, tt.input, result)
+        }
+    }
+}
+
+func TestParseRules(t *testing.T) {
+    rules, err := ParseRules("shadow, arity,")
+    if err != nil || len(rules) != 2 || !rules[SHADOW] || !rules[ARITY] {
+        t.Fatalf("expected shadow and arity but got %v, %v", rules, err)
+    }
+    if _, err := ParseRules("shadow,typo"); err == nil || err.Error() != "unknown rule typo" {
+        t.Fatalf("expected an unknown rule error but got %v", err)
+    }
+}
@@ -0,0 +1,537 @@
+package check
+
+import (
+    "fmt"
+    "strings"
+    "language/ast"
+    "language/eval"
+    "language/token"
+)
+
+var kindNames = map[bindingKind]string{
+    CONSTANT: "a constant",
+    FUNCTION: "a function declaration",
+    PARAMETER: "a parameter",
+    IMPORT: "an import",
+}
+
+var assignments = map[token.TokenType]bool{
+    token.ASSIGN: true,
+    token.ADDASSIGN: true,
+    token.SUBASSIGN: true,
+    token.MULTASSIGN: true,
+    token.DIVASSIGN: true,
+    token.MODASSIGN: true,
+    token.BITANDASSIGN: true,
+    token.BITORASSIGN: true,
+    token.BITXORASSIGN: true,
+    token.SHLASSIGN: true,
+    token.SHRASSIGN: true,
+}
+
+type checker struct {
+    builtins []string
+    isBuiltin map[string]bool
+    // reassigned are the names assigned anywhere in the program
+    reassigned map[string]bool
+    // functions are the function literals enclosing the checked code
+    functions []*ast.FunctionLiteralExpression
+    // wildcardImport is set if names are imported by from "path" import *, unknown names may come from the module then
+    wildcardImport bool
+    diagnostics []Diagnostic
+}
+
+func newChecker() *checker {
+    c := &checker{builtins: eval.BuiltinNames(), isBuiltin: map[string]bool{}, reassigned: map[string]bool{}}
+    for _, name := range c.builtins {
+        c.isBuiltin[name] = true
+    }
+    return c
+}
+
+func (c *checker) report(rule string, posInfo ast.PositionalInfo, format string, a ...interface{}) {
+    c.diagnostics = append(c.diagnostics, Diagnostic{Rule: rule, Message: fmt.Sprintf(format, a...), PosInfo: posInfo})
+}
+
+func (c *checker) checkProgram(program *ast.Program) {
+    assignedNames(program, c.reassigned)
+    module := newScope(nil)
+    c.checkStatements(program.Statements, module)
+    c.closeScope(module)
+}
+
+// declare binds name in s, a local declaration hiding a name of an enclosing function or block
+// and a module level declaration hiding a builtin are reported
+func (c *checker) declare(s *scope, b *binding) {
+    if s.isModule() && b.Kind != IMPORT && c.isBuiltin[b.Name] {
+        c.report(SHADOW, b.PosInfo, "%s shadows the builtin %s", b.Name, b.Name)
+    } else if !s.isModule() && b.Name != "_" {
+        if previous, ok := s.parent.lookupLocal(b.Name); ok {
+            c.report(SHADOW, b.PosInfo, "%s shadows the declaration in line %d", b.Name, previous.PosInfo.Line)
+        }
+    }
+    s.declare(b)
+}
+
+func (c *checker) declarePattern(s *scope, pattern ast.Pattern, kind bindingKind, posInfo ast.PositionalInfo) {
+    c.checkPatternExpressions(pattern, s)
+    for _, name := range ast.PatternNames(pattern) {
+        c.declare(s, &binding{Name: name, Kind: kind, PosInfo: posInfo})
+    }
+}
+
+// closeScope checks the functions of s and reports the names of s which are never used
+func (c *checker) closeScope(s *scope) {
+    for len(s.pending) > 0 {
+        pending := s.pending[0]
+        s.pending = s.pending[1:]
+        c.checkFunction(pending.function, pending.scope)
+    }
+    for _, b := range s.order {
+        if b.Used || b.Exported || strings.HasPrefix(b.Name, "_") {
+            continue
+        }
+        switch b.Kind {
+        case IMPORT:
+            c.report(UNUSED_IMPORT, b.PosInfo, "%s is imported but never used", b.Name)
+        case VARIABLE, CONSTANT, FUNCTION:
+            if !s.isModule() {
+                c.report(UNUSED_VARIABLE, b.PosInfo, "%s is declared but never used", b.Name)
+            }
+        }
+    }
+}
+
+func (c *checker) checkFunction(function *ast.FunctionLiteralExpression, s *scope) {
+    c.functions = append(c.functions, function)
+    defer func() {
+        c.functions = c.functions[:len(c.functions) - 1]
+    }()
+    functionScope := newScope(s)
+    for _, param := range function.Parameters {
+        if param.Default != nil {
+            c.checkExpression(param.Default, functionScope)
+            if param.Type != nil {
+                c.checkDeclarationType(param.Name, param.Type, param.Default, functionScope)
+            }
+        }
+        if param.Pattern != nil {
+            c.declarePattern(functionScope, param.Pattern, PARAMETER, param.Position())
+        } else if param.Variadic {
+            c.declare(functionScope, &binding{Name: param.Name, Kind: PARAMETER, Type: newType("array"), Declared: true, PosInfo: param.Position()})
+        } else {
+            c.declare(functionScope, &binding{Name: param.Name, Kind: PARAMETER, Type: annotationType(param.Type), Declared: param.Type != nil, PosInfo: param.Position()})
+        }
+    }
+    c.checkBlock(function.Body, functionScope)
+    c.closeScope(functionScope)
+}
+
+func (c *checker) checkBlock(block *ast.BlockStatement, s *scope) {
+    if block == nil {
+        return
+    }
+    blockScope := newScope(s)
+    c.checkStatements(block.Statements, blockScope)
+    c.closeScope(blockScope)
+}
+
+// checkStatements checks the statements of a block in s, function declarations are hoisted like in the evaluator
+func (c *checker) checkStatements(statements []ast.Statement, s *scope) {
+    for _, stmt := range statements {
+        exported := false
+        if export, ok := stmt.(*ast.ExportStatement); ok {
+            stmt, exported = export.Statement, true
+        }
+        if declaration, ok := stmt.(*ast.FunctionDeclarationStatement); ok {
+            function := declaration.Function
+            c.declare(s, &binding{Name: function.Name, Kind: FUNCTION, Function: function, Type: newType("function"), Exported: exported, PosInfo: declaration.Position()})
+        }
+    }
+
+    terminated := false
+    for _, stmt := range statements {
+        if _, ok := stmt.(*ast.FunctionDeclarationStatement); terminated && !ok {
+            c.report(UNREACHABLE, stmt.Position(), "unreachable code")
+            terminated = false
+        }
+        c.checkStatement(stmt, s)
+        if terminates(stmt) {
+            terminated = true
+        }
+    }
+}
+
+func (c *checker) checkStatement(stmt ast.Statement, s *scope) {
+    switch stmt := stmt.(type) {
+    case *ast.ExpressionStatement:
+        c.checkExpression(stmt.Expr, s)
+    case *ast.LetStatement:
+        c.checkExpression(stmt.Initializer, s)
+        if stmt.Pattern != nil {
+            c.declarePattern(s, stmt.Pattern, VARIABLE, stmt.Position())
+            return
+        }
+        b := &binding{Name: stmt.Name, Kind: VARIABLE, PosInfo: stmt.Position()}
+        if stmt.Type != nil {
+            c.checkDeclarationType(stmt.Name, stmt.Type, stmt.Initializer, s)
+            b.Type, b.Declared = annotationType(stmt.Type), true
+        } else if !c.reassigned[stmt.Name] {
+            b.Type = c.inferType(stmt.Initializer, s)
+        }
+        c.declare(s, b)
+    case *ast.ConstStatement:
+        c.checkExpression(stmt.Initializer, s)
+        if stmt.Pattern != nil {
+            c.declarePattern(s, stmt.Pattern, CONSTANT, stmt.Position())
+            return
+        }
+        function, _ := stmt.Initializer.(*ast.FunctionLiteralExpression)
+        b := &binding{Name: stmt.Name, Kind: CONSTANT, Function: function, Type: c.inferType(stmt.Initializer, s), PosInfo: stmt.Position()}
+        if stmt.Type != nil {
+            c.checkDeclarationType(stmt.Name, stmt.Type, stmt.Initializer, s)
+            b.Type, b.Declared = annotationType(stmt.Type), true
+        }
+        c.declare(s, b)
+    case *ast.FunctionDeclarationStatement:
+        // the name is already bound by checkStatements
+        s.pending = append(s.pending, pendingFunction{function: stmt.Function, scope: s})
+    case *ast.ExportStatement:
+        c.checkStatement(stmt.Statement, s)
+        for _, name := range stmt.Names() {
+            if b, ok := s.names[name]; ok {
+                b.Exported = true
+            }
+        }
+    case *ast.ImportStatement:
+        c.declareImport(stmt, s)
+    case *ast.ImportGroupStatement:
+        for _, imp := range stmt.Imports {
+            c.declareImport(imp, s)
+        }
+    case *ast.BlockStatement:
+        c.checkBlock(stmt, s)
+    case *ast.IfStatement:
+        c.checkExpression(stmt.Cond, s)
+        c.checkBlock(stmt.Then, s)
+        c.checkBlock(stmt.Else, s)
+    case *ast.TryCatchStatement:
+        c.checkBlock(stmt.Try, s)
+        catchScope := newScope(s)
+        c.declare(catchScope, &binding{Name: stmt.Info, Kind: LOCAL, Type: newType("string"), PosInfo: stmt.Catch.Position()})
+        c.checkBlock(stmt.Catch, catchScope)
+        c.closeScope(catchScope)
+    case *ast.WhileStatement:
+        c.checkExpression(stmt.Head, s)
+        c.checkBlock(stmt.Body, s)
+    case *ast.RangeLoopStatement:
+        c.checkExpression(stmt.RangeExpr, s)
+        loopScope := newScope(s)
+        if stmt.Pattern != nil {
+            c.declarePattern(loopScope, stmt.Pattern, LOCAL, stmt.Position())
+        } else {
+            c.declare(loopScope, &binding{Name: stmt.Name, Kind: LOCAL, PosInfo: stmt.Position()})
+        }
+        c.checkBlock(stmt.Body, loopScope)
+        c.closeScope(loopScope)
+    case *ast.KVRangeLoopStatement:
+        c.checkExpression(stmt.RangeExpr, s)
+        loopScope := newScope(s)
+        c.declare(loopScope, &binding{Name: stmt.IndexName, Kind: LOCAL, PosInfo: stmt.Position()})
+        if stmt.ElementPattern != nil {
+            c.declarePattern(loopScope, stmt.ElementPattern, LOCAL, stmt.Position())
+        } else {
+            c.declare(loopScope, &binding{Name: stmt.ElementName, Kind: LOCAL, PosInfo: stmt.Position()})
+        }
+        c.checkBlock(stmt.Body, loopScope)
+        c.closeScope(loopScope)
+    case *ast.ReturnStatement:
+        c.checkExpression(stmt.Result, s)
+        c.checkReturnType(stmt, s)
+    case *ast.YieldStatement:
+        c.checkExpression(stmt.Value, s)
+    }
+}
+
+func (c *checker) declareImport(imp *ast.ImportStatement, s *scope) {
+    if imp.Wildcard {
+        c.wildcardImport = true
+        return
+    }
+    if !imp.IsSelective() {
+        c.declare(s, &binding{Name: imp.Name, Kind: IMPORT, Type: newType("module"), PosInfo: imp.Position()})
+        return
+    }
+    for _, name := range imp.Names {
+        c.declare(s, &binding{Name: name.Binding(), Kind: IMPORT, PosInfo: imp.Position()})
+    }
+}
+
+func (c *checker) checkExpression(expr ast.Expression, s *scope) {
+    switch expr := expr.(type) {
+    case *ast.IdentifierExpression:
+        c.use(expr, s)
+    case *ast.UnaryExpression:
+        c.checkExpression(expr.Rhs, s)
+    case *ast.InfixExpression:
+        if assignments[expr.Op.Type] {
+            c.checkAssign(expr, s)
+            return
+        }
+        c.checkExpression(expr.Lhs, s)
+        c.checkExpression(expr.Rhs, s)
+        c.checkComparison(expr)
+    case *ast.ConditionalExpression:
+        c.checkExpression(expr.Cond, s)
+        c.checkExpression(expr.Then, s)
+        c.checkExpression(expr.Else, s)
+    case *ast.FunctionLiteralExpression:
+        s.pending = append(s.pending, pendingFunction{function: expr, scope: s})
+    case *ast.CallExpression:
+        c.checkExpression(expr.Function, s)
+        for _, arg := range expr.Arguments {
+            c.checkExpression(arg, s)
+        }
+        if b, ok := c.knownFunction(expr, s); ok {
+            c.checkArity(expr, b)
+            c.checkArgumentTypes(expr, b.Function, b.Name, s)
+        }
+    case *ast.SpreadExpression:
+        c.checkExpression(expr.Value, s)
+    case *ast.NamedArgumentExpression:
+        c.checkExpression(expr.Value, s)
+    case *ast.ArrayLiteral:
+        for _, element := range expr.Elements {
+            c.checkExpression(element, s)
+        }
+    case *ast.HashLiteral:
+        for key, value := range expr.Pairs {
+            c.checkExpression(key, s)
+            c.checkExpression(value, s)
+        }
+    case *ast.IndexExpression:
+        c.checkExpression(expr.Left, s)
+        c.checkExpression(expr.Index, s)
+    case *ast.RangeExpression:
+        c.checkExpression(expr.Start, s)
+        c.checkExpression(expr.End, s)
+        c.checkExpression(expr.Step, s)
+    case *ast.MatchExpression:
+        c.checkExpression(expr.Subject, s)
+        for _, arm := range expr.Arms {
+            armScope := newScope(s)
+            c.declarePattern(armScope, arm.Pattern, LOCAL, arm.Position())
+            c.checkExpression(arm.Guard, armScope)
+            c.checkStatement(arm.Body, armScope)
+            c.closeScope(armScope)
+        }
+    }
+}
+
+// checkPatternExpressions checks the values a pattern compares with
+func (c *checker) checkPatternExpressions(pattern ast.Pattern, s *scope) {
+    switch pattern := pattern.(type) {
+    case *ast.LiteralPattern:
+        c.checkExpression(pattern.Value, s)
+    case *ast.RangePattern:
+        c.checkExpression(pattern.Start, s)
+        c.checkExpression(pattern.End, s)
+    case *ast.ArrayPattern:
+        for _, element := range pattern.Elements {
+            c.checkPatternExpressions(element, s)
+        }
+    case *ast.HashPattern:
+        for i, key := range pattern.Keys {
+            c.checkExpression(key, s)
+            c.checkPatternExpressions(pattern.Values[i], s)
+        }
+    }
+}
+
+// use marks the binding of identifier as used, unknown names similar to a builtin are reported
+func (c *checker) use(identifier *ast.IdentifierExpression, s *scope) {
+    if b, ok := s.lookup(identifier.Name); ok {
+        b.Used = true
+        return
+    }
+    if c.isBuiltin[identifier.Name] || c.wildcardImport {
+        return
+    }
+    if suggestion, ok := c.similarBuiltin(identifier.Name); ok {
+        c.report(UNKNOWN_BUILTIN, identifier.Position(), "unknown name %s, did you mean %s?", identifier.Name, suggestion)
+    }
+}
+
+func (c *checker) checkAssign(assign *ast.InfixExpression, s *scope) {
+    identifier, ok := assign.Lhs.(*ast.IdentifierExpression)
+    if !ok {
+        c.checkExpression(assign.Lhs, s)
+        c.checkExpression(assign.Rhs, s)
+        return
+    }
+    // a plain assignment does not read the variable
+    if assign.Op.Type != token.ASSIGN {
+        c.use(identifier, s)
+    }
+    c.checkExpression(assign.Rhs, s)
+
+    b, ok := s.lookup(identifier.Name)
+    if !ok {
+        if c.isBuiltin[identifier.Name] {
+            c.report(CONST_ASSIGNMENT, identifier.Position(), "cannot assign %s, it is a builtin", identifier.Name)
+        }
+        return
+    }
+    if kind, ok := kindNames[b.Kind]; ok {
+        c.report(CONST_ASSIGNMENT, identifier.Position(), "cannot assign %s, it is %s in line %d", identifier.Name, kind, b.PosInfo.Line)
+        return
+    }
+    if b.Declared && assign.Op.Type == token.ASSIGN {
+        if actual := c.inferType(assign.Rhs, s); !actual.assignableTo(b.Type) {
+            c.report(TYPE_MISMATCH, assign.Rhs.Position(), "cannot assign %s to %s of type %s", actual, identifier.Name, b.Type)
+        }
+    }
+}
+
+// knownFunction returns the binding of the function called by call if its parameters are known
+// and the arguments are passed by position
+func (c *checker) knownFunction(call *ast.CallExpression, s *scope) (*binding, bool) {
+    identifier, ok := call.Function.(*ast.IdentifierExpression)
+    if !ok {
+        return nil, false
+    }
+    b, ok := s.lookup(identifier.Name)
+    if !ok || b.Function == nil {
+        return nil, false
+    }
+    for _, arg := range call.Arguments {
+        switch arg.(type) {
+        case *ast.SpreadExpression, *ast.NamedArgumentExpression:
+            return nil, false
+        }
+    }
+    return b, true
+}
+
+// checkArity compares the number of arguments with the parameters of a call of a known function
+func (c *checker) checkArity(call *ast.CallExpression, b *binding) {
+    parameters := b.Function.Parameters
+    required := 0
+    variadic := false
+    for _, p := range parameters {
+        if p.Variadic {
+            variadic = true
+        } else if p.Default == nil {
+            required++
+        }
+    }
+    got := len(call.Arguments)
+    if got >= required && (variadic || got <= len(parameters)) {
+        return
+    }
+
+    var wanted string
+    if variadic {
+        wanted = fmt.Sprintf("at least %d", required)
+    } else if len(parameters) > required {
+        wanted = fmt.Sprintf("%d to %d", required, len(parameters))
+    } else {
+        wanted = fmt.Sprintf("%d", required)
+    }
+    c.report(ARITY, call.Position(), "wrong number of arguments in call of %s, wanted %s, got %d", signature(b.Name, b.Function), wanted, got)
+}
+
+// checkComparison reports == and != between literals which can never be equal
+func (c *checker) checkComparison(infix *ast.InfixExpression) {
+    if infix.Op.Type != token.EQ && infix.Op.Type != token.NEQ {
+        return
+    }
+    lhs, lhsIsLiteral := literalType(infix.Lhs)
+    rhs, rhsIsLiteral := literalType(infix.Rhs)
+    if !lhsIsLiteral || !rhsIsLiteral || lhs == rhs {
+        return
+    }
+    c.report(LITERAL_COMPARISON, infix.Position(), "comparison of %s and %s literals is always %t", lhs, rhs, infix.Op.Type == token.NEQ)
+}
+
+func literalType(expr ast.Expression) (string, bool) {
+    switch expr.(type) {
+    case *ast.IntegerLiteralExpression, *ast.FloatLiteralExpression, *ast.BigIntLiteralExpression, *ast.DecimalLiteralExpression:
+        return "number", true
+    case *ast.StringLiteralExpression:
+        return "string", true
+    case *ast.BoolLiteralExpression:
+        return "bool", true
+    case *ast.NullLiteralExpression:
+        return "null", true
+    }
+    return "", false
+}
+
+// terminates reports whether the statements after stmt are never run
+func terminates(stmt ast.Statement) bool {
+    switch stmt := stmt.(type) {
+    case *ast.ReturnStatement, *ast.BreakStatement, *ast.ContinueStatement:
+        return true
+    case *ast.BlockStatement:
+        return blockTerminates(stmt)
+    case *ast.IfStatement:
+        return blockTerminates(stmt.Then) && blockTerminates(stmt.Else)
+    case *ast.TryCatchStatement:
+        return blockTerminates(stmt.Try) && blockTerminates(stmt.Catch)
+    }
+    return false
+}
+
+func blockTerminates(block *ast.BlockStatement) bool {
+    if block == nil {
+        return false
+    }
+    for _, stmt := range block.Statements {
+        if terminates(stmt) {
+            return true
+        }
+    }
+    return false
+}
+
+// similarBuiltin returns the builtin closest to name if it differs in at most two characters
+func (c *checker) similarBuiltin(name string) (string, bool) {
+    best, bestDistance := "", 3
+    if len(name) <= 4 {
+        bestDistance = 2
+    }
+    for _, builtin := range c.builtins {
+        if distance := editDistance(name, builtin); distance < bestDistance {
+            best, bestDistance = builtin, distance
+        }
+    }
+    return best, best != ""
+}
+
+func editDistance(a string, b string) int {
+    lhs, rhs := []rune(a), []rune(b)
+    previous := make([]int, len(rhs) + 1)
+    current := make([]int, len(rhs) + 1)
+    for j := range previous {
+        previous[j] = j
+    }
+    for i := 1; i <= len(lhs); i++ {
+        current[0] = i
+        for j := 1; j <= len(rhs); j++ {
+            cost := 1
+            if lhs[i - 1] == rhs[j - 1] {
+                cost = 0
+            }
+            current[j] = previous[j - 1] + cost
+            if previous[j] + 1 < current[j] {
+                current[j] = previous[j] + 1
+            }
+            if current[j - 1] + 1 < current[j] {
+                current[j] = current[j - 1] + 1
+            }
+        }
+        previous, current = current, previous
+    }
+    return previous[len(rhs)]
+}
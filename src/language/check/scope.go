@@ -0,0 +1,76 @@
+package check
+
+import (
+    "language/ast"
+)
+
+type bindingKind int
+
+const (
+    VARIABLE bindingKind = iota
+    CONSTANT
+    FUNCTION
+    IMPORT
+    PARAMETER
+    // LOCAL are names bound by loops, catch clauses and match arms
+    LOCAL
+)
+
+// binding is a declared name, Function is set if the name is bound to a function literal for good,
+// Type is the annotated type if Declared is set and the inferred type otherwise
+type binding struct {
+    Name string
+    Kind bindingKind
+    Function *ast.FunctionLiteralExpression
+    Type valueType
+    Declared bool
+    Used bool
+    Exported bool
+    PosInfo ast.PositionalInfo
+}
+
+// pendingFunction is a function literal whose body is checked when its scope is closed,
+// so that it can refer to names declared after it
+type pendingFunction struct {
+    function *ast.FunctionLiteralExpression
+    scope *scope
+}
+
+type scope struct {
+    parent *scope
+    names map[string]*binding
+    order []*binding
+    pending []pendingFunction
+}
+
+func newScope(parent *scope) *scope {
+    return &scope{parent: parent, names: map[string]*binding{}}
+}
+
+func (s *scope) isModule() bool {
+    return s.parent == nil
+}
+
+func (s *scope) declare(b *binding) {
+    s.names[b.Name] = b
+    s.order = append(s.order, b)
+}
+
+func (s *scope) lookup(name string) (*binding, bool) {
+    for current := s; current != nil; current = current.parent {
+        if b, ok := current.names[name]; ok {
+            return b, true
+        }
+    }
+    return nil, false
+}
+
+// lookupLocal is like lookup but ignores the names declared at module level
+func (s *scope) lookupLocal(name string) (*binding, bool) {
+    for current := s; current != nil && !current.isModule(); current = current.parent {
+        if b, ok := current.names[name]; ok {
+            return b, true
+        }
+    }
+    return nil, false
+}
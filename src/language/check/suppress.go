@@ -0,0 +1,61 @@
+package check
+
+import (
+    "regexp"
+    "strings"
+)
+
+// suppressionPattern matches comments like // fml:ignore unused-variable, shadow
+// and // fml:ignore-file arity, without rules all rules are suppressed
+var suppressionPattern = regexp.MustCompile(`//\s*fml:ignore(-file)?\b([^\n]*)`)
+
+// suppressions are the rules suppressed by comments, an empty set suppresses all rules
+type suppressions struct {
+    lines map[int]map[string]bool
+    file map[string]bool
+    wholeFile bool
+}
+
+// parseSuppressions finds the suppression comments of source, a comment applies to its own line
+// or, if nothing but the comment is on the line, to the next line
+func parseSuppressions(source string) *suppressions {
+    result := &suppressions{lines: map[int]map[string]bool{}, file: map[string]bool{}}
+    for i, line := range strings.Split(source, "\n") {
+        match := suppressionPattern.FindStringSubmatchIndex(line)
+        if match == nil {
+            continue
+        }
+        rules := parseSuppressedRules(line[match[4]:match[5]])
+        if match[2] >= 0 {
+            if len(rules) == 0 {
+                result.wholeFile = true
+            }
+            for rule := range rules {
+                result.file[rule] = true
+            }
+            continue
+        }
+        lineNumber := i + 1
+        if strings.TrimSpace(line[:match[0]]) == "" {
+            lineNumber++
+        }
+        result.lines[lineNumber] = rules
+    }
+    return result
+}
+
+func parseSuppressedRules(list string) map[string]bool {
+    rules := map[string]bool{}
+    for _, rule := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+        rules[rule] = true
+    }
+    return rules
+}
+
+func (s *suppressions) suppresses(diagnostic Diagnostic) bool {
+    if s.wholeFile || s.file[diagnostic.Rule] {
+        return true
+    }
+    rules, ok := s.lines[diagnostic.PosInfo.Line]
+    return ok && (len(rules) == 0 || rules[diagnostic.Rule])
+}
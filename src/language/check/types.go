@@ -0,0 +1,316 @@
+package check
+
+import (
+    "sort"
+    "strings"
+    "language/ast"
+    "language/token"
+)
+
+// valueType is the set of type names a value can have, nil is the type of values which can be anything
+type valueType []string
+
+func newType(names ...string) valueType {
+    seen := map[string]bool{}
+    result := valueType{}
+    for _, name := range names {
+        if name == "any" {
+            return nil
+        }
+        if !seen[name] {
+            seen[name] = true
+            result = append(result, name)
+        }
+    }
+    sort.Strings(result)
+    return result
+}
+
+func annotationType(annotation *ast.TypeAnnotation) valueType {
+    if annotation == nil {
+        return nil
+    }
+    return newType(annotation.Names...)
+}
+
+func (t valueType) String() string {
+    if t == nil {
+        return "any"
+    }
+    return strings.Join(t, " | ")
+}
+
+// assignableTo reports whether every value of type t has the type declared, values of unknown type are assignable
+func (t valueType) assignableTo(declared valueType) bool {
+    if t == nil || declared == nil {
+        return true
+    }
+    for _, name := range t {
+        found := false
+        for _, declaredName := range declared {
+            found = found || name == declaredName
+        }
+        if !found {
+            return false
+        }
+    }
+    return true
+}
+
+func union(lhs valueType, rhs valueType) valueType {
+    if lhs == nil || rhs == nil {
+        return nil
+    }
+    return newType(append(append([]string{}, lhs...), rhs...)...)
+}
+
+// builtinTypes are the types of the results of builtins which always return the same type
+var builtinTypes = map[string]valueType{
+    "len": newType("int"),
+    "str": newType("string"),
+    "substring": newType("string"),
+    "readline": newType("string"),
+    "int": newType("int"),
+    "float": newType("float"),
+    "bigint": newType("bigint"),
+    "decimal": newType("decimal"),
+    "makeArray": newType("array"),
+    "toArray": newType("array"),
+    "exports": newType("array"),
+    "set": newType("set"),
+    "union": newType("set"),
+    "intersection": newType("set"),
+    "difference": newType("set"),
+    "deque": newType("deque"),
+    "range": newType("range"),
+    "iter": newType("iterator"),
+    "print": newType("null"),
+    "println": newType("null"),
+    "help": newType("null"),
+}
+
+// inferType returns the type of expr if it can be known without running the program
+func (c *checker) inferType(expr ast.Expression, s *scope) valueType {
+    switch expr := expr.(type) {
+    case *ast.IntegerLiteralExpression:
+        return newType("int")
+    case *ast.BigIntLiteralExpression:
+        return newType("bigint")
+    case *ast.FloatLiteralExpression:
+        return newType("float")
+    case *ast.DecimalLiteralExpression:
+        return newType("decimal")
+    case *ast.StringLiteralExpression:
+        return newType("string")
+    case *ast.BoolLiteralExpression:
+        return newType("bool")
+    case *ast.NullLiteralExpression:
+        return newType("null")
+    case *ast.ArrayLiteral:
+        return newType("array")
+    case *ast.HashLiteral:
+        return newType("hash")
+    case *ast.RangeExpression:
+        return newType("range")
+    case *ast.FunctionLiteralExpression:
+        return newType("function")
+    case *ast.IdentifierExpression:
+        if b, ok := s.lookup(expr.Name); ok {
+            return b.Type
+        }
+        if c.isBuiltin[expr.Name] {
+            return newType("function")
+        }
+    case *ast.CallExpression:
+        identifier, ok := expr.Function.(*ast.IdentifierExpression)
+        if !ok {
+            return nil
+        }
+        if b, ok := s.lookup(identifier.Name); ok {
+            if b.Function == nil {
+                return nil
+            }
+            if b.Function.IsGenerator {
+                return newType("iterator")
+            }
+            return annotationType(b.Function.ReturnType)
+        }
+        if strings.HasPrefix(identifier.Name, "is") && c.isBuiltin[identifier.Name] {
+            return newType("bool")
+        }
+        return builtinTypes[identifier.Name]
+    case *ast.UnaryExpression:
+        if expr.Op.Type == token.NEG {
+            return newType("bool")
+        }
+    case *ast.InfixExpression:
+        return c.inferInfixType(expr, s)
+    case *ast.ConditionalExpression:
+        return union(c.inferType(expr.Then, s), c.inferType(expr.Else, s))
+    }
+    return nil
+}
+
+func (c *checker) inferInfixType(infix *ast.InfixExpression, s *scope) valueType {
+    switch infix.Op.Type {
+    case token.EQ, token.NEQ, token.LT, token.GT, token.LE, token.GE:
+        return newType("bool")
+    case token.ADD, token.SUB, token.MULT:
+        lhs, rhs := c.inferType(infix.Lhs, s), c.inferType(infix.Rhs, s)
+        if lhs.String() != rhs.String() {
+            return nil
+        }
+        switch lhs.String() {
+        case "int", "float":
+            return lhs
+        case "string":
+            if infix.Op.Type == token.ADD {
+                return lhs
+            }
+        }
+    }
+    return nil
+}
+
+// signature returns the head of a function like the evaluator shows it in errors
+func signature(name string, function *ast.FunctionLiteralExpression) string {
+    result := name + "(" + ast.ParametersString(function.Parameters) + ")"
+    if function.ReturnType != nil {
+        result += ": " + function.ReturnType.String()
+    }
+    return result
+}
+
+// checkDeclarationType compares the initializer of an annotated declaration with its type
+func (c *checker) checkDeclarationType(name string, annotation *ast.TypeAnnotation, initializer ast.Expression, s *scope) {
+    if actual := c.inferType(initializer, s); !actual.assignableTo(annotationType(annotation)) {
+        c.report(TYPE_MISMATCH, initializer.Position(), "cannot use %s as %s in the declaration of %s", actual, annotation, name)
+    }
+}
+
+// checkArgumentTypes compares the arguments of a call of a known function with its annotated parameters
+func (c *checker) checkArgumentTypes(call *ast.CallExpression, function *ast.FunctionLiteralExpression, name string, s *scope) {
+    parameters := function.Parameters
+    for i, arg := range call.Arguments {
+        if i >= len(parameters) && (len(parameters) == 0 || !parameters[len(parameters) - 1].Variadic) {
+            return
+        }
+        param := parameters[len(parameters) - 1]
+        if i < len(parameters) {
+            param = parameters[i]
+        }
+        if actual := c.inferType(arg, s); !actual.assignableTo(annotationType(param.Type)) {
+            c.report(TYPE_MISMATCH, arg.Position(), "argument %s in call of %s must be %s, got %s", param.Name, signature(name, function), param.Type, actual)
+        }
+    }
+}
+
+// checkReturnType compares a returned value with the return type of the enclosing function
+func (c *checker) checkReturnType(stmt *ast.ReturnStatement, s *scope) {
+    if len(c.functions) == 0 {
+        return
+    }
+    function := c.functions[len(c.functions) - 1]
+    if function.ReturnType == nil || function.IsGenerator {
+        return
+    }
+    if actual := c.inferType(stmt.Result, s); !actual.assignableTo(annotationType(function.ReturnType)) {
+        c.report(TYPE_MISMATCH, stmt.Position(), "cannot return %s from a function returning %s", actual, function.ReturnType)
+    }
+}
+
+// assignedNames collects the names which are assigned anywhere in node, the types of variables
+// are only inferred from their initializer if they are never assigned
+func assignedNames(node ast.Node, names map[string]bool) {
+    switch node := node.(type) {
+    case *ast.Program:
+        for _, stmt := range node.Statements {
+            assignedNames(stmt, names)
+        }
+    case *ast.BlockStatement:
+        if node == nil {
+            return
+        }
+        for _, stmt := range node.Statements {
+            assignedNames(stmt, names)
+        }
+    case *ast.ExportStatement:
+        assignedNames(node.Statement, names)
+    case *ast.ExpressionStatement:
+        assignedNames(node.Expr, names)
+    case *ast.LetStatement:
+        assignedNames(node.Initializer, names)
+    case *ast.ConstStatement:
+        assignedNames(node.Initializer, names)
+    case *ast.ReturnStatement:
+        assignedNames(node.Result, names)
+    case *ast.YieldStatement:
+        assignedNames(node.Value, names)
+    case *ast.FunctionDeclarationStatement:
+        assignedNames(node.Function, names)
+    case *ast.IfStatement:
+        assignedNames(node.Cond, names)
+        assignedNames(node.Then, names)
+        assignedNames(node.Else, names)
+    case *ast.TryCatchStatement:
+        assignedNames(node.Try, names)
+        assignedNames(node.Catch, names)
+    case *ast.WhileStatement:
+        assignedNames(node.Head, names)
+        assignedNames(node.Body, names)
+    case *ast.RangeLoopStatement:
+        assignedNames(node.RangeExpr, names)
+        assignedNames(node.Body, names)
+    case *ast.KVRangeLoopStatement:
+        assignedNames(node.RangeExpr, names)
+        assignedNames(node.Body, names)
+    case *ast.FunctionLiteralExpression:
+        for _, param := range node.Parameters {
+            assignedNames(param.Default, names)
+        }
+        assignedNames(node.Body, names)
+    case *ast.InfixExpression:
+        if identifier, ok := node.Lhs.(*ast.IdentifierExpression); ok && assignments[node.Op.Type] {
+            names[identifier.Name] = true
+        }
+        assignedNames(node.Lhs, names)
+        assignedNames(node.Rhs, names)
+    case *ast.UnaryExpression:
+        assignedNames(node.Rhs, names)
+    case *ast.ConditionalExpression:
+        assignedNames(node.Cond, names)
+        assignedNames(node.Then, names)
+        assignedNames(node.Else, names)
+    case *ast.CallExpression:
+        assignedNames(node.Function, names)
+        for _, arg := range node.Arguments {
+            assignedNames(arg, names)
+        }
+    case *ast.SpreadExpression:
+        assignedNames(node.Value, names)
+    case *ast.NamedArgumentExpression:
+        assignedNames(node.Value, names)
+    case *ast.ArrayLiteral:
+        for _, element := range node.Elements {
+            assignedNames(element, names)
+        }
+    case *ast.HashLiteral:
+        for key, value := range node.Pairs {
+            assignedNames(key, names)
+            assignedNames(value, names)
+        }
+    case *ast.IndexExpression:
+        assignedNames(node.Left, names)
+        assignedNames(node.Index, names)
+    case *ast.RangeExpression:
+        assignedNames(node.Start, names)
+        assignedNames(node.End, names)
+        assignedNames(node.Step, names)
+    case *ast.MatchExpression:
+        assignedNames(node.Subject, names)
+        for _, arm := range node.Arms {
+            assignedNames(arm.Guard, names)
+            assignedNames(arm.Body, names)
+        }
+    }
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "language/check"
+    "language/doc"
+    "language/format"
+    "language/frontend"
+    "language/project"
+    "language/run"
+)
+
+var runCommand = &command{
+    usage: "run [flags] (file.fml | - | -e code) [arguments]",
+    description: "run a program, - reads it from stdin, the arguments are passed to the program",
+    setup: func(flags *flag.FlagSet) func(args []string) int {
+        code := flags.String("e", "", "evaluate code instead of a file")
+        return func(args []string) int {
+            if flagSet(flags, "e") {
+                return run.RunCode(*code, run.INLINE_PATH, args)
+            }
+            if len(args) == 0 {
+                return usageError(flags)
+            }
+            if args[0] == "-" {
+                stdin, err := ioutil.ReadAll(os.Stdin)
+                if err != nil {
+                    return exitCode(err)
+                }
+                return run.RunCode(string(stdin), run.STDIN_PATH, args[1:])
+            }
+            return run.Run(args[0], args[1:])
+        }
+    },
+}
+
+var testCommand = &command{
+    usage: "test [paths]",
+    description: "run the functions starting with test in the files ending with _test.fml, directories are searched recursively",
+    setup: func(flags *flag.FlagSet) func(args []string) int {
+        verbose := flags.Bool("v", false, "print passed tests as well")
+        return func(args []string) int {
+            args, err := parseInterspersed(flags, args)
+            if err != nil {
+                return 2
+            }
+            if len(args) == 0 {
+                args = []string{"."}
+            }
+            files, err := run.FindTests(args)
+            if err != nil {
+                return exitCode(err)
+            }
+            passed, failed := 0, 0
+            for _, file := range files {
+                for _, result := range run.RunTests(file) {
+                    if result.Passed() {
+                        passed++
+                        if *verbose {
+                            fmt.Printf("ok    %s (%s)\n", result.Name, result.Path)
+                        }
+                        continue
+                    }
+                    failed++
+                    fmt.Printf("FAIL  %s (%s)\n%s\n", result.Name, result.Path, result.Error)
+                }
+            }
+            fmt.Printf("%d passed, %d failed\n", passed, failed)
+            if failed > 0 {
+                return 1
+            }
+            return 0
+        }
+    },
+}
+
+var fmtCommand = &command{
+    usage: "fmt [-w] [-l] [files]",
+    description: "indent programs by their brackets and remove trailing whitespace, without files stdin is formatted",
+    setup: func(flags *flag.FlagSet) func(args []string) int {
+        write := flags.Bool("w", false, "write the result to the files instead of stdout")
+        list := flags.Bool("l", false, "only list the files whose formatting differs")
+        return func(args []string) int {
+            args, err := parseInterspersed(flags, args)
+            if err != nil {
+                return 2
+            }
+            if len(args) == 0 || len(args) == 1 && args[0] == "-" {
+                stdin, err := ioutil.ReadAll(os.Stdin)
+                if err != nil {
+                    return exitCode(err)
+                }
+                fmt.Print(format.Format(string(stdin)))
+                return 0
+            }
+            for _, path := range args {
+                content, err := ioutil.ReadFile(path)
+                if err != nil {
+                    return exitCode(err)
+                }
+                formatted := format.Format(string(content))
+                if *list {
+                    if formatted != string(content) {
+                        fmt.Println(path)
+                    }
+                } else if *write {
+                    if formatted != string(content) {
+                        if err := ioutil.WriteFile(path, []byte(formatted), 0644); err != nil {
+                            return exitCode(err)
+                        }
+                    }
+                } else {
+                    fmt.Print(formatted)
+                }
+            }
+            return 0
+        }
+    },
+}
+
+var checkCommand = &command{
+    usage: "check [flags] files",
+    description: "report parser errors and suspicious code of programs without running them",
+    setup: func(flags *flag.FlagSet) func(args []string) int {
+        disable := flags.String("disable", "", "comma separated rules which are not reported, see -rules")
+        listRules := flags.Bool("rules", false, "list the rules of the checker")
+        return func(args []string) int {
+            args, err := parseInterspersed(flags, args)
+            if err != nil {
+                return 2
+            }
+            if *listRules {
+                rules := []string{}
+                for rule := range check.RULES {
+                    rules = append(rules, rule)
+                }
+                sort.Strings(rules)
+                for _, rule := range rules {
+                    fmt.Printf("%-20s %s\n", rule, check.RULES[rule])
+                }
+                return 0
+            }
+            if len(args) == 0 {
+                return usageError(flags)
+            }
+            disabled, err := check.ParseRules(*disable)
+            if err != nil {
+                return exitCode(err)
+            }
+            result := 0
+            for _, path := range args {
+                diagnostics, errs := checkFile(path, disabled)
+                for _, err := range errs {
+                    fmt.Fprintf(os.Stderr, "\t%s\n", err.Error())
+                }
+                for _, diagnostic := range diagnostics {
+                    fmt.Fprintf(os.Stderr, "\t%s\n", diagnostic.String())
+                }
+                if len(errs) > 0 || len(diagnostics) > 0 {
+                    result = 1
+                }
+            }
+            return result
+        }
+    },
+}
+
+var docCommand = &command{
+    usage: "doc [-html] [-o file] paths",
+    description: "print the documentation of modules as markdown, directories are searched recursively",
+    setup: func(flags *flag.FlagSet) func(args []string) int {
+        asHTML := flags.Bool("html", false, "render html instead of markdown")
+        output := flags.String("o", "", "write the documentation to a file instead of stdout")
+        return func(args []string) int {
+            args, err := parseInterspersed(flags, args)
+            if err != nil {
+                return 2
+            }
+            if len(args) == 0 {
+                return usageError(flags)
+            }
+            modules, err := documentModules(args)
+            if err != nil {
+                return exitCode(err)
+            }
+            rendered := doc.Markdown(modules)
+            if *asHTML {
+                rendered = doc.HTML(modules)
+            }
+            if *output == "" {
+                fmt.Print(rendered)
+                return 0
+            }
+            return exitCode(ioutil.WriteFile(*output, []byte(rendered), 0644))
+        }
+    },
+}
+
+// documentModules extracts the documentation of the modules at paths, test files in directories are skipped
+// and the modules found in a directory are titled by their path relative to it
+func documentModules(paths []string) ([]doc.Module, error) {
+    modules := []doc.Module{}
+    for _, path := range paths {
+        info, err := os.Stat(path)
+        if err != nil {
+            return nil, err
+        }
+        if !info.IsDir() {
+            module, err := documentModule(path, filepath.ToSlash(path))
+            if err != nil {
+                return nil, err
+            }
+            modules = append(modules, module)
+            continue
+        }
+        files := []string{}
+        err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+            if err != nil {
+                return err
+            }
+            if !info.IsDir() && strings.HasSuffix(file, ".fml") && !strings.HasSuffix(file, run.TEST_SUFFIX) {
+                files = append(files, file)
+            }
+            return nil
+        })
+        if err != nil {
+            return nil, err
+        }
+        sort.Strings(files)
+        for _, file := range files {
+            title, err := filepath.Rel(path, file)
+            if err != nil {
+                return nil, err
+            }
+            module, err := documentModule(file, filepath.ToSlash(title))
+            if err != nil {
+                return nil, err
+            }
+            modules = append(modules, module)
+        }
+    }
+    return modules, nil
+}
+
+func documentModule(path string, title string) (doc.Module, error) {
+    program, errs := frontend.Build(path)
+    if len(errs) > 0 {
+        return doc.Module{}, fmt.Errorf("%s: %s", path, errs[0])
+    }
+    return doc.Extract(program, title), nil
+}
+
+// checkFile parses and checks the program at path, the rules disabled by the manifest of its project are skipped as well
+func checkFile(path string, disabled map[string]bool) ([]check.Diagnostic, []error) {
+    source, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, []error{err}
+    }
+    program, errs := frontend.Build(path)
+    if len(errs) > 0 {
+        return nil, errs
+    }
+    config := check.Config{Disabled: map[string]bool{}}
+    for rule := range disabled {
+        config.Disabled[rule] = true
+    }
+    manifest, err := project.Find(filepath.Dir(path))
+    if err != nil {
+        return nil, []error{err}
+    }
+    if manifest != nil {
+        projectDisabled, err := check.ParseRules(strings.Join(manifest.DisabledChecks, ","))
+        if err != nil {
+            return nil, []error{fmt.Errorf("invalid manifest %s: %s", manifest.Root, err)}
+        }
+        for rule := range projectDisabled {
+            config.Disabled[rule] = true
+        }
+    }
+    return check.Check(program, string(source), config), nil
+}
@@ -0,0 +1,103 @@
+package doc
+
+import (
+    "strconv"
+    "strings"
+    "language/ast"
+)
+
+// the kinds of documented bindings
+const (
+    FUNCTION = "function"
+    CONSTANT = "const"
+    VARIABLE = "let"
+)
+
+// Entry is a binding visible to importers of a module with its doc comment
+type Entry struct {
+    Name string
+    Kind string
+    Signature string
+    Doc string
+    PosInfo ast.PositionalInfo
+}
+
+// Module is the documentation of a module file, Path is the path shown as its title
+type Module struct {
+    Path string
+    Doc string
+    Entries []Entry
+}
+
+// Extract collects the documentation of program, only exported bindings are listed if the module exports names
+func Extract(program *ast.Program, path string) Module {
+    module := Module{Path: path, Doc: program.Doc, Entries: []Entry{}}
+    _, hasExports := program.Exports()
+    for _, stmt := range program.Statements {
+        if export, ok := stmt.(*ast.ExportStatement); ok {
+            stmt = export.Statement
+        } else if hasExports {
+            continue
+        }
+        module.Entries = append(module.Entries, entries(stmt)...)
+    }
+    return module
+}
+
+func entries(stmt ast.Statement) []Entry {
+    switch stmt := stmt.(type) {
+    case *ast.FunctionDeclarationStatement:
+        return []Entry{functionEntry(stmt.Function.Name, stmt.Function, stmt.PosInfo)}
+    case *ast.LetStatement:
+        return bindingEntries(VARIABLE, stmt.Name, stmt.Pattern, stmt.Type, stmt.Initializer, stmt.Doc, stmt.PosInfo)
+    case *ast.ConstStatement:
+        return bindingEntries(CONSTANT, stmt.Name, stmt.Pattern, stmt.Type, stmt.Initializer, stmt.Doc, stmt.PosInfo)
+    }
+    return []Entry{}
+}
+
+func functionEntry(name string, function *ast.FunctionLiteralExpression, posInfo ast.PositionalInfo) Entry {
+    signature := "fun " + name + "(" + ast.ParametersString(function.Parameters) + ")"
+    if function.ReturnType != nil {
+        signature += ": " + function.ReturnType.String()
+    }
+    return Entry{Name: name, Kind: FUNCTION, Signature: signature, Doc: function.Doc, PosInfo: posInfo}
+}
+
+// bindingEntries documents a let or const statement, the names of a destructuring pattern share its doc comment
+func bindingEntries(kind string, name string, pattern ast.Pattern, annotation *ast.TypeAnnotation, initializer ast.Expression, doc string, posInfo ast.PositionalInfo) []Entry {
+    if pattern != nil {
+        result := []Entry{}
+        for _, name := range ast.PatternNames(pattern) {
+            result = append(result, Entry{Name: name, Kind: kind, Signature: kind + " " + name, Doc: doc, PosInfo: posInfo})
+        }
+        return result
+    }
+    if function, ok := initializer.(*ast.FunctionLiteralExpression); ok && kind == CONSTANT {
+        return []Entry{functionEntry(name, function, posInfo)}
+    }
+    signature := kind + " " + name
+    if annotation != nil {
+        signature += ": " + annotation.String()
+    }
+    if value, ok := literalString(initializer); ok && kind == CONSTANT {
+        signature += " = " + value
+    }
+    return []Entry{{Name: name, Kind: kind, Signature: signature, Doc: doc, PosInfo: posInfo}}
+}
+
+// literalString returns the source of a literal, the values of constants initialized by literals are shown in their signature
+func literalString(expr ast.Expression) (string, bool) {
+    switch expr := expr.(type) {
+    case *ast.FloatLiteralExpression:
+        value := strconv.FormatFloat(expr.Value, 'g', -1, 64)
+        if !strings.ContainsAny(value, ".e") {
+            value += ".0"
+        }
+        return value, true
+    case *ast.IntegerLiteralExpression, *ast.BigIntLiteralExpression, *ast.DecimalLiteralExpression,
+        *ast.StringLiteralExpression, *ast.BoolLiteralExpression, *ast.NullLiteralExpression:
+        return expr.String(), true
+    }
+    return "", false
+}
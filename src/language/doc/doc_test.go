@@ -0,0 +1,55 @@
+package doc
+
+import (
+    "strings"
+    "testing"
+    "language/parser"
+    "language/scanner"
+)
+
+func extract(t *testing.T, input string) Module {
+    t.Helper()
+    p := parser.New(scanner.New(input), "test.fml")
+    program, errs := p.Parse()
+    if len(errs) > 0 {
+        t.Fatalf("unexpected parser errors in %s: %v", input, errs)
+    }
+    return Extract(program, "test.fml")
+}
+
+func TestExtract(t *testing.T) {
+    tests := []struct {
+        input string
+        expected []string
+    }{
+        {"/// Adds.\nfun add(a, b: int): int { return a + b; }\nconst PI = 3.0;\nlet [x, y] = [1, 2];", []string{"fun add(a, b: int): int: Adds.", "const PI = 3.0: ", "let x: ", "let y: "}},
+        {"const hidden = 1;\n/// Shown.\nexport const f = fun(x) {};\nexport let count: int = 0;", []string{"fun f(x): Shown.", "let count: int: "}},
+        {"const NAME = \"fml\";\nconst items = [1];\nlet g = fun() {};", []string{"const NAME = \"fml\": ", "const items: ", "let g: "}},
+    }
+
+    for _, tt := range tests {
+        result := []string{}
+        for _, entry := range extract(t, tt.input).Entries {
+            result = append(result, entry.Signature + ": " + entry.Doc)
+        }
+        if strings.Join(result, ", ") != strings.Join(tt.expected, ", ") {
+            t.Errorf("expected %v for %s but got %v", tt.expected, tt.input, result)
+        }
+    }
+}
+
+func TestRender(t *testing.T) {
+    module := extract(t, "/// Math <helpers>.\n\n/// Squares x.\nexport fun square(x) { return x * x; }\n")
+
+    expectedMarkdown := "# test.fml\n\nMath <helpers>.\n\n## square\n\n```\nfun square(x)\n```\n\nSquares x.\n"
+    if markdown := Markdown([]Module{module}); markdown != expectedMarkdown {
+        t.Errorf("expected markdown %q but got %q", expectedMarkdown, markdown)
+    }
+
+    html := HTML([]Module{module})
+    for _, expected := range []string{"<h1 id=\"test.fml\">test.fml</h1>", "<p>Math &lt;helpers&gt;.</p>", "<h2 id=\"test.fml.square\">square</h2>", "<pre><code>fun square(x)</code></pre>", "<p>Squares x.</p>"} {
+        if !strings.Contains(html, expected) {
+            t.Errorf("expected %s in %s", expected, html)
+        }
+    }
+}
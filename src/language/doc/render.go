@@ -0,0 +1,64 @@
+package doc
+
+import (
+    "html"
+    "strings"
+)
+
+// Markdown renders the documentation of modules with a section for each module and each of its bindings
+func Markdown(modules []Module) string {
+    var out strings.Builder
+    for i, module := range modules {
+        if i > 0 {
+            out.WriteString("\n")
+        }
+        out.WriteString("# " + module.Path + "\n")
+        if module.Doc != "" {
+            out.WriteString("\n" + module.Doc + "\n")
+        }
+        for _, entry := range module.Entries {
+            out.WriteString("\n## " + entry.Name + "\n\n```\n" + entry.Signature + "\n```\n")
+            if entry.Doc != "" {
+                out.WriteString("\n" + entry.Doc + "\n")
+            }
+        }
+    }
+    return out.String()
+}
+
+// HTML renders the documentation of modules as a page with an index of the modules
+func HTML(modules []Module) string {
+    var out strings.Builder
+    out.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Documentation</title>\n</head>\n<body>\n")
+    out.WriteString("<ul>\n")
+    for _, module := range modules {
+        out.WriteString("<li><a href=\"#" + anchor(module.Path) + "\">" + html.EscapeString(module.Path) + "</a></li>\n")
+    }
+    out.WriteString("</ul>\n")
+    for _, module := range modules {
+        out.WriteString("<h1 id=\"" + anchor(module.Path) + "\">" + html.EscapeString(module.Path) + "</h1>\n")
+        out.WriteString(paragraphs(module.Doc))
+        for _, entry := range module.Entries {
+            out.WriteString("<h2 id=\"" + anchor(module.Path + "." + entry.Name) + "\">" + html.EscapeString(entry.Name) + "</h2>\n")
+            out.WriteString("<pre><code>" + html.EscapeString(entry.Signature) + "</code></pre>\n")
+            out.WriteString(paragraphs(entry.Doc))
+        }
+    }
+    out.WriteString("</body>\n</html>\n")
+    return out.String()
+}
+
+// paragraphs renders a doc comment, empty lines separate its paragraphs
+func paragraphs(doc string) string {
+    var out strings.Builder
+    for _, paragraph := range strings.Split(doc, "\n\n") {
+        if strings.TrimSpace(paragraph) != "" {
+            out.WriteString("<p>" + html.EscapeString(strings.TrimSpace(paragraph)) + "</p>\n")
+        }
+    }
+    return out.String()
+}
+
+func anchor(name string) string {
+    return html.EscapeString(strings.Replace(name, " ", "-", -1))
+}
@@ -0,0 +1,127 @@
+package eval
+
+import (
+    "fmt"
+    "language/ast"
+    "language/object"
+)
+
+type namedArgument struct {
+    name string
+    value object.Object
+    posInfo ast.PositionalInfo
+}
+
+// evalArguments evaluates the arguments of a call, spread arguments are expanded and named arguments are returned separately
+func evalArguments(exprs []ast.Expression, env *object.Environment, modules map[string]*object.Module) ([]object.Object, []namedArgument, object.Object) {
+    args := []object.Object{}
+    named := []namedArgument{}
+
+    for _, e := range exprs {
+        switch e := e.(type) {
+        case *ast.SpreadExpression:
+            value := Eval(e.Value, env, modules)
+            if isError(value) {
+                return nil, nil, value
+            }
+            elements, err := iterableElements(value, modules)
+            if err != nil {
+                return nil, nil, addToStacktrace(e.Position(), err.(*object.Error))
+            }
+            args = append(args, elements...)
+        case *ast.NamedArgumentExpression:
+            value := Eval(e.Value, env, modules)
+            if isError(value) {
+                return nil, nil, value
+            }
+            named = append(named, namedArgument{name: e.Name, value: value, posInfo: e.Position()})
+        default:
+            value := Eval(e, env, modules)
+            if isError(value) {
+                return nil, nil, value
+            }
+            args = append(args, value)
+        }
+    }
+
+    return args, named, nil
+}
+
+// bindNamedArguments puts the named arguments at the position of their parameter, positions which are not passed stay nil
+func bindNamedArguments(fn object.Object, args []object.Object, named []namedArgument) ([]object.Object, object.Object) {
+    function, ok := fn.(*object.Function)
+    if !ok {
+        return nil, makeError(named[0].posInfo, "named arguments can only be passed to user defined functions, not to %s", fn.Type())
+    }
+
+    for _, arg := range named {
+        idx := parameterIndex(function, arg.name)
+        if idx < 0 {
+            return nil, makeError(arg.posInfo, "%s has no parameter %s", function.Signature(), arg.name)
+        }
+        if idx < len(args) && args[idx] != nil {
+            return nil, makeError(arg.posInfo, "parameter %s of %s is passed more than once", arg.name, function.Signature())
+        }
+        for len(args) <= idx {
+            args = append(args, nil)
+        }
+        args[idx] = arg.value
+    }
+
+    return args, nil
+}
+
+func parameterIndex(function *object.Function, name string) int {
+    for i, p := range function.Parameters {
+        if p.Name == name && p.Pattern == nil && !p.Variadic {
+            return i
+        }
+    }
+    return -1
+}
+
+func checkArity(function *object.Function, args []object.Object) *object.Error {
+    required := 0
+    variadic := false
+    for _, p := range function.Parameters {
+        if p.Variadic {
+            variadic = true
+        } else if p.Default == nil {
+            required++
+        }
+    }
+    optional := len(function.Parameters) - required
+
+    if len(args) >= required && (variadic || len(args) <= len(function.Parameters)) {
+        return nil
+    }
+
+    var wanted string
+    if variadic {
+        wanted = fmt.Sprintf("at least %d", required)
+    } else if optional > 0 {
+        wanted = fmt.Sprintf("%d to %d", required, required + optional)
+    } else {
+        wanted = fmt.Sprintf("%d", required)
+    }
+    return makeErrorWithEmptyStacktrace("Wrong number of arguments in call of %s! Wanted %s, got %d", function.Signature(), wanted, len(args))
+}
+
+// parameterValue returns the argument passed for the parameter at idx or evaluates its default value in env
+func parameterValue(function *object.Function, idx int, args []object.Object, env *object.Environment, modules map[string]*object.Module) object.Object {
+    param := function.Parameters[idx]
+    if param.Variadic {
+        rest := []object.Object{}
+        if idx < len(args) {
+            rest = append(rest, args[idx:]...)
+        }
+        return &object.Array{Elements: rest}
+    }
+    if idx < len(args) && args[idx] != nil {
+        return args[idx]
+    }
+    if param.Default != nil {
+        return Eval(param.Default, env, modules)
+    }
+    return makeErrorWithEmptyStacktrace("missing argument for parameter %s in call of %s", param.String(), function.Signature())
+}
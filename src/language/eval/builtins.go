@@ -1,13 +1,15 @@
 package eval
 
 import (
+    "sort"
     "strings"
     "strconv"
     "fmt"
     "bufio"
+    "math"
+    "math/big"
     "os"
     "language/object"
-    "language/ast"
 )
 
 var builtins = map[string]*object.Builtin{
@@ -24,6 +26,12 @@ var builtins = map[string]*object.Builtin{
                 return &object.Integer{Value: int64(len(r))}
             case *object.Array:
                 return &object.Integer{Value: int64(len(value.Elements))}
+            case *object.Set:
+                return &object.Integer{Value: int64(len(value.Elements))}
+            case *object.Deque:
+                return &object.Integer{Value: int64(value.Len())}
+            case *object.Range:
+                return &object.Integer{Value: value.Len()}
             default:
                 return makeBuiltinError("cannot call len on %s", value.Type())
             }
@@ -42,6 +50,12 @@ var builtins = map[string]*object.Builtin{
                     return makeBuiltinError("cannot get first argument of an empty array")
                 }
                 return value.Elements[0]
+            case *object.Deque:
+                element, ok := value.Get(0)
+                if !ok {
+                    return makeBuiltinError("cannot get first argument of an empty deque")
+                }
+                return element
             default:
                 return makeBuiltinError("cannot call first on %s", value.Type())
             }
@@ -60,6 +74,12 @@ var builtins = map[string]*object.Builtin{
                     return makeBuiltinError("cannot get last argument of an empty array")
                 }
                 return value.Elements[len(value.Elements)-1]
+            case *object.Deque:
+                element, ok := value.Get(value.Len()-1)
+                if !ok {
+                    return makeBuiltinError("cannot get last argument of an empty deque")
+                }
+                return element
             default:
                 return makeBuiltinError("cannot call last on %s", value.Type())
             }
@@ -125,28 +145,6 @@ var builtins = map[string]*object.Builtin{
             return &object.Array{Elements: elements}
         },
     },
-    "print": &object.Builtin{
-        Function: func(args ...object.Object) object.Object {
-            var strs = []string{}
-            for _, s := range args {
-                strs = append(strs, s.String())
-            }
-            fmt.Printf("%s", strings.Join(strs, ", "))
-
-            return NULL
-        },
-    },
-    "println": &object.Builtin{
-        Function: func(args ...object.Object) object.Object {
-            var strs = []string{}
-            for _, s := range args {
-                strs = append(strs, s.String())
-            }
-            fmt.Printf("%s\n", strings.Join(strs, ", "))
-
-            return NULL
-        },
-    },
     "readline": &object.Builtin{
         Function: func(args ...object.Object) object.Object {
             if len(args) != 1 {
@@ -164,16 +162,6 @@ var builtins = map[string]*object.Builtin{
             return NULL
         },
     },
-    "str": &object.Builtin{
-        Function: func(args ...object.Object) object.Object {
-            if len(args) != 1 {
-                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
-            }
-
-            arg := args[0]
-            return &object.String{Value: arg.String()}
-        },
-    },
     "int": &object.Builtin{
         Function: func(args ...object.Object) object.Object {
             if len(args) != 1 {
@@ -194,6 +182,12 @@ var builtins = map[string]*object.Builtin{
                 return &object.Integer{Value: f}
             case *object.Integer:
                 return value
+            case *object.BigInt, *object.Decimal:
+                i := toBigInt(value)
+                if !i.IsInt64() {
+                    return makeBuiltinError("%s does not fit into an int", i.String())
+                }
+                return &object.Integer{Value: i.Int64()}
             default:
                 return makeBuiltinError("cannot convert values of type %s to int", arg.Type())
             }
@@ -220,6 +214,8 @@ var builtins = map[string]*object.Builtin{
             case *object.Integer:
                 f := float64(value.Value)
                 return &object.Float{Value: f}
+            case *object.BigInt, *object.Decimal:
+                return &object.Float{Value: toFloat(value)}
             default:
                 return makeBuiltinError("cannot convert values of type %s to int", arg.Type())
             }
@@ -262,6 +258,64 @@ var builtins = map[string]*object.Builtin{
             }
         },
     },
+    "bigint": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            arg := args[0]
+            switch value := arg.(type) {
+            case *object.String:
+                s := strings.TrimSpace(value.Value)
+                i, ok := new(big.Int).SetString(s, 10)
+                if !ok {
+                    return makeBuiltinError("Cannot convert string \"%s\" to bigint", value.Value)
+                }
+                return &object.BigInt{Value: i}
+            case *object.Float:
+                if math.IsInf(value.Value, 0) || math.IsNaN(value.Value) {
+                    return makeBuiltinError("cannot convert %s to bigint", value.String())
+                }
+                i, _ := big.NewFloat(value.Value).Int(nil)
+                return &object.BigInt{Value: i}
+            case *object.Integer, *object.BigInt, *object.Decimal:
+                return &object.BigInt{Value: new(big.Int).Set(toBigInt(value))}
+            default:
+                return makeBuiltinError("cannot convert values of type %s to bigint", arg.Type())
+            }
+        },
+    },
+    "decimal": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            arg := args[0]
+            switch value := arg.(type) {
+            case *object.String:
+                d, ok := object.ParseDecimal(strings.TrimSpace(value.Value))
+                if !ok {
+                    return makeBuiltinError("Cannot convert string \"%s\" to decimal", value.Value)
+                }
+                return d
+            case *object.Float:
+                // the shortest representation of the float, so decimal(0.1) is 0.1 and not the exact binary value
+                d, ok := object.ParseDecimal(strconv.FormatFloat(value.Value, 'f', -1, 64))
+                if !ok {
+                    return makeBuiltinError("cannot convert %s to decimal", value.String())
+                }
+                return d
+            case *object.Decimal:
+                return value
+            case *object.Integer, *object.BigInt:
+                return object.NewDecimal(toBigInt(value))
+            default:
+                return makeBuiltinError("cannot convert values of type %s to decimal", arg.Type())
+            }
+        },
+    },
     "copy": &object.Builtin{
         Function: func(args ...object.Object) object.Object {
             if len(args) != 1 {
@@ -282,6 +336,56 @@ var builtins = map[string]*object.Builtin{
             return deepCopy(arg)
         },
     },
+    "freeze": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            object.Freeze(args[0])
+            return args[0]
+        },
+    },
+    "exports": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            module, ok := args[0].(*object.Module)
+            if !ok {
+                return makeBuiltinError("cannot list exports of %s", args[0].Type())
+            }
+            names := []object.Object{}
+            for _, name := range module.ExportedNames() {
+                names = append(names, &object.String{Value: name})
+            }
+            return &object.Array{Elements: names}
+        },
+    },
+    "isFrozen": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            switch value := args[0].(type) {
+            case *object.Array:
+                return boolToBoolean(value.Frozen)
+            case *object.Hash:
+                return boolToBoolean(value.Frozen)
+            case *object.Set:
+                return boolToBoolean(value.Frozen)
+            case *object.Deque:
+                return boolToBoolean(value.Frozen)
+            case *object.Module:
+                return FALSE
+            default:
+                // scalars can not be changed anyway
+                return TRUE
+            }
+        },
+    },
     "isInt": &object.Builtin{
         Function: func(args ...object.Object) object.Object {
             return isOfTypeHelper(object.INTEGER_OBJECT, args...)
@@ -289,7 +393,17 @@ var builtins = map[string]*object.Builtin{
     },
     "isFloat": &object.Builtin{
         Function: func(args ...object.Object) object.Object {
-            return isOfTypeHelper(object.INTEGER_OBJECT, args...)
+            return isOfTypeHelper(object.FLOAT_OBJECT, args...)
+        },
+    },
+    "isBigInt": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            return isOfTypeHelper(object.BIGINT_OBJECT, args...)
+        },
+    },
+    "isDecimal": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            return isOfTypeHelper(object.DECIMAL_OBJECT, args...)
         },
     },
     "isBool": &object.Builtin{
@@ -364,6 +478,18 @@ func shallowCopy(arg object.Object) object.Object {
             result.Pairs[k] = v
         }
         return result
+    case *object.Set:
+        result := object.NewSet()
+        for _, v := range value.Values() {
+            result.Add(v)
+        }
+        return result
+    case *object.Deque:
+        result := object.NewDeque()
+        for _, v := range value.Elements() {
+            result.PushBack(v)
+        }
+        return result
     default:
         // no need to copy
         return arg
@@ -384,12 +510,31 @@ func deepCopy(arg object.Object) object.Object {
             result.Pairs[k] = object.HashPair{Key: v.Key, Value: deepCopy(v.Value)}
         }
         return result
+    case *object.Set:
+        // set elements are hashable values, so there is nothing to copy deeply
+        return shallowCopy(value)
+    case *object.Deque:
+        result := object.NewDeque()
+        for _, v := range value.Elements() {
+            result.PushBack(deepCopy(v))
+        }
+        return result
     default:
         // no need to deepcopy
         return arg
     }
 }
 
+// BuiltinNames returns the sorted names of the builtin functions
+func BuiltinNames() []string {
+    names := []string{}
+    for name := range builtins {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
 func makeBuiltinError(format string, a ...interface{}) *object.Error {
-    return &object.Error{Message: fmt.Sprintf(format, a...), StackTrace: []ast.PositionalInfo{}}
+    return &object.Error{Message: fmt.Sprintf(format, a...), StackTrace: []object.StackFrame{}}
 }
@@ -0,0 +1,344 @@
+package eval
+
+import (
+    "strings"
+    "language/object"
+)
+
+// collectionBuiltins are kept apart from the basic builtins because they use the iterator protocol,
+// which calls back into the evaluator
+var collectionBuiltins = map[string]*object.Builtin{
+    "set": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) > 1 {
+                return makeBuiltinError("wrong number of arguments, want 0 or 1, got %d", len(args))
+            }
+
+            result := object.NewSet()
+            if len(args) == 0 {
+                return result
+            }
+            elements, err := iterableElements(args[0], nil)
+            if err != nil {
+                return err
+            }
+            for _, e := range elements {
+                if !result.Add(e) {
+                    return makeBuiltinError("cannot add %s to set, it is not hashable", e.Type())
+                }
+            }
+            return result
+        },
+    },
+    "deque": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) > 1 {
+                return makeBuiltinError("wrong number of arguments, want 0 or 1, got %d", len(args))
+            }
+
+            result := object.NewDeque()
+            if len(args) == 0 {
+                return result
+            }
+            elements, err := iterableElements(args[0], nil)
+            if err != nil {
+                return err
+            }
+            for _, e := range elements {
+                result.PushBack(e)
+            }
+            return result
+        },
+    },
+    "add": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 2 {
+                return makeBuiltinError("wrong number of arguments, want 2, got %d", len(args))
+            }
+
+            set, ok := args[0].(*object.Set)
+            if !ok {
+                return makeBuiltinError("cannot call add on %s", args[0].Type())
+            }
+            if set.Frozen {
+                return makeBuiltinError("cannot add to a frozen set")
+            }
+            if !set.Add(args[1]) {
+                return makeBuiltinError("cannot add %s to set, it is not hashable", args[1].Type())
+            }
+            return set
+        },
+    },
+    "remove": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 2 {
+                return makeBuiltinError("wrong number of arguments, want 2, got %d", len(args))
+            }
+
+            switch value := args[0].(type) {
+            case *object.Set:
+                if value.Frozen {
+                    return makeBuiltinError("cannot remove from a frozen set")
+                }
+                return boolToBoolean(value.Remove(args[1]))
+            case *object.Hash:
+                if value.Frozen {
+                    return makeBuiltinError("cannot remove from a frozen hash")
+                }
+                key, ok := object.HashKeyOf(args[1])
+                if !ok {
+                    return makeBuiltinError("unusable as hashkey: %s", args[1].Type())
+                }
+                _, found := value.Pairs[key]
+                delete(value.Pairs, key)
+                return boolToBoolean(found)
+            default:
+                return makeBuiltinError("cannot call remove on %s", value.Type())
+            }
+        },
+    },
+    "contains": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 2 {
+                return makeBuiltinError("wrong number of arguments, want 2, got %d", len(args))
+            }
+
+            element := args[1]
+            switch value := args[0].(type) {
+            case *object.Set:
+                return boolToBoolean(value.Contains(element))
+            case *object.Hash:
+                key, ok := object.HashKeyOf(element)
+                if !ok {
+                    return FALSE
+                }
+                _, found := value.Pairs[key]
+                return boolToBoolean(found)
+            case *object.Array:
+                return boolToBoolean(containsObject(value.Elements, element))
+            case *object.Deque:
+                return boolToBoolean(containsObject(value.Elements(), element))
+            case *object.Range:
+                integer, ok := element.(*object.Integer)
+                return boolToBoolean(ok && value.Contains(integer.Value))
+            case *object.String:
+                substring, ok := element.(*object.String)
+                if !ok {
+                    return makeBuiltinError("can only search strings in strings, got %s", element.Type())
+                }
+                return boolToBoolean(strings.Contains(value.Value, substring.Value))
+            default:
+                return makeBuiltinError("cannot call contains on %s", value.Type())
+            }
+        },
+    },
+    "union": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            return setOperationHelper("union", (*object.Set).Union, args...)
+        },
+    },
+    "intersection": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            return setOperationHelper("intersection", (*object.Set).Intersection, args...)
+        },
+    },
+    "difference": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            return setOperationHelper("difference", (*object.Set).Difference, args...)
+        },
+    },
+    "pushBack": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 2 {
+                return makeBuiltinError("wrong number of arguments, want 2, got %d", len(args))
+            }
+
+            deque, ok := args[0].(*object.Deque)
+            if !ok {
+                return makeBuiltinError("cannot call pushBack on %s", args[0].Type())
+            }
+            if deque.Frozen {
+                return makeBuiltinError("cannot push to a frozen deque")
+            }
+            deque.PushBack(args[1])
+            return deque
+        },
+    },
+    "pushFront": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 2 {
+                return makeBuiltinError("wrong number of arguments, want 2, got %d", len(args))
+            }
+
+            deque, ok := args[0].(*object.Deque)
+            if !ok {
+                return makeBuiltinError("cannot call pushFront on %s", args[0].Type())
+            }
+            if deque.Frozen {
+                return makeBuiltinError("cannot push to a frozen deque")
+            }
+            deque.PushFront(args[1])
+            return deque
+        },
+    },
+    "popBack": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            deque, ok := args[0].(*object.Deque)
+            if !ok {
+                return makeBuiltinError("cannot call popBack on %s", args[0].Type())
+            }
+            if deque.Frozen {
+                return makeBuiltinError("cannot pop from a frozen deque")
+            }
+            element, ok := deque.PopBack()
+            if !ok {
+                return makeBuiltinError("cannot pop from an empty deque")
+            }
+            return element
+        },
+    },
+    "popFront": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            deque, ok := args[0].(*object.Deque)
+            if !ok {
+                return makeBuiltinError("cannot call popFront on %s", args[0].Type())
+            }
+            if deque.Frozen {
+                return makeBuiltinError("cannot pop from a frozen deque")
+            }
+            element, ok := deque.PopFront()
+            if !ok {
+                return makeBuiltinError("cannot pop from an empty deque")
+            }
+            return element
+        },
+    },
+    "range": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 2 && len(args) != 3 {
+                return makeBuiltinError("wrong number of arguments, want 2 or 3, got %d", len(args))
+            }
+
+            bounds := []int64{}
+            for _, arg := range args {
+                integer, ok := arg.(*object.Integer)
+                if !ok {
+                    return makeBuiltinError("arguments of range need to be integers, got %s", arg.Type())
+                }
+                bounds = append(bounds, integer.Value)
+            }
+            result := object.NewRange(bounds[0], bounds[1], false)
+            if len(bounds) == 3 {
+                if bounds[2] == 0 {
+                    return makeBuiltinError("range step must not be 0")
+                }
+                result.Step = bounds[2]
+            }
+            return result
+        },
+    },
+    "iter": &object.Builtin{
+        WithModules: func(modules map[string]*object.Module, args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            iterator, err := makeIterator(args[0], modules)
+            if err != nil {
+                return err
+            }
+            return iterator
+        },
+    },
+    "hasNext": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            iterator, ok := args[0].(object.Iterator)
+            if !ok {
+                return makeBuiltinError("cannot call hasNext on %s", args[0].Type())
+            }
+            return boolToBoolean(iterator.HasNext())
+        },
+    },
+    "next": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            iterator, ok := args[0].(object.Iterator)
+            if !ok {
+                return makeBuiltinError("cannot call next on %s", args[0].Type())
+            }
+            if !iterator.HasNext() {
+                return makeBuiltinError("iterator is exhausted")
+            }
+            return iterator.Next()
+        },
+    },
+    "toArray": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            elements, err := iterableElements(args[0], nil)
+            if err != nil {
+                return err
+            }
+            return &object.Array{Elements: elements}
+        },
+    },
+    "isSet": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            return isOfTypeHelper(object.SET_OBJECT, args...)
+        },
+    },
+    "isDeque": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            return isOfTypeHelper(object.DEQUE_OBJECT, args...)
+        },
+    },
+}
+
+func init() {
+    for name, builtin := range collectionBuiltins {
+        builtins[name] = builtin
+    }
+}
+
+func setOperationHelper(name string, operation func(*object.Set, *object.Set) *object.Set, args ...object.Object) object.Object {
+    if len(args) != 2 {
+        return makeBuiltinError("wrong number of arguments, want 2, got %d", len(args))
+    }
+
+    lhs, ok := args[0].(*object.Set)
+    if !ok {
+        return makeBuiltinError("cannot call %s on %s", name, args[0].Type())
+    }
+    rhs, ok := args[1].(*object.Set)
+    if !ok {
+        return makeBuiltinError("cannot call %s on %s", name, args[1].Type())
+    }
+    return operation(lhs, rhs)
+}
+
+func containsObject(elements []object.Object, element object.Object) bool {
+    for _, e := range elements {
+        if objectsEqual(e, element, nil) {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,142 @@
+package eval
+
+import (
+    "language/ast"
+    "language/object"
+    "language/token"
+)
+
+// comparedPair is a pair of compound values whose comparison is in progress, it makes the comparison of cyclic values terminate
+type comparedPair struct {
+    lhs object.Object
+    rhs object.Object
+}
+
+func evalEquality(op token.Token, lhs object.Object, rhs object.Object, modules map[string]*object.Module, posInfo ast.PositionalInfo) object.Object {
+    equal, err := valuesEqual(lhs, rhs, nil, modules)
+    if err != nil {
+        return addToStacktrace(posInfo, err)
+    }
+    if op.Type == token.NEQ {
+        return boolToBoolean(!equal)
+    }
+    return boolToBoolean(equal)
+}
+
+// objectsEqual compares by value, errors of eq functions count as not equal
+func objectsEqual(lhs object.Object, rhs object.Object, modules map[string]*object.Module) bool {
+    equal, err := valuesEqual(lhs, rhs, nil, modules)
+    return err == nil && equal
+}
+
+// objectsIdentical compares compound values by reference, scalars are immutable so they are compared by value
+func objectsIdentical(lhs object.Object, rhs object.Object) bool {
+    switch lhs.(type) {
+    case *object.Integer, *object.Float, *object.BigInt, *object.Decimal, *object.String, *object.Boolean, *object.Null:
+        return objectsEqual(lhs, rhs, nil)
+    }
+    return lhs == rhs
+}
+
+// valuesEqual compares arrays, hashes, sets and deques element wise, hashes with an __eq__ function are compared by it
+func valuesEqual(lhs object.Object, rhs object.Object, comparing map[comparedPair]bool, modules map[string]*object.Module) (bool, *object.Error) {
+    if lhs == rhs {
+        return true, nil
+    }
+    if eq, ok := operatorMethod(lhs, operatorMethods[token.EQ]); ok {
+        return callEq(eq, rhs, modules)
+    }
+    if eq, ok := operatorMethod(rhs, operatorMethods[token.EQ]); ok {
+        return callEq(eq, lhs, modules)
+    }
+    lhs, rhs, _ = promoteNumbers(token.Token{Type: token.EQ}, lhs, rhs)
+    if lhs.Type() != rhs.Type() {
+        return false, nil
+    }
+
+    switch lhs := lhs.(type) {
+    case *object.Integer:
+        return lhs.Value == rhs.(*object.Integer).Value, nil
+    case *object.Float:
+        return lhs.Value == rhs.(*object.Float).Value, nil
+    case *object.String:
+        return lhs.Value == rhs.(*object.String).Value, nil
+    case *object.Boolean:
+        return lhs.Value == rhs.(*object.Boolean).Value, nil
+    case *object.BigInt:
+        return lhs.Value.Cmp(rhs.(*object.BigInt).Value) == 0, nil
+    case *object.Decimal:
+        return lhs.Cmp(rhs.(*object.Decimal)) == 0, nil
+    case *object.Null:
+        return true, nil
+    case *object.Range:
+        other := rhs.(*object.Range)
+        return *lhs == *other, nil
+    case *object.Set:
+        other := rhs.(*object.Set)
+        if len(lhs.Elements) != len(other.Elements) {
+            return false, nil
+        }
+        for key := range lhs.Elements {
+            if _, ok := other.Elements[key]; !ok {
+                return false, nil
+            }
+        }
+        return true, nil
+    }
+
+    // a pair which is already being compared is equal as long as nothing else differs
+    pair := comparedPair{lhs: lhs, rhs: rhs}
+    if comparing == nil {
+        comparing = map[comparedPair]bool{}
+    }
+    if comparing[pair] {
+        return true, nil
+    }
+    comparing[pair] = true
+
+    switch lhs := lhs.(type) {
+    case *object.Array:
+        return elementsEqual(lhs.Elements, rhs.(*object.Array).Elements, comparing, modules)
+    case *object.Deque:
+        return elementsEqual(lhs.Elements(), rhs.(*object.Deque).Elements(), comparing, modules)
+    case *object.Hash:
+        other := rhs.(*object.Hash)
+        if len(lhs.Pairs) != len(other.Pairs) {
+            return false, nil
+        }
+        for key, pair := range lhs.Pairs {
+            otherPair, ok := other.Pairs[key]
+            if !ok {
+                return false, nil
+            }
+            equal, err := valuesEqual(pair.Value, otherPair.Value, comparing, modules)
+            if err != nil || !equal {
+                return false, err
+            }
+        }
+        return true, nil
+    }
+    return false, nil
+}
+
+func elementsEqual(lhs []object.Object, rhs []object.Object, comparing map[comparedPair]bool, modules map[string]*object.Module) (bool, *object.Error) {
+    if len(lhs) != len(rhs) {
+        return false, nil
+    }
+    for i := range lhs {
+        equal, err := valuesEqual(lhs[i], rhs[i], comparing, modules)
+        if err != nil || !equal {
+            return false, err
+        }
+    }
+    return true, nil
+}
+
+func callEq(eq object.Object, other object.Object, modules map[string]*object.Module) (bool, *object.Error) {
+    result := applyFunction(eq, []object.Object{other}, modules, ast.PositionalInfo{})
+    if err, ok := result.(*object.Error); ok {
+        return false, err
+    }
+    return isTruthy(result), nil
+}
@@ -2,12 +2,13 @@ package eval
 
 import (
     "fmt"
+    "io"
+    "math"
+    "math/big"
     "os"
-    "path/filepath"
     "language/ast"
     "language/object"
     "language/token"
-    "language/frontend"
 )
 
 var (
@@ -15,6 +16,12 @@ var (
     FALSE = &object.Boolean{Value: false}
     NULL = &object.Null{}
     MODULEPATH = ""
+    // READONLYMODULES forbids importers to assign to the variables of a module, like readOnlyModules in the manifest
+    READONLYMODULES = os.Getenv("FMLREADONLYMODULES") != ""
+    // FMLPATH is the directory of the core library
+    FMLPATH = os.Getenv("FMLPATH")
+    // TRACE receives a line for every call of a function if it is set
+    TRACE io.Writer
 )
 
 func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Module) object.Object {
@@ -23,53 +30,15 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
         return evalProgram(node, env, modules)
 
     case *ast.ImportStatement:
-        path := node.Path
-        if MODULEPATH == "" {
-            cwd, err := os.Getwd()
-            if err != nil {
-                return makeError(node.Position(), err.Error())
-            }
-            MODULEPATH = cwd
-        }
-        if !filepath.IsAbs(path) {
-            fmlpath := os.Getenv("FMLPATH")
-            if fmlpath != "" {
-                core_path := filepath.Join(fmlpath, path)
-                info, err := os.Stat(core_path)
-                if err != nil || info.IsDir() {
-                    path = filepath.Join(MODULEPATH, path)
-                } else {
-                    path = core_path
-                }
-            } else {
-                path = filepath.Join(MODULEPATH, path)
-            }
+        return evalImport(node, env, modules)
 
-        }
-        name := node.Name
-        // don't load module if already loaded
-        if foundModule, ok := modules[path]; ok {
-            if !env.AddConst(name, foundModule) {
-                return makeError(node.Position(), "Cannot define module with this name, it is already taken")
+    case *ast.ImportGroupStatement:
+        for _, imp := range node.Imports {
+            if result := evalImport(imp, env, modules); isError(result) {
+                return result
             }
-            return NULL
-        }
-        old_MODULEPATH := MODULEPATH
-        MODULEPATH = filepath.Dir(path)
-        defer func() {
-            MODULEPATH = old_MODULEPATH
-        }()
-        moduleCode, errs := frontend.Build(path)
-        if len(errs) > 0 {
-            return makeParserErrors(errs)
         }
-        module := &object.Module{Env: object.NewEnvironment(), Path: path}
-        if !env.AddConst(name, module) {
-            return makeError(node.Position(), "Cannot define module with this name, it is already taken")
-        }
-        moduleEnv := module.Env
-        modules[path] = module
-        return Eval(moduleCode, moduleEnv, modules)
+        return NULL
 
     case *ast.BlockStatement:
         return evalBlockStatement(node, env, modules)
@@ -77,16 +46,32 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
     case *ast.FunctionLiteralExpression:
         parameters := node.Parameters
         body := node.Body
-        return &object.Function{Parameters: parameters, Body: body, Env: env}
+        return &object.Function{Name: node.Name, Parameters: parameters, ReturnType: node.ReturnType, Body: body, Env: env, IsGenerator: node.IsGenerator, Doc: node.Doc}
+
+    case *ast.FunctionDeclarationStatement:
+        // the function is already bound by hoistFunctionDeclarations
+        return NULL
+
+    case *ast.ExportStatement:
+        return Eval(node.Statement, env, modules)
 
     case *ast.CallExpression:
         function := Eval(node.Function, env, modules)
         if isError(function) {
             return function
         }
-        args := evalExpressions(node.Arguments, env, modules)
-        if len(args) == 1 && isError(args[0]) {
-            return args[0]
+        args, named, err := evalArguments(node.Arguments, env, modules)
+        if err != nil {
+            return err
+        }
+        if len(named) > 0 {
+            args, err = bindNamedArguments(function, args, named)
+            if err != nil {
+                return err
+            }
+        }
+        if node.IsTailCall {
+            return &object.TailCall{Function: function, Arguments: args, PosInfo: node.Position()}
         }
 
         result := applyFunction(function, args, modules, node.Position())
@@ -153,42 +138,35 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
         if isError(theRange) {
             return theRange
         }
+        defer closeLoopGenerator(node.RangeExpr, theRange)
+        iterator, err := makeIterator(theRange, modules)
+        if err != nil {
+            return addToStacktrace(node.Position(), err)
+        }
         name := node.Name
         loopEnv := object.NewEnclosingEnvironment(env)
-        loopEnv.Add(name, NULL)
-        switch rangeHolder := theRange.(type) {
-        case *object.Array:
-            for _, e := range rangeHolder.Elements {
-                loopEnv.Set(name, e)
-                body := Eval(node.Body, loopEnv, modules)
-                if isErrorOrReturn(body) {
-                    return body
-                }
-                if isBreak(body) {
-                    return NULL
-                }
-                if isContinue(body) {
-                    continue
-                }
+        if node.Pattern == nil {
+            loopEnv.Add(name, NULL)
+        }
+        for iterator.HasNext() {
+            e := iterator.Next()
+            if isError(e) {
+                return e
             }
-            return NULL
-        case *object.Hash:
-            for _, p := range rangeHolder.Pairs {
-                key := p.Key
-                loopEnv.Set(name, key)
-                body := Eval(node.Body, loopEnv, modules)
-                if isErrorOrReturn(body) {
-                    return body
-                }
-                if isBreak(body) {
-                    return NULL
-                }
-                if isContinue(body) {
-                    continue
-                }
+            bodyEnv, err := bindLoopElement(name, node.Pattern, e, loopEnv, modules)
+            if err != nil {
+                return err
+            }
+            body := Eval(node.Body, bodyEnv, modules)
+            if isErrorOrReturn(body) {
+                return body
+            }
+            if isBreak(body) {
+                return NULL
+            }
+            if isContinue(body) {
+                continue
             }
-        default:
-            return makeError(node.Position(), "Can only range over array or hash, got %s", theRange.Type())
         }
         return NULL
 
@@ -197,35 +175,24 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
         if isError(theRange) {
             return theRange
         }
+        defer closeLoopGenerator(node.RangeExpr, theRange)
         indexName := node.IndexName
         elementName := node.ElementName
         loopEnv := object.NewEnclosingEnvironment(env)
         loopEnv.Add(indexName, NULL)
-        loopEnv.Add(elementName, NULL)
-        switch rangeHolder := theRange.(type) {
-        case *object.Array:
-            for i, e := range rangeHolder.Elements {
-                loopEnv.Set(indexName, &object.Integer{Value: int64(i)})
-                loopEnv.Set(elementName, e)
-                body := Eval(node.Body, loopEnv, modules)
-                if isErrorOrReturn(body) {
-                    return body
-                }
-                if isBreak(body) {
-                    return NULL
-                }
-                if isContinue(body) {
-                    continue
-                }
-            }
-            return NULL
-        case *object.Hash:
+        if node.ElementPattern == nil {
+            loopEnv.Add(elementName, NULL)
+        }
+        if rangeHolder, ok := theRange.(*object.Hash); ok && !isUserIterable(rangeHolder) {
             for _, p := range rangeHolder.Pairs {
                 key := p.Key
                 value := p.Value
                 loopEnv.Set(indexName, key)
-                loopEnv.Set(elementName, value)
-                body := Eval(node.Body, loopEnv, modules)
+                bodyEnv, err := bindLoopElement(elementName, node.ElementPattern, value, loopEnv, modules)
+                if err != nil {
+                    return err
+                }
+                body := Eval(node.Body, bodyEnv, modules)
                 if isErrorOrReturn(body) {
                     return body
                 }
@@ -236,8 +203,32 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
                     continue
                 }
             }
-        default:
-            return makeError(node.Position(), "Can only range over array or hash, got %s", theRange.Type())
+            return NULL
+        }
+        iterator, err := makeIterator(theRange, modules)
+        if err != nil {
+            return addToStacktrace(node.Position(), err)
+        }
+        for i := int64(0); iterator.HasNext(); i++ {
+            e := iterator.Next()
+            if isError(e) {
+                return e
+            }
+            loopEnv.Set(indexName, &object.Integer{Value: i})
+            bodyEnv, err := bindLoopElement(elementName, node.ElementPattern, e, loopEnv, modules)
+            if err != nil {
+                return err
+            }
+            body := Eval(node.Body, bodyEnv, modules)
+            if isErrorOrReturn(body) {
+                return body
+            }
+            if isBreak(body) {
+                return NULL
+            }
+            if isContinue(body) {
+                continue
+            }
         }
         return NULL
 
@@ -247,7 +238,12 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
             return value
         }
 
-        if !env.Add(node.Name, value) {
+        if node.Pattern != nil {
+            err := destructure(node.Pattern, value, env, false, modules)
+            if err != nil {
+                return err
+            }
+        } else if !env.Add(node.Name, value) {
             return makeError(node.Position(), "Cannot redefine variable %s", node.Name)
         }
 
@@ -257,7 +253,12 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
             return value
         }
 
-        if !env.AddConst(node.Name, value) {
+        if node.Pattern != nil {
+            err := destructure(node.Pattern, value, env, true, modules)
+            if err != nil {
+                return err
+            }
+        } else if !env.AddConst(node.Name, value) {
             return makeError(node.Position(), "Cannot redefine constant %s", node.Name)
         }
 
@@ -270,6 +271,16 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
     case *ast.FloatLiteralExpression:
         return &object.Float{Value: node.Value}
 
+    case *ast.BigIntLiteralExpression:
+        return &object.BigInt{Value: node.Value}
+
+    case *ast.DecimalLiteralExpression:
+        decimal, ok := object.ParseDecimal(node.Value)
+        if !ok {
+            return makeError(node.Position(), "invalid decimal literal %s", node.String())
+        }
+        return decimal
+
     case *ast.BoolLiteralExpression:
         return boolToBoolean(node.Value)
 
@@ -287,7 +298,11 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
         if len(elements) == 1 && isError(elements[0]) {
             return elements[0]
         }
-        return &object.Array{Elements: elements}
+        array := &object.Array{Elements: elements}
+        if node.Frozen {
+            object.Freeze(array)
+        }
+        return array
 
     case *ast.HashLiteral:
         return evalHashLiteral(node, env, modules)
@@ -302,7 +317,7 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
         if isError(idx) {
             return idx
         }
-        return evalIndex(lhs, idx, node.Position())
+        return evalIndex(lhs, idx, !node.Property, modules, node.Position())
 
     case *ast.BreakStatement:
         return &object.Break{}
@@ -317,6 +332,18 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
         }
         return &object.Return{Value: result}
 
+    case *ast.YieldStatement:
+        value := Eval(node.Value, env, modules)
+        if isError(value) {
+            return value
+        }
+        generator, ok := env.Generator()
+        if !ok {
+            return makeError(node.Position(), "yield outside of a generator")
+        }
+        generator.Yield(value)
+        return NULL
+
     case *ast.UnaryExpression:
         return evalUnary(node, env, modules)
 
@@ -325,22 +352,33 @@ func Eval(node ast.Node, env *object.Environment, modules map[string]*object.Mod
 
     case *ast.ConditionalExpression:
         return evalConditional(node, env, modules)
+
+    case *ast.RangeExpression:
+        return evalRange(node, env, modules)
+
+    case *ast.MatchExpression:
+        return evalMatch(node, env, modules)
     default:
         return makeError(node.Position(), "Unknown expression of type: %T", node)
     }
     return NULL
 }
 
-func evalIndex(lhs object.Object, index object.Object, posInfo ast.PositionalInfo) object.Object {
+// evalIndex evaluates lhs[index] and lhs.index, the __index__ function of a hash is only used for brackets
+func evalIndex(lhs object.Object, index object.Object, bracket bool, modules map[string]*object.Module, posInfo ast.PositionalInfo) object.Object {
     switch lhs := lhs.(type) {
     case *object.Array:
         return evalArray(lhs, index, posInfo)
     case *object.Hash:
-        return evalHash(lhs, index, posInfo)
+        return evalHash(lhs, index, bracket, modules, posInfo)
     case *object.Module:
         return evalModule(lhs, index, posInfo)
     case *object.String:
         return evalStringIndex(lhs, index, posInfo)
+    case *object.Deque:
+        return evalDequeIndex(lhs, index, posInfo)
+    case *object.Range:
+        return evalRangeIndex(lhs, index, posInfo)
     default:
         return makeError(posInfo, "Cannot index on %s", lhs.Type())
     }
@@ -355,7 +393,7 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment, modules map
             return key
         }
 
-        hashKey, ok := key.(object.Hashable)
+        hashed, ok := object.HashKeyOf(key)
         if !ok {
             return makeError(node.Position(), "key is not hashable: %s", key.Type())
         }
@@ -365,23 +403,38 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment, modules map
             return value
         }
 
-        hashed := hashKey.HashKey()
         pairs[hashed] = object.HashPair{Key: key, Value: value}
     }
-    return &object.Hash{Pairs: pairs}
+    hash := &object.Hash{Pairs: pairs}
+    if node.Frozen {
+        object.Freeze(hash)
+    }
+    return hash
 }
 
-func evalHash(lhs *object.Hash, index object.Object, posInfo ast.PositionalInfo) object.Object {
-    key, ok := index.(object.Hashable)
-    if !ok {
-        return makeError(posInfo, "unusable as hashkey: %s", index.Type())
+func evalHash(lhs *object.Hash, index object.Object, bracket bool, modules map[string]*object.Module, posInfo ast.PositionalInfo) object.Object {
+    key, ok := object.HashKeyOf(index)
+    if ok {
+        if pair, ok := lhs.Pairs[key]; ok {
+            return pair.Value
+        }
+    }
+
+    // an __index__ function handles the keys in brackets which are not part of the hash
+    if bracket {
+        if indexFunction, ok := hashFunction(lhs, INDEX_METHOD); ok {
+            result := applyFunction(indexFunction, []object.Object{index}, modules, posInfo)
+            if err, ok := result.(*object.Error); ok {
+                return addToStacktrace(posInfo, err)
+            }
+            return result
+        }
     }
 
-    pair, ok := lhs.Pairs[key.HashKey()]
     if !ok {
-        return NULL
+        return makeError(posInfo, "unusable as hashkey: %s", index.Type())
     }
-    return pair.Value
+    return NULL
 }
 
 func evalArray(lhs *object.Array, index object.Object, posInfo ast.PositionalInfo) object.Object {
@@ -395,25 +448,43 @@ func evalArray(lhs *object.Array, index object.Object, posInfo ast.PositionalInf
     return lhs.Elements[idx.Value]
 }
 
-func evalStringIndex(lhs *object.String, index object.Object, posInfo ast.PositionalInfo) object.Object {
+func evalDequeIndex(lhs *object.Deque, index object.Object, posInfo ast.PositionalInfo) object.Object {
     idx, ok := index.(*object.Integer)
     if !ok {
-        return makeError(posInfo, "Can only use integer as index on string, got %s", index.Type())
+        return makeError(posInfo, "Can only use integer as index on deque, got %s", index.Type())
     }
-    return builtins["substring"].Function(lhs, idx, &object.Integer{Value: idx.Value+1})
+    element, ok := lhs.Get(int(idx.Value))
+    if !ok {
+        return NULL
+    }
+    return element
 }
 
-func evalModule(lhs *object.Module, index object.Object, posInfo ast.PositionalInfo) object.Object {
-    name := index.String()
-    result, ok := lhs.Env.Get(name)
+func evalRangeIndex(lhs *object.Range, index object.Object, posInfo ast.PositionalInfo) object.Object {
+    idx, ok := index.(*object.Integer)
     if !ok {
-        return makeError(posInfo, "Cannot find %s in module", name)
+        return makeError(posInfo, "Can only use integer as index on range, got %s", index.Type())
     }
-    return result
+    element, ok := lhs.At(idx.Value)
+    if !ok {
+        return NULL
+    }
+    return element
+}
+
+func evalStringIndex(lhs *object.String, index object.Object, posInfo ast.PositionalInfo) object.Object {
+    idx, ok := index.(*object.Integer)
+    if !ok {
+        return makeError(posInfo, "Can only use integer as index on string, got %s", index.Type())
+    }
+    return builtins["substring"].Function(lhs, idx, &object.Integer{Value: idx.Value+1})
 }
 
 func evalIdentifier(name string, env *object.Environment, posInfo ast.PositionalInfo) object.Object {
     result, ok := env.Get(name)
+    if binding, isBinding := result.(*object.ImportBinding); isBinding {
+        return unresolvedImport(binding, posInfo)
+    }
     if ok {
         return result
     }
@@ -427,6 +498,10 @@ func evalIdentifier(name string, env *object.Environment, posInfo ast.Positional
 func evalProgram(program *ast.Program, env *object.Environment, modules map[string]*object.Module) object.Object {
     var result object.Object = NULL
 
+    if err := hoistFunctionDeclarations(program.Statements, env, modules); err != nil {
+        return err
+    }
+
     for _, stmt := range program.Statements {
         result = Eval(stmt, env, modules)
 
@@ -442,6 +517,10 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment, modu
     var result object.Object = NULL
     blockEnv := object.NewEnclosingEnvironment(env)
 
+    if err := hoistFunctionDeclarations(block.Statements, blockEnv, modules); err != nil {
+        return err
+    }
+
     for _, stmt := range block.Statements {
         result = Eval(stmt, blockEnv, modules)
         
@@ -453,25 +532,115 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment, modu
     return result
 }
 
+// hoistFunctionDeclarations binds all functions declared in statements before they are executed, so they can call each other
+func hoistFunctionDeclarations(statements []ast.Statement, env *object.Environment, modules map[string]*object.Module) object.Object {
+    for _, stmt := range statements {
+        if export, ok := stmt.(*ast.ExportStatement); ok {
+            stmt = export.Statement
+        }
+        declaration, ok := stmt.(*ast.FunctionDeclarationStatement)
+        if !ok {
+            continue
+        }
+        function := Eval(declaration.Function, env, modules)
+        if !env.AddConst(declaration.Function.Name, function) {
+            return makeError(declaration.Position(), "Cannot redefine constant %s", declaration.Function.Name)
+        }
+    }
+    return nil
+}
+
+// applyFunction calls fn and then the functions called in tail position, without growing the stack.
+// The annotated return types of the functions which made tail calls are checked against the result of the last call.
 func applyFunction(fn object.Object, args []object.Object, modules map[string]*object.Module, posInfo ast.PositionalInfo) object.Object {
+    var caller *object.Function
+    pending := []*object.Function{}
+    for {
+        result := callFunction(fn, args, modules, posInfo)
+        if err, ok := result.(*object.Error); ok && caller != nil {
+            // the frame of the tail call is part of the function which made it
+            addToStacktrace(posInfo, err)
+            nameStackFrames(err, caller)
+        }
+        tailCall, ok := result.(*object.TailCall)
+        if !ok {
+            for _, function := range pending {
+                if err := checkReturnType(function, result); err != nil {
+                    return err
+                }
+            }
+            return result
+        }
+        caller, _ = fn.(*object.Function)
+        if CHECKTYPES && caller != nil && caller.ReturnType != nil && !containsFunction(pending, caller) {
+            pending = append(pending, caller)
+        }
+        fn = tailCall.Function
+        args = tailCall.Arguments
+        posInfo = tailCall.PosInfo
+    }
+}
+
+func containsFunction(functions []*object.Function, function *object.Function) bool {
+    for _, f := range functions {
+        if f == function {
+            return true
+        }
+    }
+    return false
+}
+
+func callFunction(fn object.Object, args []object.Object, modules map[string]*object.Module, posInfo ast.PositionalInfo) object.Object {
     function, ok := fn.(*object.Function)
     if ok {
-        if len(args) != len(function.Parameters) {
-            return makeErrorWithEmptyStacktrace("Wrong number of arguiments in function call! Wanted %d, got %d", len(function.Parameters), len(args))
+        if err := checkArity(function, args); err != nil {
+            return err
+        }
+        extendedEnv, err := extendFunctionEnv(function, args, modules)
+        if err != nil {
+            return err
+        }
+        if TRACE != nil {
+            fmt.Fprintf(TRACE, "call %s at %s\n", functionName(function), posInfo)
+        }
+        if CHECKTYPES {
+            if err := checkArgumentTypes(function, extendedEnv); err != nil {
+                return err
+            }
+        }
+        if function.IsGenerator {
+            generator := makeGenerator(function, extendedEnv, modules, posInfo)
+            if CHECKTYPES {
+                if err := checkReturnType(function, generator); err != nil {
+                    return err
+                }
+            }
+            return generator
         }
-        extendedEnv := extendFunctionEnv(function, args)
         evaluated := Eval(function.Body, extendedEnv, modules)
-        return unwrapReturnValue(evaluated)
+        if err, ok := evaluated.(*object.Error); ok {
+            nameStackFrames(err, function)
+        }
+        result := unwrapReturnValue(evaluated)
+        if CHECKTYPES {
+            if err := checkReturnType(function, result); err != nil {
+                return err
+            }
+        }
+        return result
     }
 
     builtin, ok := fn.(*object.Builtin)
     if ok {
-        result := builtin.Function(args...)
-        if isError(result) {
-            resultingError := result.(*object.Error)
-            return addToStacktrace(posInfo, resultingError)
+        // the position of the call is added to the stack trace by the caller
+        if builtin.WithModules != nil {
+            return builtin.WithModules(modules, args...)
         }
-        return result
+        return builtin.Function(args...)
+    }
+
+    if call, ok := callableFunction(fn); ok {
+        return applyFunction(call, args, modules, posInfo)
     }
 
     return makeError(posInfo, "cannot call a non function %T", fn)
@@ -517,12 +686,27 @@ func evalUnary(expr *ast.UnaryExpression, env *object.Environment, modules map[s
         return computeNegExpr(value)
     }
 
+    if method, ok := unaryOperatorMethods[op.Type]; ok {
+        if function, ok := operatorMethod(value, method); ok {
+            result := applyFunction(function, []object.Object{}, modules, expr.Position())
+            if err, ok := result.(*object.Error); ok {
+                return addToStacktrace(expr.Position(), err)
+            }
+            return result
+        }
+    }
+
     switch typedValue := value.(type) {
     case *object.Integer:
         if op.Type == token.ADD {
             return typedValue
         } else if op.Type == token.SUB {
+            if typedValue.Value == math.MinInt64 {
+                return makeError(expr.Position(), "integer overflow: -(%d)", typedValue.Value)
+            }
             return &object.Integer{Value: -typedValue.Value}
+        } else if op.Type == token.BITNOT {
+            return &object.Integer{Value: ^typedValue.Value}
         } else {
             return makeError(expr.Position(), "unsupported unary expression")
         }
@@ -534,6 +718,24 @@ func evalUnary(expr *ast.UnaryExpression, env *object.Environment, modules map[s
         } else {
             return makeError(expr.Position(), "unsupported unary expression")
         }
+    case *object.BigInt:
+        if op.Type == token.ADD {
+            return typedValue
+        } else if op.Type == token.SUB {
+            return &object.BigInt{Value: new(big.Int).Neg(typedValue.Value)}
+        } else if op.Type == token.BITNOT {
+            return &object.BigInt{Value: new(big.Int).Not(typedValue.Value)}
+        } else {
+            return makeError(expr.Position(), "unsupported unary expression")
+        }
+    case *object.Decimal:
+        if op.Type == token.ADD {
+            return typedValue
+        } else if op.Type == token.SUB {
+            return typedValue.Neg()
+        } else {
+            return makeError(expr.Position(), "unsupported unary expression")
+        }
     default:
         return makeError(expr.Position(), "unsupported unary right hand side type")
     }
@@ -579,6 +781,12 @@ func evalIndexSet(expr *ast.IndexExpression, value object.Object, env *object.En
             return index
         }
         return evalHashIndexSet(lhs, index, value, expr.Position())
+    case *object.Deque:
+        index := Eval(expr.Index, env, modules)
+        if isError(index) {
+            return index
+        }
+        return evalDequeIndexSet(lhs, index, value, expr.Position())
     case *object.Module:
         index := Eval(expr.Index, env, modules)
         if isError(index) {
@@ -598,51 +806,60 @@ func evalArrayIndexSet(arr *object.Array, index object.Object, value object.Obje
     if idx.Value < 0 || idx.Value >= int64(len(arr.Elements)) {
         return makeError(posInfo, "index out of bounds: %d", idx.Value)
     }
+    if arr.Frozen {
+        return makeError(posInfo, "cannot modify a frozen array")
+    }
     arr.Elements[idx.Value] = value
     return value
 }
 
-func evalHashIndexSet(hash *object.Hash, index object.Object, value object.Object, posInfo ast.PositionalInfo) object.Object {
-    key, ok := index.(object.Hashable)
+func evalDequeIndexSet(deque *object.Deque, index object.Object, value object.Object, posInfo ast.PositionalInfo) object.Object {
+    idx, ok := index.(*object.Integer)
     if !ok {
-        return makeError(posInfo, "cannot use %s as hash key", index.Type())
+        return makeError(posInfo, "can only use integer as deque index but got %s", index.Type())
+    }
+    if deque.Frozen {
+        return makeError(posInfo, "cannot modify a frozen deque")
+    }
+    if !deque.Set(int(idx.Value), value) {
+        return makeError(posInfo, "index out of bounds: %d", idx.Value)
     }
-    hash.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: value}
     return value
 }
 
-func evalModuleIndexSet(module *object.Module, index object.Object, value object.Object, posInfo ast.PositionalInfo) object.Object {
-    name := index.String()
-    ok := module.Env.Set(name, value)
+func evalHashIndexSet(hash *object.Hash, index object.Object, value object.Object, posInfo ast.PositionalInfo) object.Object {
+    if hash.Frozen {
+        return makeError(posInfo, "cannot modify a frozen hash")
+    }
+    key, ok := object.HashKeyOf(index)
     if !ok {
-        return makeError(posInfo, "Cannot set value in module")
+        return makeError(posInfo, "cannot use %s as hash key", index.Type())
     }
+    hash.Pairs[key] = object.HashPair{Key: index, Value: value}
     return value
 }
 
+// compoundAssignments maps an assignment like += to the operator it applies before assigning
+var compoundAssignments = map[token.TokenType]token.TokenType{
+    token.ADDASSIGN: token.ADD,
+    token.SUBASSIGN: token.SUB,
+    token.MULTASSIGN: token.MULT,
+    token.DIVASSIGN: token.DIV,
+    token.MODASSIGN: token.MOD,
+    token.BITANDASSIGN: token.BITAND,
+    token.BITORASSIGN: token.BITOR,
+    token.BITXORASSIGN: token.BITXOR,
+    token.SHLASSIGN: token.SHL,
+    token.SHRASSIGN: token.SHR,
+}
+
 func evalInfix(expr *ast.InfixExpression, env *object.Environment, modules map[string]*object.Module) object.Object {
     if expr.Op.Type == token.ASSIGN {
         return evalAssign(expr.Lhs, expr.Rhs, env, modules)
     }
 
-    if expr.Op.Type == token.ADDASSIGN {
-        newRhs := &ast.InfixExpression{Lhs: expr.Lhs, Rhs: expr.Rhs, Op: token.FromType(token.ADD, expr.Op.Line, expr.Op.Column)}
-        return evalAssign(expr.Lhs, newRhs, env, modules)
-    }
-    if expr.Op.Type == token.SUBASSIGN {
-        newRhs := &ast.InfixExpression{Lhs: expr.Lhs, Rhs: expr.Rhs, Op: token.FromType(token.SUB, expr.Op.Line, expr.Op.Column)}
-        return evalAssign(expr.Lhs, newRhs, env, modules)
-    }
-    if expr.Op.Type == token.MULTASSIGN {
-        newRhs := &ast.InfixExpression{Lhs: expr.Lhs, Rhs: expr.Rhs, Op: token.FromType(token.MULT, expr.Op.Line, expr.Op.Column)}
-        return evalAssign(expr.Lhs, newRhs, env, modules)
-    }
-    if expr.Op.Type == token.DIVASSIGN {
-        newRhs := &ast.InfixExpression{Lhs: expr.Lhs, Rhs: expr.Rhs, Op: token.FromType(token.DIV, expr.Op.Line, expr.Op.Column)}
-        return evalAssign(expr.Lhs, newRhs, env, modules)
-    }
-    if expr.Op.Type == token.MODASSIGN {
-        newRhs := &ast.InfixExpression{Lhs: expr.Lhs, Rhs: expr.Rhs, Op: token.FromType(token.MOD, expr.Op.Line, expr.Op.Column)}
+    if op, ok := compoundAssignments[expr.Op.Type]; ok {
+        newRhs := &ast.InfixExpression{Lhs: expr.Lhs, Rhs: expr.Rhs, Op: token.FromType(op, expr.Op.Line, expr.Op.Column), PosInfo: expr.PosInfo}
         return evalAssign(expr.Lhs, newRhs, env, modules)
     }
 
@@ -676,6 +893,19 @@ func evalInfix(expr *ast.InfixExpression, env *object.Environment, modules map[s
         return rhs
     }
 
+    if expr.Op.Type == token.IS {
+        return boolToBoolean(objectsIdentical(lhs, rhs))
+    }
+
+    if result, ok := evalOverloadedInfix(expr.Op, lhs, rhs, modules, expr.Position()); ok {
+        return result
+    }
+
+    lhs, rhs, ok := promoteNumbers(expr.Op, lhs, rhs)
+    if !ok {
+        return makeError(expr.Position(), "cannot mix %s and %s in arithmetic, convert one of them with decimal() or float()", lhs.Type(), rhs.Type())
+    }
+
     if lhs.Type() == rhs.Type() {
         if lhs.Type() == object.INTEGER_OBJECT {
             lhsIo, _ := lhs.(*object.Integer)
@@ -687,29 +917,68 @@ func evalInfix(expr *ast.InfixExpression, env *object.Environment, modules map[s
             rhsFo := rhs.(*object.Float)
             return evalFloatInfix(expr.Op, lhsFo, rhsFo, expr.Position())
         }
+        if lhs.Type() == object.BIGINT_OBJECT {
+            return evalBigIntInfix(expr.Op, lhs.(*object.BigInt).Value, rhs.(*object.BigInt).Value, expr.Position())
+        }
+        if lhs.Type() == object.DECIMAL_OBJECT {
+            return evalDecimalInfix(expr.Op, lhs.(*object.Decimal), rhs.(*object.Decimal), expr.Position())
+        }
         if lhs.Type() == object.STRING_OBJECT {
             lhsSo, _ := lhs.(*object.String)
             rhsSo, _ := rhs.(*object.String)
             return evalStringInfix(expr.Op, lhsSo, rhsSo, expr.Position())
         }
         switch expr.Op.Type {
-        case token.EQ:
-            return boolToBoolean(lhs == rhs)
-        case token.NEQ:
-            return boolToBoolean(lhs != rhs)
+        case token.EQ, token.NEQ:
+            return evalEquality(expr.Op, lhs, rhs, modules, expr.Position())
         }
         return makeError(expr.Position(), "unsupported infix expression")
     }
     switch expr.Op.Type {
-    case token.EQ:
-        return boolToBoolean(lhs == rhs)
-    case token.NEQ:
-        return boolToBoolean(lhs != rhs)
+    case token.EQ, token.NEQ:
+        return evalEquality(expr.Op, lhs, rhs, modules, expr.Position())
     }
 
     return makeError(expr.Position(), "operands on infix expressions need to be of the same type")
 }
 
+func evalRange(expr *ast.RangeExpression, env *object.Environment, modules map[string]*object.Module) object.Object {
+    start := Eval(expr.Start, env, modules)
+    if isError(start) {
+        return start
+    }
+    end := Eval(expr.End, env, modules)
+    if isError(end) {
+        return end
+    }
+    startInt, ok := start.(*object.Integer)
+    if !ok {
+        return makeError(expr.Position(), "range bounds need to be integers, got %s", start.Type())
+    }
+    endInt, ok := end.(*object.Integer)
+    if !ok {
+        return makeError(expr.Position(), "range bounds need to be integers, got %s", end.Type())
+    }
+    result := object.NewRange(startInt.Value, endInt.Value, expr.Inclusive)
+    if expr.Step == nil {
+        return result
+    }
+
+    step := Eval(expr.Step, env, modules)
+    if isError(step) {
+        return step
+    }
+    stepInt, ok := step.(*object.Integer)
+    if !ok {
+        return makeError(expr.Position(), "range step needs to be an integer, got %s", step.Type())
+    }
+    if stepInt.Value == 0 {
+        return makeError(expr.Position(), "range step must not be 0")
+    }
+    result.Step = stepInt.Value
+    return result
+}
+
 func evalStringInfix(op token.Token, lhs *object.String, rhs *object.String, posInfo ast.PositionalInfo) object.Object {
     switch op.Type {
     case token.ADD:
@@ -724,16 +993,22 @@ func evalStringInfix(op token.Token, lhs *object.String, rhs *object.String, pos
 
 func evalIntegerInfix(op token.Token, lhs *object.Integer, rhs *object.Integer, posInfo ast.PositionalInfo) object.Object {
     switch op.Type {
-    case token.ADD:
-        return &object.Integer{Value: lhs.Value + rhs.Value}
-    case token.SUB:
-        return &object.Integer{Value: lhs.Value - rhs.Value}
-    case token.MULT:
-        return &object.Integer{Value: lhs.Value * rhs.Value}
-    case token.DIV:
-        return &object.Integer{Value: lhs.Value / rhs.Value}
-    case token.MOD:
-        return &object.Integer{Value: lhs.Value % rhs.Value}
+    case token.ADD, token.SUB, token.MULT, token.DIV, token.MOD, token.FLOORDIV:
+        value, err := checkedIntegerArithmetic(op, lhs.Value, rhs.Value)
+        if err != "" {
+            return makeError(posInfo, "%s", err)
+        }
+        return &object.Integer{Value: value}
+    case token.POW:
+        return integerPower(lhs.Value, rhs.Value, posInfo)
+    case token.BITAND:
+        return &object.Integer{Value: lhs.Value & rhs.Value}
+    case token.BITOR:
+        return &object.Integer{Value: lhs.Value | rhs.Value}
+    case token.BITXOR:
+        return &object.Integer{Value: lhs.Value ^ rhs.Value}
+    case token.SHL, token.SHR:
+        return integerShift(op, lhs.Value, rhs.Value, posInfo)
     case token.LT:
         return boolToBoolean(lhs.Value < rhs.Value)
     case token.GT:
@@ -746,18 +1021,6 @@ func evalIntegerInfix(op token.Token, lhs *object.Integer, rhs *object.Integer,
         return boolToBoolean(lhs.Value == rhs.Value)
     case token.NEQ:
         return boolToBoolean(lhs.Value != rhs.Value)
-    case token.RANGE:
-        elements := []object.Object{}
-        if lhs.Value < rhs.Value {
-            for i := lhs.Value; i < rhs.Value; i++ {
-                elements = append(elements, &object.Integer{Value: i})
-            }
-        } else if lhs.Value > rhs.Value {
-            for i := lhs.Value; i > rhs.Value; i-- {
-                elements = append(elements, &object.Integer{Value: i})
-            }
-        }
-        return &object.Array{Elements: elements}
     default:
         return makeError(posInfo, "unsupported infix operator on integers")
     }
@@ -773,6 +1036,12 @@ func evalFloatInfix(op token.Token, lhs *object.Float, rhs *object.Float, posInf
         return &object.Float{Value: lhs.Value * rhs.Value}
     case token.DIV:
         return &object.Float{Value: lhs.Value / rhs.Value}
+    case token.MOD:
+        return &object.Float{Value: math.Mod(lhs.Value, rhs.Value)}
+    case token.POW:
+        return &object.Float{Value: math.Pow(lhs.Value, rhs.Value)}
+    case token.FLOORDIV:
+        return floatFloorDivision(lhs.Value, rhs.Value, posInfo)
     case token.LT:
         return boolToBoolean(lhs.Value < rhs.Value)
     case token.GT:
@@ -841,14 +1110,50 @@ func boolToBoolean(value bool) *object.Boolean {
     }
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+func extendFunctionEnv(fn *object.Function, args []object.Object, modules map[string]*object.Module) (*object.Environment, object.Object) {
     env := object.NewEnclosingEnvironment(fn.Env)
     
     for i, p := range fn.Parameters {
-        env.AddConst(p, args[i])
+        value := parameterValue(fn, i, args, env, modules)
+        if isError(value) {
+            return nil, value
+        }
+        if p.Pattern != nil {
+            err := destructure(p.Pattern, value, env, true, modules)
+            if err != nil {
+                return nil, err
+            }
+            continue
+        }
+        env.AddConst(p.Name, value)
     }
 
-    return env
+    return env, nil
+}
+
+// closeLoopGenerator ends the body of a generator created by the call in the head of a loop when the loop is left,
+// nothing else can ask the generator for further values
+func closeLoopGenerator(rangeExpr ast.Expression, theRange object.Object) {
+    call, ok := rangeExpr.(*ast.CallExpression)
+    if !ok {
+        return
+    }
+    if generator, ok := theRange.(*object.Generator); ok && generator.CreatedBy == call.Position() {
+        generator.Close()
+    }
+}
+
+// makeGenerator defers the evaluation of the function body until the first value is requested
+func makeGenerator(function *object.Function, env *object.Environment, modules map[string]*object.Module, posInfo ast.PositionalInfo) *object.Generator {
+    generator := object.NewGenerator(func(c *object.Coroutine) object.Object {
+        result := unwrapReturnValue(Eval(function.Body, env, modules))
+        if tailCall, ok := result.(*object.TailCall); ok {
+            return applyFunction(tailCall.Function, tailCall.Arguments, modules, tailCall.PosInfo)
+        }
+        return result
+    }, posInfo)
+    env.SetGenerator(generator.Coroutine)
+    return generator
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -859,18 +1164,35 @@ func unwrapReturnValue(obj object.Object) object.Object {
 }
 
 func makeError(position ast.PositionalInfo, format string, a ...interface{}) *object.Error {
-    return &object.Error{Message: fmt.Sprintf(format, a...), StackTrace: []ast.PositionalInfo{position}}
+    return &object.Error{Message: fmt.Sprintf(format, a...), StackTrace: []object.StackFrame{{Position: position}}}
 }
 
 func makeErrorWithEmptyStacktrace(format string, a ...interface{}) *object.Error {
-    return &object.Error{Message: fmt.Sprintf(format, a...), StackTrace: []ast.PositionalInfo{}}
+    return &object.Error{Message: fmt.Sprintf(format, a...), StackTrace: []object.StackFrame{}}
 }
 
 func addToStacktrace(posInfo ast.PositionalInfo, err *object.Error) *object.Error {
-    err.StackTrace = append(err.StackTrace, posInfo)
+    err.StackTrace = append(err.StackTrace, object.StackFrame{Position: posInfo})
     return err
 }
 
+// nameStackFrames assigns the frames added while evaluating the body of function to it
+func nameStackFrames(err *object.Error, function *object.Function) {
+    name := functionName(function)
+    for i := range err.StackTrace {
+        if err.StackTrace[i].Function == "" {
+            err.StackTrace[i].Function = name
+        }
+    }
+}
+
+func functionName(function *object.Function) string {
+    if function.Name == "" {
+        return "<anonymous>"
+    }
+    return function.Name
+}
+
 func makeParserErrors(errs []error) *object.ParserErrors {
     return &object.ParserErrors{Errors: errs}
 }
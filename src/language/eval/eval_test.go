@@ -1,10 +1,19 @@
 package eval
 
 import (
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strconv"
     "testing"
+    "time"
     "language/scanner"
     "language/parser"
     "language/object"
+    "language/ast"
+    "language/frontend"
+    "language/project"
 )
 
 func TestPrograms(t *testing.T) {
@@ -140,6 +149,19 @@ func TestRangeOperator(t *testing.T) {
         {"(10..0)[9];", 1},
         {"(10..0)[10];", nil},
         {"(0..0)[0];", nil},
+        {"(0..=10)[10];", 10},
+        {"(10..=0)[10];", 0},
+        {"len(0..100 step 5);", 20},
+        {"(0..100 step 5)[3];", 15},
+        {"len(0..=100 step 5);", 21},
+        {"len(10..0 step -3);", 4},
+        {"len(10..0 step 3);", 0},
+        {"len(0..100000000000);", 100000000000},
+        {"contains(0..10 step 2, 4);", true},
+        {"contains(0..10 step 2, 5);", false},
+        {"let sum = 0; loop i in 0..=10 step 5 { sum += i; } sum;", 15},
+        {"0..1 step 0;", &object.Error{Message: "range step must not be 0"}},
+        {"0..\"a\";", &object.Error{Message: "range bounds need to be integers, got STRING"}},
     }
 
     for _, tt := range tests {
@@ -396,6 +418,328 @@ func TestBuiltin(t *testing.T) {
     }
 }
 
+func TestSet(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"len(set());", 0},
+        {"len(set([1, 2, 2, 3, 1]));", 3},
+        {"contains(set([1, 2, 3]), 2);", true},
+        {"contains(set([1, 2, 3]), 4);", false},
+        {"let s = set(); add(s, \"a\"); add(s, \"a\"); len(s);", 1},
+        {"let s = set([1, 2]); remove(s, 1); contains(s, 1);", false},
+        {"len(union(set([1, 2]), set([2, 3])));", 3},
+        {"len(intersection(set([1, 2]), set([2, 3])));", 1},
+        {"contains(intersection(set([1, 2]), set([2, 3])), 2);", true},
+        {"contains(difference(set([1, 2]), set([2, 3])), 1);", true},
+        {"contains(difference(set([1, 2]), set([2, 3])), 2);", false},
+        {"let sum = 0; loop e in set([1, 2, 3, 3]) { sum += e; } sum;", 6},
+        {"set([[1]]);", &object.Error{Message: "cannot add ARRAY to set, it is not hashable"}},
+        {"str(set([3, 1, 2, 1]));", "set(3, 1, 2)"},
+        {"let s = set([3, 1, 2]); remove(s, 3); add(s, 3); str(s);", "set(1, 2, 3)"},
+        {"str(union(set([\"b\", \"a\"]), set([\"c\", \"a\"])));", "set(b, a, c)"},
+        {"str(intersection(set([3, 2, 1]), set([1, 2])));", "set(2, 1)"},
+        {"str(difference(set([3, 2, 1]), set([2])));", "set(3, 1)"},
+        {"let order = \"\"; loop i, e in set([\"z\", \"y\", \"x\"]) { order += str(i) + e; } order;", "0z1y2x"},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestDeque(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"len(deque());", 0},
+        {"len(deque([1, 2, 3]));", 3},
+        {"let d = deque(); pushBack(d, 1); pushBack(d, 2); popFront(d);", 1},
+        {"let d = deque(); pushFront(d, 1); pushFront(d, 2); popFront(d);", 2},
+        {"let d = deque([1, 2, 3]); popBack(d);", 3},
+        {"let d = deque([1, 2, 3]); popFront(d); d[0];", 2},
+        {"let d = deque([1, 2, 3]); d[1] = 1337; d[1];", 1337},
+        {"deque([1])[5];", nil},
+        {"first(deque([1, 2, 3]));", 1},
+        {"last(deque([1, 2, 3]));", 3},
+        {"let d = deque(); loop i in 0..100 { pushBack(d, i); pushFront(d, i); } len(d);", 200},
+        {"let sum = 0; loop i, e in deque([5, 6, 7]) { sum += i * e; } sum;", 20},
+        {"popBack(deque());", &object.Error{Message: "cannot pop from an empty deque"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestIterators(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"let s = \"\"; loop c in \"abc\" { s = c + s; } s;", "cba"},
+        {"let s = 0; loop i, c in \"abc\" { s += i; } s;", 3},
+        {"let it = iter([1, 2]); next(it); next(it);", 2},
+        {"let it = iter([1]); next(it); hasNext(it);", false},
+        {"next(iter([]));", &object.Error{Message: "iterator is exhausted"}},
+        {"len(toArray(0..5));", 5},
+        {"toArray(\"hello\")[1];", "e"},
+        {"len(set(\"hello\"));", 4},
+        {`
+        const countdown = fun(start) {
+            let this = {};
+            let n = start;
+            this.__hasNext__ = fun() { return n > 0; };
+            this.__next__ = fun() { n -= 1; return n + 1; };
+            return this;
+        };
+        let sum = 0;
+        loop i in countdown(4) {
+            sum += i;
+        }
+        sum;
+        `, 10},
+        {`
+        const bag = {"__iter__": fun() { return iter([1, 2, 3]); }};
+        len(toArray(bag));
+        `, 3},
+        {`
+        const broken = {"__hasNext__": fun() { return true; }, "__next__": fun() { return unknown; }};
+        loop i in broken {}
+        `, &object.Error{Message: "unknown identifier: unknown"}},
+        {`
+        const node = {"value": 1, "next": fun() { return null; }};
+        let keys = "";
+        loop k, v in node {
+            keys += isString(k) ? "s" : "i";
+        }
+        keys;
+        `, "ss"},
+        {`
+        const node = {"value": 1, "hasNext": fun() { return true; }, "next": fun() { return 1; }, "iterator": fun() { return iter([]); }};
+        len(toArray(node));
+        `, 4},
+        {"loop i in 5 {}", &object.Error{Message: "Cannot iterate over INTEGER"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestGenerators(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {`
+        const fibonacci = fun() {
+            let a = 0;
+            let b = 1;
+            loop forever {
+                yield b;
+                const next = a + b;
+                a = b;
+                b = next;
+            }
+        };
+        let sum = 0;
+        loop f in fibonacci() {
+            if f > 100 {
+                break;
+            }
+            sum += f;
+        }
+        sum;
+        `, 232},
+        {`
+        const upTo = fun(n) {
+            loop i in 0..n {
+                yield i;
+            }
+        };
+        len(toArray(upTo(5)));
+        `, 5},
+        {`
+        const once = fun() {
+            yield 1;
+            return 2;
+            yield 3;
+        };
+        len(toArray(once()));
+        `, 1},
+        {`
+        const twice = fun() { yield "a"; yield "b"; };
+        let g = twice();
+        next(g);
+        next(g);
+        `, "b"},
+        {`
+        const lazy = fun() {
+            error("not reached");
+            yield 1;
+        };
+        lazy();
+        1337;
+        `, 1337},
+        {`
+        const failing = fun() {
+            yield 1;
+            unknown;
+        };
+        loop i in failing() {}
+        `, &object.Error{Message: "unknown identifier: unknown"}},
+        {`
+        const counter = fun(start) {
+            let i = start;
+            loop forever {
+                yield i;
+                i += 1;
+            }
+        };
+        let a = counter(0);
+        let b = counter(100);
+        next(a);
+        next(b);
+        next(a) + next(b);
+        `, 102},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestAbandonedGenerators(t *testing.T) {
+    before := runtime.NumGoroutine()
+    evaluated := evaluate(t, `
+    const naturals = fun() {
+        let i = 0;
+        loop forever {
+            yield i;
+            i += 1;
+        }
+    };
+    let sum = 0;
+    loop _ in 0..1000 {
+        loop n in naturals() {
+            if n > 2 {
+                break;
+            }
+        }
+        sum += next(naturals());
+        let dropped = naturals();
+        next(dropped);
+    }
+    let kept = naturals();
+    loop n in kept {
+        break;
+    }
+    next(kept);
+    `)
+    testLiteral(t, evaluated, 1)
+
+    // the generators closed by loops end at once, the dropped ones when they are collected
+    for i := 0; i < 100 && runtime.NumGoroutine() > before + 10; i++ {
+        runtime.GC()
+        time.Sleep(10 * time.Millisecond)
+    }
+    if after := runtime.NumGoroutine(); after > before + 10 {
+        t.Fatalf("expected abandoned generators to end, %d goroutines before and %d after", before, after)
+    }
+}
+
+func TestMatch(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {`match 2 { 1 => "one", 2 => "two", _ => "many" };`, "two"},
+        {`match 5 { 1 => "one", 2 => "two", _ => "many" };`, "many"},
+        {`match -3 { -3 => true, _ => false };`, true},
+        {`match 7 { 0..5 => "low", 5..=10 => "high" };`, "high"},
+        {`match "k" { "a"..="m" => 1, _ => 2 };`, 1},
+        {`match 4 { n if n % 2 == 0 => n / 2, n => n * 3 + 1 };`, 2},
+        {`match 5 { n if n % 2 == 0 => n / 2, n => n * 3 + 1 };`, 16},
+        {`match [1, 2, 3] { [] => 0, [x] => x, [x, y, ...rest] => x + y + len(rest) };`, 4},
+        {`match [1, 2] { [1, 3] => "a", [_, 2] => "b" };`, "b"},
+        {`match [] { [...rest] => len(rest) };`, 0},
+        {`match {"name": "fml", "version": 2} { {name, version: 1} => 1, {name, version: v} => name + str(v) };`, "fml2"},
+        {`match {"a": 1} { {b} => "b", {a: [x]} => "array", {a} => a };`, 1},
+        {`match 1.5 { i: int => "int", f: float => f };`, 1.5},
+        {`match "s" { _: int => "int", s: string => s + s };`, "ss"},
+        {`match null { _: null => "nothing", _ => "something" };`, "nothing"},
+        {`match len { _: function => true, _ => false };`, true},
+        {`match set([1]) { _: set => "set", _ => "other" };`, "set"},
+        {`
+        const describe = fun(value) {
+            return match value {
+                0 => { "zero"; },
+                n: int if n < 0 => {
+                    let abs = -n;
+                    "negative " + str(abs);
+                },
+                _: int => "positive",
+            };
+        };
+        describe(0) + ", " + describe(-2) + ", " + describe(3);
+        `, "zero, negative 2, positive"},
+        {`
+        let x = 1;
+        match 2 { x => x };
+        x;
+        `, 1},
+        {`match 3 { 1 => "one", 2 => "two" };`, &object.Error{Message: "no match arm for value 3 (INTEGER)"}},
+        {`match [1] { [] => 0 };`, &object.Error{Message: "no match arm for value [1] (ARRAY)"}},
+        {`match [1, 2] { [x, x] => x };`, &object.Error{Message: "Cannot bind x twice in one pattern"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestDestructuring(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"let [a, b, ...rest] = [1, 2, 3, 4]; a + b + len(rest);", 5},
+        {"let [a, ...rest] = [1]; len(rest);", 0},
+        {"const {name, age} = {\"name\": \"fml\", \"age\": 3}; name + str(age);", "fml3"},
+        {"let {\"point\": [x, y]} = {\"point\": [3, 4]}; x * y;", 12},
+        {"let [_, second] = [1, 2]; second;", 2},
+        {"let [a, b] = [1, 2]; a = 3; a + b;", 5},
+        {"const [a] = [1]; a = 2;", &object.Error{Message: "cannot assign a"}},
+        {"let a = 1; let [a] = [2];", &object.Error{Message: "Cannot redefine variable a"}},
+        {"let [a, {\"b\": a}] = [1, {\"b\": 2}];", &object.Error{Message: "Cannot bind a twice in one pattern"}},
+        {"let [a, b] = [1, 2, 3];", &object.Error{Message: "cannot destructure an array of length 3 into [a, b], expected 2 elements"}},
+        {"let [a, b, ...c] = [1];", &object.Error{Message: "cannot destructure an array of length 1 into [a, b, ...c], expected at least 2 elements"}},
+        {"let [a] = {};", &object.Error{Message: "cannot destructure HASH into [a], expected an array"}},
+        {"let {a} = [];", &object.Error{Message: "cannot destructure ARRAY into {\"a\": a}, expected a hash"}},
+        {"let {a} = {\"b\": 1};", &object.Error{Message: "cannot destructure hash into {\"a\": a}, key a is missing"}},
+        {"let [n: int] = [\"1\"];", &object.Error{Message: "cannot destructure 1, it does not match n: int"}},
+        {"const add = fun([a, b], {c}) { return a + b + c; }; add([1, 2], {\"c\": 3});", 6},
+        {"const first = fun([a, ...rest]) { return a; }; first([]);", &object.Error{Message: "cannot destructure an array of length 0 into [a, ...rest], expected at least 1 elements"}},
+        {"let sum = 0; loop [a, b] in [[1, 2], [3, 4]] { sum += a * b; } sum;", 14},
+        {"let sum = 0; loop i, {v} in [{\"v\": 1}, {\"v\": 2}] { sum += i * v; } sum;", 2},
+        {"let sum = 0; loop k, [a, b] in {\"x\": [1, 2]} { sum += a + b; } sum;", 3},
+        {"loop [a, b] in [[1, 2], [3]] { }", &object.Error{Message: "cannot destructure an array of length 1 into [a, b], expected 2 elements"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
 func TestEvalInt(t *testing.T) {
     tests := []struct {
         input string
@@ -528,6 +872,578 @@ func TestEvalFunctionCall(t *testing.T) {
     }
 }
 
+func TestFunctionArguments(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"const f = fun(a, b = 10) { return a + b; }; f(1);", 11},
+        {"const f = fun(a, b = 10) { return a + b; }; f(1, 2);", 3},
+        {"const f = fun(a, b = a * 2) { return a + b; }; f(3);", 9},
+        {"let offset = 1; const f = fun(a = offset) { return a; }; offset = 5; f();", 5},
+        {"const f = fun(a, ...rest) { return len(rest); }; f(1, 2, 3);", 2},
+        {"const f = fun(a, ...rest) { return len(rest); }; f(1);", 0},
+        {"const f = fun(a, b, c) { return a + b * c; }; f(...[1, 2, 3]);", 7},
+        {"const f = fun(a, b, c) { return a + b * c; }; f(1, ...2..4);", 7},
+        {"const f = fun(...all) { return len(all); }; f(...[1, 2], 3, ...[4]);", 4},
+        {"len(...[[3, 1, 2]]);", 3},
+        {"const f = fun(a, b = 2, c = 3) { return a * 100 + b * 10 + c; }; f(1, c: 5);", 125},
+        {"const f = fun(a, b = 2, c = 3) { return a * 100 + b * 10 + c; }; f(c: 1, b: 1, a: 1);", 111},
+        {"const f = fun(a, b) { return a; }; f(1);", &object.Error{Message: "Wrong number of arguments in call of fun(a, b)! Wanted 2, got 1"}},
+        {"const f = fun(a, b = 1) { return a; }; f(1, 2, 3);", &object.Error{Message: "Wrong number of arguments in call of fun(a, b = 1)! Wanted 1 to 2, got 3"}},
+        {"const f = fun(a, ...b) { return a; }; f();", &object.Error{Message: "Wrong number of arguments in call of fun(a, ...b)! Wanted at least 1, got 0"}},
+        {"const f = fun(a, b = 1) { return a; }; f(b: 2);", &object.Error{Message: "missing argument for parameter a in call of fun(a, b = 1)"}},
+        {"const f = fun(a) { return a; }; f(b: 2);", &object.Error{Message: "fun(a) has no parameter b"}},
+        {"const f = fun(a) { return a; }; f(1, a: 2);", &object.Error{Message: "parameter a of fun(a) is passed more than once"}},
+        {"len(a: 2);", &object.Error{Message: "named arguments can only be passed to user defined functions, not to BUILTIN"}},
+        {"const f = fun(a) { return a; }; f(...1);", &object.Error{Message: "Cannot iterate over INTEGER"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestTypeAnnotations(t *testing.T) {
+    tests := []struct {
+        input string
+        checkTypes bool
+        expected interface{}
+    }{
+        {"const f = fun(a: int): string { return a; }; f(\"no check\");", false, "no check"},
+        {"const f = fun(a: int, b: float = 1.5): float { return a * b; }; f(2);", true, 3.0},
+        {"let a: string? = null; a = 1; a;", true, 1},
+        {"const f = fun(a: int | string): any { return a; }; f(\"s\");", true, "s"},
+        {"const f = fun(a: int) { return a; }; f(1.5);", true, &object.Error{Message: "argument a in call of fun(a: int) must be int, got FLOAT"}},
+        {"const f = fun(a: int, b: string = 1) { return a; }; f(1);", true, &object.Error{Message: "argument b in call of fun(a: int, b: string = 1) must be string, got INTEGER"}},
+        {"const f = fun(...rest: int) { return rest; }; f(1, 2, \"3\");", true, &object.Error{Message: "argument rest[2] in call of fun(...rest: int) must be int, got STRING"}},
+        {"fun f(a): bool { return a; } f(1);", true, &object.Error{Message: "f(a): bool must return bool, got INTEGER"}},
+        {"fun f(): string? { } f();", true, nil},
+        {"fun count(n): int { if n == 0 { return \"done\"; } return count(n - 1); } count(3);", true, &object.Error{Message: "count(n): int must return int, got STRING"}},
+        {"let g = fun() { return \"s\"; }; fun f(): int { return g(); } f();", true, &object.Error{Message: "f(): int must return int, got STRING"}},
+        {"let g = fun() { let r = \"s\"; return r; }; fun f(): int { let r = g(); return r; } f();", true, &object.Error{Message: "f(): int must return int, got STRING"}},
+        {"fun h(): string { return 1; } fun f(): int { return h(); } f();", true, &object.Error{Message: "h(): string must return string, got INTEGER"}},
+        {"fun down(n): int { if n == 0 { return 0; } return down(n - 1); } down(100000);", true, 0},
+        {"const g = fun(): iterator { yield 1; }; next(g());", true, 1},
+        {"match 1 { x: any => x };", false, 1},
+    }
+
+    defer func() {
+        CHECKTYPES = false
+    }()
+    for _, tt := range tests {
+        CHECKTYPES = tt.checkTypes
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestHelp(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"/// Adds a and b.\n///\n/// Both are numbers.\nfun add(a, b: int): int { return a + b; }\nadd;", "fun add(a, b: int): int\n    Adds a and b.\n\n    Both are numbers."},
+        {"/// Doubles.\nconst f = fun(x) { return x * 2; };\nf;", "fun(x)\n    Doubles."},
+        {"fun(x) { return x; };", "fun(x)\n    no documentation"},
+        {"len;", "builtin len"},
+        {"[1];", "value of type ARRAY"},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        if help := Help(evaluated); help != tt.expected {
+            t.Errorf("expected %q for %s but got %q", tt.expected, tt.input, help)
+        }
+    }
+}
+
+func TestFunctionDeclarations(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"fun add(a, b) { return a + b; } add(1, 2);", 3},
+        {"const result = twice(21); fun twice(a) { return 2 * a; } result;", 42},
+        {`
+        fun isEven(n) { return n == 0 ? true : isOdd(n - 1); }
+        fun isOdd(n) { return n == 0 ? false : isEven(n - 1); }
+        isEven(10);
+        `, true},
+        {`
+        const outer = fun() {
+            return inner();
+            fun inner() { return "inner"; }
+        };
+        outer();
+        `, "inner"},
+        {"fun f() {} str(f);", "fun f()"},
+        {"str(fun(a, b = 1) { return a; });", "fun(a, b = 1)"},
+        {"const g = fun named() { return 1; }; str(g);", "fun named()"},
+        {"if true { fun local() { return 1; } } local();", &object.Error{Message: "unknown identifier: local"}},
+        {"fun f() {} fun f() {}", &object.Error{Message: "Cannot redefine constant f"}},
+        {"fun f() {} const f = 1;", &object.Error{Message: "Cannot redefine constant f"}},
+        {"fun f() {} f = 1;", &object.Error{Message: "cannot assign f"}},
+        {`fun fail() {
+    error("failed");
+}
+fun callFail() {
+    const f = fun() { fail(); };
+    f();
+}
+callFail();`, &object.Error{Message: "failed", StackTrace: []object.StackFrame{
+            {Function: "fail", Position: ast.PositionalInfo{Path: "test", Line: 2, Column: 10}},
+            {Function: "<anonymous>", Position: ast.PositionalInfo{Path: "test", Line: 5, Column: 27}},
+            {Function: "callFail", Position: ast.PositionalInfo{Path: "test", Line: 6, Column: 6}},
+            {Position: ast.PositionalInfo{Path: "test", Line: 8, Column: 9}},
+        }}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestTailCalls(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {`
+        fun count(n, acc) {
+            if n == 0 {
+                return acc;
+            }
+            return count(n - 1, acc + 1);
+        }
+        count(1000000, 0);
+        `, 1000000},
+        {`
+        fun isEven(n) { return n == 0 ? true : isOdd(n - 1); }
+        fun isOdd(n) { return n == 0 ? false : isEven(n - 1); }
+        isEven(100001);
+        `, false},
+        {`
+        fun sum(n, acc = 0) {
+            return match n {
+                0 => acc,
+                _ => sum(n - 1, acc: acc + n),
+            };
+        }
+        sum(100000);
+        `, 5000050000},
+        {`
+        fun down(n) {
+            return n == 0 ? len([]) : down(n - 1);
+        }
+        down(100000);
+        `, 0},
+        {`
+        fun failAt(n) {
+            if n == 0 {
+                error("reached zero");
+            }
+            return failAt(n - 1);
+        }
+        fun catching() {
+            try {
+                return failAt(10);
+            } catch e {
+                return e;
+            }
+        }
+        catching();
+        `, "reached zero"},
+        {`
+        fun gen() {
+            yield 1;
+            return gen2();
+        }
+        fun gen2() { error("in tail call"); }
+        toArray(gen());
+        `, &object.Error{Message: "in tail call"}},
+        {`
+        fun fail() {
+    error("failed");
+}
+fun tail() {
+    return fail();
+}
+tail();`, &object.Error{Message: "failed", StackTrace: []object.StackFrame{
+            {Function: "fail", Position: ast.PositionalInfo{Path: "test", Line: 3, Column: 10}},
+            {Function: "tail", Position: ast.PositionalInfo{Path: "test", Line: 6, Column: 16}},
+            {Position: ast.PositionalInfo{Path: "test", Line: 8, Column: 5}},
+        }}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestOperatorOverloading(t *testing.T) {
+    vector := `
+    fun Vector(x, y) {
+        const this = {"x": x, "y": y};
+        this.__add__ = fun(other) { return Vector(x + other.x, y + other.y); };
+        this.__sub__ = fun(other) { return Vector(x - other.x, y - other.y); };
+        this.__mul__ = fun(factor) { return Vector(x * factor, y * factor); };
+        this.__eq__ = fun(other) { return isHash(other) && x == other.x && y == other.y; };
+        this.__lt__ = fun(other) { return x * x + y * y < other.x * other.x + other.y * other.y; };
+        this.__neg__ = fun() { return Vector(-x, -y); };
+        this.__index__ = fun(i) { return i == 0 ? x : y; };
+        this.__call__ = fun(scale) { return Vector(x * scale, y * scale); };
+        this.__toString__ = fun() { return "Vector(" + str(x) + ", " + str(y) + ")"; };
+        return this;
+    }
+    `
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"str(Vector(1, 2) + Vector(3, 4));", "Vector(4, 6)"},
+        {"str(Vector(1, 2) - Vector(3, 4));", "Vector(-2, -2)"},
+        {"str(Vector(1, 2) * 3);", "Vector(3, 6)"},
+        {"let v = Vector(1, 1); v += Vector(1, 2); str(v);", "Vector(2, 3)"},
+        {"Vector(1, 2) == Vector(1, 2);", true},
+        {"Vector(1, 2) != Vector(1, 2);", false},
+        {"Vector(1, 2) == 1;", false},
+        {"1 == Vector(1, 2);", false},
+        {"Vector(1, 2) < Vector(3, 4);", true},
+        {"str(-Vector(1, 2));", "Vector(-1, -2)"},
+        {"Vector(1, 2)[0] + Vector(1, 2)[1];", 3},
+        {"Vector(5, 6).x;", 5},
+        {"str(Vector(1, 2)(10));", "Vector(10, 20)"},
+        {"str([Vector(1, 2), 3]);", "[Vector(1, 2), 3]"},
+        {"Vector(1, 2) / 2;", &object.Error{Message: "operands on infix expressions need to be of the same type"}},
+        {"Vector(1, 2) > Vector(3, 4);", &object.Error{Message: "unsupported infix expression"}},
+        {"const broken = {\"__toString__\": fun() { return 1; }}; str(broken);", &object.Error{Message: "__toString__ has to return a string, got INTEGER"}},
+        {"const failing = {\"__add__\": fun(other) { error(\"cannot add\"); }}; failing + 1;", &object.Error{Message: "cannot add"}},
+        {"const plain = {\"a\": 1}; plain.b;", nil},
+        {"Vector(5, 6).z;", nil},
+        {"const ordinary = {\"add\": fun(other) { return 0; }, \"toString\": fun() { return \"x\"; }}; str(ordinary + 1);", &object.Error{Message: "operands on infix expressions need to be of the same type"}},
+        {"const ordinary = {\"index\": fun(key) { return 0; }}; ordinary[1];", nil},
+        {"str({\"v\": Vector(1, 2)});", "{v: Vector(1, 2)}"},
+        {"str(deque([Vector(1, 2)]));", "deque(Vector(1, 2))"},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, vector + tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestStructuralEquality(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"[1, [2, 3]] == [1, [2, 3]];", true},
+        {"[1, [2, 3]] == [1, [2, 4]];", false},
+        {"[1, 2] != [1, 2, 3];", true},
+        {"{\"a\": [1], \"b\": null} == {\"b\": null, \"a\": [1]};", true},
+        {"{\"a\": 1} == {\"a\": 1, \"b\": 2};", false},
+        {"set([1, 2]) == set([2, 1]);", true},
+        {"deque([1, 2]) == deque([1, 2]);", true},
+        {"(0..3) == (0..3);", true},
+        {"(0..3) == (0..=3);", false},
+        {"[1] == {\"a\": 1};", false},
+        {"const b = [1, null]; const c = [1, null]; b[1] = b; c[1] = c; b == c;", true},
+        {"const a = [1]; a is a;", true},
+        {"[1] is [1];", false},
+        {"const a = {}; const b = a; b is a;", true},
+        {"\"a\" is \"a\" && 1 is 1 && null is null;", true},
+        {"[{\"__eq__\": fun(other) { return true; }}] == [1];", true},
+        {"[{\"__eq__\": fun(other) { error(\"cannot compare\"); }}] == [1];", &object.Error{Message: "cannot compare"}},
+        {"contains([[1, 2]], [1, 2]);", true},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestFrozenValues(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"const h = {freeze([1, 2]): \"pair\"}; h[freeze([1, 2])];", "pair"},
+        {"const h = {}; h[freeze({\"x\": 1})] = 2; h[freeze({\"x\": 1})];", 2},
+        {"const s = set([freeze([1, 2]), freeze([1, 2])]); len(s);", 1},
+        {"contains(set([freeze([1, [2]])]), freeze([1, [2]]));", true},
+        {"freeze([1, 2]) == [1, 2];", true},
+        {"const a = freeze([1, [2]]); a[1][0] = 3;", &object.Error{Message: "cannot modify a frozen array"}},
+        {"const h = freeze({\"a\": 1}); h.a = 2;", &object.Error{Message: "cannot modify a frozen hash"}},
+        {"const h = freeze({\"a\": 1}); remove(h, \"a\");", &object.Error{Message: "cannot remove from a frozen hash"}},
+        {"const a = copy(freeze([1])); a[0] = 2; a[0];", 2},
+        {"const s = freeze(set([1])); add(s, 2);", &object.Error{Message: "cannot add to a frozen set"}},
+        {"const s = freeze(set([1])); remove(s, 1);", &object.Error{Message: "cannot remove from a frozen set"}},
+        {"const s = freeze(set([1])); len(union(s, set([2])));", 2},
+        {"const d = freeze(deque([1])); pushBack(d, 2);", &object.Error{Message: "cannot push to a frozen deque"}},
+        {"const d = freeze(deque([1])); pushFront(d, 2);", &object.Error{Message: "cannot push to a frozen deque"}},
+        {"const d = freeze(deque([1])); popBack(d);", &object.Error{Message: "cannot pop from a frozen deque"}},
+        {"const d = freeze(deque([1])); popFront(d);", &object.Error{Message: "cannot pop from a frozen deque"}},
+        {"const d = freeze(deque([1])); d[0] = 2;", &object.Error{Message: "cannot modify a frozen deque"}},
+        {"const a = freeze([deque([[1]]), set([1])]); a[0][0][0] = 2;", &object.Error{Message: "cannot modify a frozen array"}},
+        {"const a = freeze([deque([1]), set([1])]); add(a[1], 2);", &object.Error{Message: "cannot add to a frozen set"}},
+        {"const d = copy(freeze(deque([1]))); pushBack(d, 2); len(d);", 2},
+        {"{1: \"a\"}[1.0];", "a"},
+        {"{2.5: \"a\"}[2.5d];", "a"},
+        {"{0.1d: \"a\"}[0.1];", "a"},
+        {"contains(set([1]), 1.0) && contains(set([1.0]), 1n);", true},
+        {"len(set([1, 1.0, 1n, 1d, 1.5, 1.5d]));", 2},
+        {"{freeze([1]): \"a\"}[freeze([1.0])];", "a"},
+        {"{[1]: 2};", &object.Error{Message: "key is not hashable: ARRAY"}},
+        {"const h = {}; h[[1]] = 2;", &object.Error{Message: "cannot use ARRAY as hash key"}},
+        {"set([[1]]);", &object.Error{Message: "cannot add ARRAY to set, it is not hashable"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestFrozenLiterals(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"const point = #[1, 2]; point[0] = 3;", &object.Error{Message: "cannot modify a frozen array"}},
+        {"const this = #{\"name\": \"fml\"}; this.name = \"other\";", &object.Error{Message: "cannot modify a frozen hash"}},
+        {"const nested = #{\"list\": [1, {\"a\": 1}]}; nested.list[1].a = 2;", &object.Error{Message: "cannot modify a frozen hash"}},
+        {"isFrozen(#[[1]][0]);", true},
+        {"isFrozen([1]) || isFrozen({});", false},
+        {"isFrozen(freeze({}));", true},
+        {"isFrozen(1) && isFrozen(\"a\");", true},
+        {"isFrozen(set([1]));", false},
+        {"isFrozen(freeze(set([1]))) && isFrozen(freeze(deque([1])));", true},
+        {"isFrozen(freeze({\"d\": deque([])}).d);", true},
+        {"const h = {#[0, 0]: \"origin\"}; h[#[0, 0]];", "origin"},
+        {"#[1, 2] == [1, 2];", true},
+        {"const f = fun(p) { p[0] = 1; }; f(#[0]);", &object.Error{Message: "cannot modify a frozen array"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestReadOnlyModules(t *testing.T) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    defer os.RemoveAll(dir)
+    path := filepath.Join(dir, "module.fml")
+    if err := ioutil.WriteFile(path, []byte("let value = 1;"), 0644); err != nil {
+        t.Fatalf("could not write module: %s", err)
+    }
+
+    input := "import \"" + path + "\" as m; m.value = 2; m.value;"
+
+    readOnly := &object.Error{Message: "Cannot set value, module " + path + " is read-only"}
+    testLiteral(t, evaluate(t, input), 2)
+
+    func() {
+        defer setReadOnlyModules(true)()
+        testLiteral(t, evaluate(t, input), readOnly)
+    }()
+    testLiteral(t, evaluate(t, input), 2)
+
+    func() {
+        defer setProject(&project.Manifest{Name: "readonly", Root: dir, ReadOnlyModules: true})()
+        testLiteral(t, evaluate(t, input), readOnly)
+    }()
+}
+
+// setReadOnlyModules sets READONLYMODULES, the returned function restores it
+func setReadOnlyModules(readOnly bool) func() {
+    old := READONLYMODULES
+    READONLYMODULES = readOnly
+    return func() {
+        READONLYMODULES = old
+    }
+}
+
+// setProject sets PROJECT, the returned function restores it
+func setProject(manifest *project.Manifest) func() {
+    old := PROJECT
+    PROJECT = manifest
+    return func() {
+        PROJECT = old
+    }
+}
+
+func TestExportedModules(t *testing.T) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    defer os.RemoveAll(dir)
+    path := filepath.Join(dir, "module.fml")
+    code := "let secret = 1; export let value = helper(); export fun helper() { return secret; }"
+    if err := ioutil.WriteFile(path, []byte(code), 0644); err != nil {
+        t.Fatalf("could not write module: %s", err)
+    }
+    importStmt := "import \"" + path + "\" as m; "
+
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"m.value;", 1},
+        {"m.helper();", 1},
+        {"m.value = 2; m.value;", 2},
+        {"str(exports(m));", "[helper, value]"},
+        {"m.secret;", &object.Error{Message: "secret is not exported by module " + path}},
+        {"m.secret = 2;", &object.Error{Message: "secret is not exported by module " + path}},
+        {"exports(1);", &object.Error{Message: "cannot list exports of INTEGER"}},
+    }
+
+    for _, tt := range tests {
+        testLiteral(t, evaluate(t, importStmt+tt.input), tt.expected)
+    }
+}
+
+func TestSelectiveImports(t *testing.T) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    defer os.RemoveAll(dir)
+    files := map[string]string{
+        "lib.fml": "let secret = 2; export let value = 1; export fun double(x) { return secret * x; }",
+        "all.fml": "let first = 1; fun second() { return 2; }",
+    }
+    for name, code := range files {
+        if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(code), 0644); err != nil {
+            t.Fatalf("could not write module: %s", err)
+        }
+    }
+    path := func(name string) string {
+        return "\"" + filepath.Join(dir, name) + "\""
+    }
+
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"import " + path("lib.fml") + "; lib.value;", 1},
+        {"import (" + path("lib.fml") + ", " + path("lib.fml") + " as l); l.value + lib.value;", 2},
+        {"from " + path("lib.fml") + " import value; value;", 1},
+        {"from " + path("lib.fml") + " import (value, double as twice); twice(value);", 2},
+        {"from " + path("lib.fml") + " import missing;", &object.Error{Message: "Cannot find missing in module " + filepath.Join(dir, "lib.fml")}},
+        {"from " + path("lib.fml") + " import secret;", &object.Error{Message: "secret is not exported by module " + filepath.Join(dir, "lib.fml")}},
+        {"let value = 0; from " + path("lib.fml") + " import value;", &object.Error{Message: "Cannot import value, the name is already taken"}},
+        {"from " + path("lib.fml") + " import *; double(value);", 2},
+        {"from " + path("lib.fml") + " import *; secret;", &object.Error{Message: "unknown identifier: secret"}},
+        {"from " + path("all.fml") + " import *; first + second();", 3},
+        {"let double = 0; from " + path("lib.fml") + " import *;", &object.Error{Message: "Cannot import double, the name is already taken"}},
+    }
+
+    for _, tt := range tests {
+        testLiteral(t, evaluate(t, tt.input), tt.expected)
+    }
+}
+
+func TestCircularImports(t *testing.T) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    defer os.RemoveAll(dir)
+    files := map[string]string{
+        // names imported circularly are resolved when they are used, using them before their module defined them is an error
+        "ping.fml": "from \"pong.fml\" import pong; fun ping(n) { return n == 0 ? \"ping\" : pong(n - 1); }",
+        "pong.fml": "from \"ping.fml\" import ping; fun pong(n) { return n == 0 ? \"pong\" : ping(n - 1); }",
+        "first.fml": "import \"second.fml\";\nconst name = \"first\";",
+        "second.fml": "import \"third.fml\";",
+        "third.fml": "from \"first.fml\" import name;\nlet copy = name;",
+        "early.fml": "import \"late.fml\";\nconst name = \"early\";",
+        "late.fml": "import \"early.fml\";\nlet name = early.name;",
+        "lazy.fml": "import \"lazier.fml\";\nconst name = \"lazy\";\nlet value = lazier.get();",
+        "lazier.fml": "import \"lazy.fml\";\nfun get() { return lazy.name; }",
+        "even.fml": "from \"odd.fml\" import isOdd;\nconst isEven = fun(n) { return n == 0 ? true : isOdd(n - 1); };",
+        "odd.fml": "from \"even.fml\" import isEven;\nconst isOdd = fun(n) { return n == 0 ? false : isEven(n - 1); };",
+        "wild.fml": "import \"card.fml\";\nconst joker = 1;",
+        "card.fml": "from \"wild.fml\" import *;",
+        "missing.fml": "from \"absent.fml\" import never;\nconst get = fun() { return never; };",
+        "absent.fml": "import \"missing.fml\";",
+    }
+    for name, code := range files {
+        if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(code), 0644); err != nil {
+            t.Fatalf("could not write module: %s", err)
+        }
+    }
+    path := func(name string) string {
+        return filepath.Join(dir, name)
+    }
+    position := func(name string, line int) string {
+        return path(name) + ": [line: " + strconv.Itoa(line) + ", column: 1]"
+    }
+
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"from \"" + path("ping.fml") + "\" import ping; ping(3);", "pong"},
+        {"import \"" + path("lazy.fml") + "\"; lazy.value;", "lazy"},
+        {"from \"" + path("even.fml") + "\" import isEven; isEven(5);", false},
+        {"import \"" + path("first.fml") + "\";", &object.Error{Message: "Cannot use name of module " + path("first.fml") + ", it is not defined yet because of the circular import " + position("first.fml", 1) + " -> " + position("second.fml", 1) + " -> " + position("third.fml", 1) + " -> " + path("first.fml")}},
+        {"import \"" + path("wild.fml") + "\";", &object.Error{Message: "Cannot import * from module " + path("wild.fml") + " without exports, it is still loading because of the circular import " + position("wild.fml", 1) + " -> " + position("card.fml", 1) + " -> " + path("wild.fml")}},
+        {"from \"" + path("missing.fml") + "\" import get; get();", &object.Error{Message: "Cannot find never in module " + path("absent.fml")}},
+        {"import \"" + path("early.fml") + "\";", &object.Error{Message: "Cannot find name in module " + path("early.fml") + ", it is not defined yet because of the circular import " + position("early.fml", 1) + " -> " + position("late.fml", 1) + " -> " + path("early.fml")}},
+    }
+
+    for _, tt := range tests {
+        testLiteral(t, evaluate(t, tt.input), tt.expected)
+    }
+}
+
+func TestOsModule(t *testing.T) {
+    ARGS = []string{"first", "second"}
+    exitCode := -1
+    EXIT = func(code int) {
+        exitCode = code
+    }
+    defer func() {
+        ARGS = []string{}
+        EXIT = os.Exit
+        os.Unsetenv("FML_TEST_VARIABLE")
+    }()
+    cwd, _ := os.Getwd()
+
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"import \"os\"; len(os.args);", 2},
+        {"import \"os\"; os.args[1];", "second"},
+        {"import \"os\"; os.env(\"FML_TEST_VARIABLE\");", nil},
+        {"import \"os\"; os.setEnv(\"FML_TEST_VARIABLE\", \"set\"); os.env(\"FML_TEST_VARIABLE\");", "set"},
+        {"import \"os\"; os.setEnv(\"FML_TEST_VARIABLE\", null); os.env(\"FML_TEST_VARIABLE\");", nil},
+        {"import \"os\"; os.cwd();", cwd},
+        {"from \"os\" import exit; exit(3);", nil},
+        {"import \"os\"; os.args = [];", &object.Error{Message: "Cannot set args, module os is read-only"}},
+        {"import \"os\"; push(os.args, \"third\"); len(os.args);", 2},
+        {"import \"os\"; os.exit(\"1\");", &object.Error{Message: "expected argument to be of type int"}},
+    }
+
+    for _, tt := range tests {
+        testLiteral(t, evaluate(t, tt.input), tt.expected)
+    }
+    if exitCode != 3 {
+        t.Fatalf("expected exit code 3 but got %d", exitCode)
+    }
+}
+
 func TestEvalFunctionDefinition(t *testing.T) {
     tests := []struct {
         input string
@@ -552,8 +1468,8 @@ func TestEvalFunctionDefinition(t *testing.T) {
         }
 
         for i, p := range funcObj.Parameters {
-            if p != tt.parameters[i] {
-                t.Fatalf("Expected parameters number %d to be %s, but got %s", i, tt.parameters[i], p)
+            if p.String() != tt.parameters[i] {
+                t.Fatalf("Expected parameters number %d to be %s, but got %s", i, tt.parameters[i], p.String())
             }
         }
 
@@ -659,6 +1575,152 @@ func TestEvalInfix(t *testing.T) {
     }
 }
 
+func TestNumericArithmetic(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"1 + 2.5;", 3.5},
+        {"2.5 - 1;", 1.5},
+        {"2 * 1.5;", 3.0},
+        {"7 / 2;", 3},
+        {"7 / 2.0;", 3.5},
+        {"1 < 1.5;", true},
+        {"2.0 >= 2;", true},
+        {"1 == 1.0;", true},
+        {"[1, 2] == [1.0, 2.0];", true},
+        {"7.5 % 2;", 1.5},
+        {"-7.5 % 2;", -1.5},
+        {"2 ** 10;", 1024},
+        {"2 ** 3 ** 2;", 512},
+        {"-2 ** 2;", -4},
+        {"(-2) ** 3;", -8},
+        {"2 ** -1;", 0.5},
+        {"4.0 ** 0.5;", 2.0},
+        {"7 ~/ 2;", 3},
+        {"-7 ~/ 2;", -4},
+        {"7 ~/ -2;", -4},
+        {"-7 ~/ -2;", 3},
+        {"7.5 ~/ 2;", 3},
+        {"-0.5 ~/ 1;", -1},
+        {"1 / 0;", &object.Error{Message: "division by zero"}},
+        {"1 % 0;", &object.Error{Message: "division by zero"}},
+        {"1 ~/ 0;", &object.Error{Message: "division by zero"}},
+        {"1.5 ~/ 0;", &object.Error{Message: "division by zero"}},
+        {"let message = null; try { 1 / 0; } catch e { message = e; } message;", "division by zero"},
+        {"9223372036854775807 + 1;", &object.Error{Message: "integer overflow: 9223372036854775807 + 1"}},
+        {"-9223372036854775807 - 2;", &object.Error{Message: "integer overflow: -9223372036854775807 - 2"}},
+        {"4611686018427387904 * 2;", &object.Error{Message: "integer overflow: 4611686018427387904 * 2"}},
+        {"2 ** 63;", &object.Error{Message: "integer overflow: 2 ** 63"}},
+        {"2 ** 62;", 4611686018427387904},
+        {"(-2) ** 63;", -9223372036854775807 - 1},
+        {"const min = -9223372036854775807 - 1; min / -1;", &object.Error{Message: "integer overflow: -9223372036854775808 / -1"}},
+        {"const min = -9223372036854775807 - 1; -min;", &object.Error{Message: "integer overflow: -(-9223372036854775808)"}},
+        {"10.0 ** 30 ~/ 1;", &object.Error{Message: "integer overflow: 1e+30 ~/ 1"}},
+        {"\"a\" + 1;", &object.Error{Message: "operands on infix expressions need to be of the same type"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestBigIntAndDecimal(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"str(9223372036854775807n + 1);", "9223372036854775808"},
+        {"str(bigint(2) ** 100);", "1267650600228229401496703205376"},
+        {"let f = 1n; loop i in 1..=25 { f *= i; } str(f);", "15511210043330985984000000"},
+        {"str(-7n ~/ 2);", "-4"},
+        {"str(-7n % 2);", "-1"},
+        {"str(-(2n ** 64));", "-18446744073709551616"},
+        {"1n == 1;", true},
+        {"2n ** 64 > 9223372036854775807;", true},
+        {"isBigInt(1n + 1);", true},
+        {"1n + 0.5;", 1.5},
+        {"int(12n);", 12},
+        {"int(2n ** 64);", &object.Error{Message: "18446744073709551616 does not fit into an int"}},
+        {"float(2n ** 64) == 18446744073709551616.0;", true},
+        {"str(bigint(\"123456789012345678901234567890\") * 10);", "1234567890123456789012345678900"},
+        {"str(bigint(-3.9));", "-3"},
+        {"bigint(\"12a\");", &object.Error{Message: "Cannot convert string \"12a\" to bigint"}},
+        {"1n / 0;", &object.Error{Message: "division by zero"}},
+        {"const h = {1n: \"one\", 2n ** 70: \"big\"}; h[1] + h[2n ** 70];", "onebig"},
+        {"str(0.10d + 0.20d);", "0.30"},
+        {"0.1d + 0.2d == 0.3d;", true},
+        {"str(19.99d * 3);", "59.97"},
+        {"str(10d / 3);", "3.33333333333333333333"},
+        {"str(1.50d + 1);", "2.50"},
+        {"str(-1.5d);", "-1.5"},
+        {"str(7.5d % 2);", "1.5"},
+        {"-7.5d ~/ 2;", -4},
+        {"str(1.5d ** 2);", "2.25"},
+        {"str(2d ** -2);", "0.25"},
+        {"1.5d ** 0.5d;", &object.Error{Message: "the exponent of a decimal has to be integral, got 0.5"}},
+        {"1.5d + 0.5;", &object.Error{Message: "cannot mix DECIMAL and FLOAT in arithmetic, convert one of them with decimal() or float()"}},
+        {"1.5d < 2.0;", true},
+        {"1.5d == 1.50d;", true},
+        {"2.00d == 2;", true},
+        {"str(decimal(\"12.340\"));", "12.340"},
+        {"str(decimal(0.1));", "0.1"},
+        {"str(decimal(3n));", "3"},
+        {"float(1.25d);", 1.25},
+        {"int(-2.75d);", -2},
+        {"decimal(\"1.2.3\");", &object.Error{Message: "Cannot convert string \"1.2.3\" to decimal"}},
+        {"1d / 0;", &object.Error{Message: "division by zero"}},
+        {"const h = {1.50d: \"price\"}; h[1.5d];", "price"},
+        {"isDecimal(1.5d) && isFloat(1.5) && !isFloat(1);", true},
+        {"match 12n { x: bigint => str(x), _ => \"other\" };", "12"},
+        {"match 1.50d { 1.5d => \"one and a half\", _ => \"other\" };", "one and a half"},
+        {"[1n, 2.0d] == [1, 2];", true},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
+func TestBitwiseOperators(t *testing.T) {
+    tests := []struct {
+        input string
+        expected interface{}
+    }{
+        {"0b1100 & 0b1010;", 8},
+        {"0b1100 | 0b1010;", 14},
+        {"0b1100 ^ 0b1010;", 6},
+        {"~0;", -1},
+        {"~0xff;", -256},
+        {"1 << 10;", 1024},
+        {"-16 >> 2;", -4},
+        {"1 >> 100;", 0},
+        {"-1 >> 100;", -1},
+        {"0xFF_FF;", 65535},
+        {"0o777;", 511},
+        {"1_000_000 + 1;", 1000001},
+        {"5 & 1 == 1;", true},
+        {"let flags = 0; flags |= 0b100; flags ^= 0b110; flags <<= 2; flags >>= 1; flags &= 0xf; flags;", 4},
+        {"1 << 63;", &object.Error{Message: "integer overflow: 1 << 63"}},
+        {"-1 << 63;", -9223372036854775807 - 1},
+        {"1 << -1;", &object.Error{Message: "negative shift count: -1"}},
+        {"1.5 & 1;", &object.Error{Message: "unsupported infix operator on floats"}},
+        {"~1.5;", &object.Error{Message: "unsupported unary expression"}},
+        {"str(1n << 100);", "1267650600228229401496703205376"},
+        {"str((1n << 100) >> 98);", "4"},
+        {"str(0xffn & 0x0f);", "15"},
+        {"str(~0n);", "-1"},
+        {"const mask = {\"bits\": 3, \"__bitand__\": fun(other) { return 3 & other; }, \"__bitnot__\": fun() { return -4; }}; [mask & 6, ~mask] == [2, -4];", true},
+    }
+
+    for _, tt := range tests {
+        evaluated := evaluate(t, tt.input)
+        testLiteral(t, evaluated, tt.expected)
+    }
+}
+
 func TestConditional(t *testing.T) {
     tests := []struct {
         input string
@@ -842,6 +1904,23 @@ func testErrorObject(t *testing.T, obj object.Object, expected *object.Error) {
     if errObj.Message != expected.Message {
         t.Fatalf("expected Error to be \"%s\", but got \"%s\"", expected.Message, errObj.Message)
     }
+
+    if len(expected.StackTrace) == 0 {
+        return
+    }
+    if len(errObj.StackTrace) != len(expected.StackTrace) {
+        t.Fatalf("expected a stack trace of %d frames, but got %v", len(expected.StackTrace), errObj.StackTrace)
+    }
+    for i, frame := range expected.StackTrace {
+        if errObj.StackTrace[i].String() != frame.String() {
+            t.Fatalf("expected frame %d to be %s, but got %s", i, frame.String(), errObj.StackTrace[i].String())
+        }
+    }
+}
+
+func init() {
+    // modules written by the tests are temporary and must not end up in the user cache
+    frontend.CACHE = false
 }
 
 func evaluate(t *testing.T, input string) object.Object {
@@ -0,0 +1,74 @@
+package eval
+
+import (
+    "fmt"
+    "strings"
+    "language/object"
+)
+
+var helpBuiltins = map[string]*object.Builtin{
+    "help": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            fmt.Println(Help(args[0]))
+            return NULL
+        },
+    },
+}
+
+func init() {
+    for name, builtin := range helpBuiltins {
+        builtins[name] = builtin
+    }
+}
+
+// Help describes a value for the help builtin, functions are shown with their signature and doc comment,
+// modules with their doc comment and exported names
+func Help(value object.Object) string {
+    switch value := value.(type) {
+    case *object.Function:
+        return value.String() + "\n" + indentDoc(value.Doc, "no documentation")
+    case *object.Builtin:
+        for _, name := range BuiltinNames() {
+            if builtins[name] == value {
+                return "builtin " + name
+            }
+        }
+        return "builtin function"
+    case *object.Module:
+        var out strings.Builder
+        out.WriteString("module " + value.Path + "\n" + indentDoc(value.Doc, "no documentation"))
+        for _, name := range value.ExportedNames() {
+            exported, _ := value.Env.Get(name)
+            if function, ok := exported.(*object.Function); ok {
+                named := &object.Function{Name: name, Parameters: function.Parameters, ReturnType: function.ReturnType}
+                out.WriteString("\n\n" + named.Signature())
+                if function.Doc != "" {
+                    out.WriteString("\n" + indentDoc(function.Doc, ""))
+                }
+            } else {
+                out.WriteString("\n\n" + name + ": " + string(exported.Type()))
+            }
+        }
+        return out.String()
+    default:
+        return "value of type " + string(value.Type())
+    }
+}
+
+// indentDoc indents the lines of a doc comment, empty is shown if there is no doc
+func indentDoc(doc string, empty string) string {
+    if doc == "" {
+        doc = empty
+    }
+    lines := strings.Split(doc, "\n")
+    for i, line := range lines {
+        if line != "" {
+            lines[i] = "    " + line
+        }
+    }
+    return strings.Join(lines, "\n")
+}
@@ -0,0 +1,121 @@
+package eval
+
+import (
+    "language/ast"
+    "language/object"
+)
+
+// the names of the functions of the iterator protocol, they are reserved so that the fields of ordinary hashes are not mistaken for them
+const (
+    HAS_NEXT_METHOD = "__hasNext__"
+    NEXT_METHOD = "__next__"
+    ITER_METHOD = "__iter__"
+)
+
+// userIterator adapts a hash with __hasNext__ and __next__ functions to the iterator protocol
+type userIterator struct {
+    hasNext object.Object
+    next object.Object
+    modules map[string]*object.Module
+    err object.Object
+}
+
+func (u *userIterator) Type() object.ObjectType {
+    return object.ITERATOR_OBJECT
+}
+
+func (u *userIterator) String() string {
+    return "iterator"
+}
+
+func (u *userIterator) HasNext() bool {
+    if u.err != nil {
+        return true
+    }
+    result := applyFunction(u.hasNext, []object.Object{}, u.modules, ast.PositionalInfo{})
+    if isError(result) {
+        // report the error on the next call of Next
+        u.err = result
+        return true
+    }
+    return isTruthy(result)
+}
+
+func (u *userIterator) Next() object.Object {
+    if u.err != nil {
+        err := u.err
+        u.err = nil
+        return err
+    }
+    return applyFunction(u.next, []object.Object{}, u.modules, ast.PositionalInfo{})
+}
+
+func (u *userIterator) Iterator() object.Iterator {
+    return u
+}
+
+// makeIterator returns an iterator for native iterables and for user objects.
+// A user object is an iterator if it has __hasNext__ and __next__ functions and it is iterable if it has an __iter__ function.
+func makeIterator(obj object.Object, modules map[string]*object.Module) (object.Iterator, *object.Error) {
+    if hash, ok := obj.(*object.Hash); ok {
+        hasNext, hasHasNext := hashFunction(hash, HAS_NEXT_METHOD)
+        next, hasNextFunction := hashFunction(hash, NEXT_METHOD)
+        if hasHasNext && hasNextFunction {
+            return &userIterator{hasNext: hasNext, next: next, modules: modules}, nil
+        }
+        if iteratorFunction, ok := hashFunction(hash, ITER_METHOD); ok {
+            iterator := applyFunction(iteratorFunction, []object.Object{}, modules, ast.PositionalInfo{})
+            if isError(iterator) {
+                if err, ok := iterator.(*object.Error); ok {
+                    return nil, err
+                }
+                return nil, makeErrorWithEmptyStacktrace("%s", iterator.String())
+            }
+            if iterator == obj {
+                return nil, makeErrorWithEmptyStacktrace("%s function did not return an iterator", ITER_METHOD)
+            }
+            return makeIterator(iterator, modules)
+        }
+    }
+    if iterable, ok := obj.(object.Iterable); ok {
+        return iterable.Iterator(), nil
+    }
+    return nil, makeErrorWithEmptyStacktrace("Cannot iterate over %s", obj.Type())
+}
+
+// isUserIterable reports if makeIterator uses the protocol functions of hash instead of iterating its keys
+func isUserIterable(hash *object.Hash) bool {
+    _, hasHasNext := hashFunction(hash, HAS_NEXT_METHOD)
+    _, hasNext := hashFunction(hash, NEXT_METHOD)
+    _, hasIterator := hashFunction(hash, ITER_METHOD)
+    return hasHasNext && hasNext || hasIterator
+}
+
+func hashFunction(hash *object.Hash, name string) (object.Object, bool) {
+    pair, ok := hash.Pairs[(&object.String{Value: name}).HashKey()]
+    if !ok {
+        return nil, false
+    }
+    switch pair.Value.(type) {
+    case *object.Function, *object.Builtin:
+        return pair.Value, true
+    }
+    return nil, false
+}
+
+// iterableElements drains the iterator of obj, the second result is an error object if iterating failed
+func iterableElements(obj object.Object, modules map[string]*object.Module) ([]object.Object, object.Object) {
+    iterator, err := makeIterator(obj, modules)
+    if err != nil {
+        return nil, err
+    }
+    elements := []object.Object{}
+    for iterator.HasNext() {
+        e := iterator.Next()
+        if isError(e) {
+            return nil, e
+        }
+        elements = append(elements, e)
+    }
+    return elements, nil
+}
@@ -0,0 +1,289 @@
+package eval
+
+import (
+    "language/ast"
+    "language/object"
+)
+
+var patternTypes = map[string][]object.ObjectType{
+    "int": {object.INTEGER_OBJECT},
+    "float": {object.FLOAT_OBJECT},
+    "bigint": {object.BIGINT_OBJECT},
+    "decimal": {object.DECIMAL_OBJECT},
+    "bool": {object.BOOLEAN_OBJECT},
+    "string": {object.STRING_OBJECT},
+    "null": {object.NULL_OBJECT},
+    "array": {object.ARRAY_OBJECT},
+    "hash": {object.HASH_OBJECT},
+    "function": {object.FUNCTION_OBJECT, object.BUILTIN_OBJECT},
+    "set": {object.SET_OBJECT},
+    "deque": {object.DEQUE_OBJECT},
+    "range": {object.RANGE_OBJECT},
+    "iterator": {object.ITERATOR_OBJECT},
+    "module": {object.MODULE_OBJECT},
+}
+
+func evalMatch(node *ast.MatchExpression, env *object.Environment, modules map[string]*object.Module) object.Object {
+    subject := Eval(node.Subject, env, modules)
+    if isError(subject) {
+        return subject
+    }
+
+    for _, arm := range node.Arms {
+        if err := checkPatternNames(arm.Pattern); err != nil {
+            return err
+        }
+        armEnv := object.NewEnclosingEnvironment(env)
+        matched, err := matchPattern(arm.Pattern, subject, armEnv, false, modules)
+        if err != nil {
+            return err
+        }
+        if !matched {
+            continue
+        }
+        if arm.Guard != nil {
+            guard := Eval(arm.Guard, armEnv, modules)
+            if isError(guard) {
+                return guard
+            }
+            if !isTruthy(guard) {
+                continue
+            }
+        }
+        return Eval(arm.Body, armEnv, modules)
+    }
+
+    return makeError(node.Position(), "no match arm for value %s (%s)", subject.String(), subject.Type())
+}
+
+// matchPattern checks if value matches the pattern and binds the names of the pattern in env
+func matchPattern(pattern ast.Pattern, value object.Object, env *object.Environment, constant bool, modules map[string]*object.Module) (bool, object.Object) {
+    switch pattern := pattern.(type) {
+    case *ast.WildcardPattern:
+        return true, nil
+
+    case *ast.IdentifierPattern:
+        return bindPatternName(pattern.Name, value, env, constant, pattern.Position())
+
+    case *ast.LiteralPattern:
+        literal := Eval(pattern.Value, env, modules)
+        if isError(literal) {
+            return false, literal
+        }
+        return objectsEqual(literal, value, modules), nil
+
+    case *ast.RangePattern:
+        return matchRangePattern(pattern, value, env, modules)
+
+    case *ast.TypePattern:
+        if !hasPatternType(pattern.TypeName, value) {
+            return false, nil
+        }
+        return bindPatternName(pattern.Name, value, env, constant, pattern.Position())
+
+    case *ast.ArrayPattern:
+        array, ok := value.(*object.Array)
+        if !ok {
+            return false, nil
+        }
+        if len(array.Elements) < len(pattern.Elements) {
+            return false, nil
+        }
+        if !pattern.HasRest && len(array.Elements) != len(pattern.Elements) {
+            return false, nil
+        }
+        for i, elementPattern := range pattern.Elements {
+            matched, err := matchPattern(elementPattern, array.Elements[i], env, constant, modules)
+            if err != nil || !matched {
+                return false, err
+            }
+        }
+        if pattern.HasRest {
+            rest := make([]object.Object, len(array.Elements) - len(pattern.Elements))
+            copy(rest, array.Elements[len(pattern.Elements):])
+            return bindPatternName(pattern.Rest, &object.Array{Elements: rest}, env, constant, pattern.Position())
+        }
+        return true, nil
+
+    case *ast.HashPattern:
+        hash, ok := value.(*object.Hash)
+        if !ok {
+            return false, nil
+        }
+        for i, keyExpr := range pattern.Keys {
+            key := Eval(keyExpr, env, modules)
+            if isError(key) {
+                return false, key
+            }
+            hashed, ok := object.HashKeyOf(key)
+            if !ok {
+                return false, makeError(keyExpr.Position(), "%s is not hashable", key.Type())
+            }
+            pair, ok := hash.Pairs[hashed]
+            if !ok {
+                return false, nil
+            }
+            matched, err := matchPattern(pattern.Values[i], pair.Value, env, constant, modules)
+            if err != nil || !matched {
+                return false, err
+            }
+        }
+        return true, nil
+    }
+
+    return false, makeError(pattern.Position(), "Unknown pattern of type: %T", pattern)
+}
+
+func matchRangePattern(pattern *ast.RangePattern, value object.Object, env *object.Environment, modules map[string]*object.Module) (bool, object.Object) {
+    start := Eval(pattern.Start, env, modules)
+    if isError(start) {
+        return false, start
+    }
+    end := Eval(pattern.End, env, modules)
+    if isError(end) {
+        return false, end
+    }
+
+    switch value := value.(type) {
+    case *object.Integer:
+        startValue, startOk := start.(*object.Integer)
+        endValue, endOk := end.(*object.Integer)
+        if !startOk || !endOk {
+            return false, nil
+        }
+        if pattern.Inclusive {
+            return value.Value >= startValue.Value && value.Value <= endValue.Value, nil
+        }
+        return value.Value >= startValue.Value && value.Value < endValue.Value, nil
+    case *object.String:
+        startValue, startOk := start.(*object.String)
+        endValue, endOk := end.(*object.String)
+        if !startOk || !endOk {
+            return false, nil
+        }
+        if pattern.Inclusive {
+            return value.Value >= startValue.Value && value.Value <= endValue.Value, nil
+        }
+        return value.Value >= startValue.Value && value.Value < endValue.Value, nil
+    }
+    return false, nil
+}
+
+func hasPatternType(typeName string, value object.Object) bool {
+    if typeName == "any" {
+        return true
+    }
+    for _, t := range patternTypes[typeName] {
+        if value.Type() == t {
+            return true
+        }
+    }
+    return false
+}
+
+func bindPatternName(name string, value object.Object, env *object.Environment, constant bool, posInfo ast.PositionalInfo) (bool, object.Object) {
+    if name == "_" {
+        return true, nil
+    }
+    if constant {
+        if !env.AddConst(name, value) {
+            return false, makeError(posInfo, "Cannot redefine constant %s", name)
+        }
+    } else if !env.Add(name, value) {
+        return false, makeError(posInfo, "Cannot redefine variable %s", name)
+    }
+    return true, nil
+}
+
+// checkPatternNames fails if a name is bound twice in one pattern
+func checkPatternNames(pattern ast.Pattern) object.Object {
+    names := map[string]bool{}
+    for _, name := range ast.PatternNames(pattern) {
+        if names[name] {
+            return makeError(pattern.Position(), "Cannot bind %s twice in one pattern", name)
+        }
+        names[name] = true
+    }
+    return nil
+}
+
+// destructure binds the names of the pattern in env, it fails with an error describing the mismatch if the value does not have the shape of the pattern
+func destructure(pattern ast.Pattern, value object.Object, env *object.Environment, constant bool, modules map[string]*object.Module) object.Object {
+    if err := checkPatternNames(pattern); err != nil {
+        return err
+    }
+    return destructureNames(pattern, value, env, constant, modules)
+}
+
+func destructureNames(pattern ast.Pattern, value object.Object, env *object.Environment, constant bool, modules map[string]*object.Module) object.Object {
+    switch pattern := pattern.(type) {
+    case *ast.ArrayPattern:
+        array, ok := value.(*object.Array)
+        if !ok {
+            return makeError(pattern.Position(), "cannot destructure %s into %s, expected an array", value.Type(), pattern.String())
+        }
+        if pattern.HasRest && len(array.Elements) < len(pattern.Elements) {
+            return makeError(pattern.Position(), "cannot destructure an array of length %d into %s, expected at least %d elements", len(array.Elements), pattern.String(), len(pattern.Elements))
+        }
+        if !pattern.HasRest && len(array.Elements) != len(pattern.Elements) {
+            return makeError(pattern.Position(), "cannot destructure an array of length %d into %s, expected %d elements", len(array.Elements), pattern.String(), len(pattern.Elements))
+        }
+        for i, elementPattern := range pattern.Elements {
+            err := destructureNames(elementPattern, array.Elements[i], env, constant, modules)
+            if err != nil {
+                return err
+            }
+        }
+        if pattern.HasRest {
+            rest := make([]object.Object, len(array.Elements) - len(pattern.Elements))
+            copy(rest, array.Elements[len(pattern.Elements):])
+            _, err := bindPatternName(pattern.Rest, &object.Array{Elements: rest}, env, constant, pattern.Position())
+            return err
+        }
+        return nil
+
+    case *ast.HashPattern:
+        hash, ok := value.(*object.Hash)
+        if !ok {
+            return makeError(pattern.Position(), "cannot destructure %s into %s, expected a hash", value.Type(), pattern.String())
+        }
+        for i, keyExpr := range pattern.Keys {
+            key := Eval(keyExpr, env, modules)
+            if isError(key) {
+                return key
+            }
+            hashed, ok := object.HashKeyOf(key)
+            if !ok {
+                return makeError(keyExpr.Position(), "%s is not hashable", key.Type())
+            }
+            pair, ok := hash.Pairs[hashed]
+            if !ok {
+                return makeError(pattern.Position(), "cannot destructure hash into %s, key %s is missing", pattern.String(), key.String())
+            }
+            err := destructureNames(pattern.Values[i], pair.Value, env, constant, modules)
+            if err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+
+    matched, err := matchPattern(pattern, value, env, constant, modules)
+    if err != nil {
+        return err
+    }
+    if !matched {
+        return makeError(pattern.Position(), "cannot destructure %s, it does not match %s", value.String(), pattern.String())
+    }
+    return nil
+}
+
+// bindLoopElement binds the element of a range loop to its name in loopEnv or to the names of the pattern in a new environment
+func bindLoopElement(name string, pattern ast.Pattern, element object.Object, loopEnv *object.Environment, modules map[string]*object.Module) (*object.Environment, object.Object) {
+    if pattern == nil {
+        loopEnv.Set(name, element)
+        return loopEnv, nil
+    }
+    elementEnv := object.NewEnclosingEnvironment(loopEnv)
+    return elementEnv, destructure(pattern, element, elementEnv, false, modules)
+}
@@ -0,0 +1,218 @@
+package eval
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "language/ast"
+    "language/object"
+    "language/frontend"
+    "language/project"
+)
+
+// PROJECT is the manifest of the project being run, its dependencies and search paths are tried first on imports
+var PROJECT *project.Manifest
+
+// readOnlyModules is set by --readonly-modules, FMLREADONLYMODULES or the manifest of the project
+func readOnlyModules() bool {
+    return READONLYMODULES || (PROJECT != nil && PROJECT.ReadOnlyModules)
+}
+
+func evalImport(node *ast.ImportStatement, env *object.Environment, modules map[string]*object.Module) object.Object {
+    module, err := loadModule(node.Path, node.Position(), modules)
+    if err != nil {
+        return err
+    }
+
+    if node.Wildcard {
+        names := module.ExportedNames()
+        if module.Loading {
+            if module.Exports == nil {
+                return makeError(node.Position(), "Cannot import * from module %s without exports, it is still loading because of the circular import %s", module.Path, module.Cycle)
+            }
+            names = []string{}
+            for name := range module.Exports {
+                names = append(names, name)
+            }
+            sort.Strings(names)
+        }
+        for _, name := range names {
+            if !env.AddConst(name, importedValue(module, name)) {
+                return makeError(node.Position(), "Cannot import %s, the name is already taken", name)
+            }
+        }
+        return NULL
+    }
+
+    if !node.IsSelective() {
+        if !env.AddConst(node.Name, module) {
+            return makeError(node.Position(), "Cannot define module with this name, it is already taken")
+        }
+        return NULL
+    }
+
+    for _, name := range node.Names {
+        if _, ok := module.Env.Get(name.Name); !ok && !module.Loading {
+            return makeError(node.Position(), "Cannot find %s in module %s", name.Name, module.Path)
+        }
+        if !module.IsExported(name.Name) {
+            return makeError(node.Position(), "%s is not exported by module %s", name.Name, module.Path)
+        }
+        if !env.AddConst(name.Binding(), importedValue(module, name.Name)) {
+            return makeError(node.Position(), "Cannot import %s, the name is already taken", name.Binding())
+        }
+    }
+    return NULL
+}
+
+// importedValue returns the value of name in module, a name of a module which is still loading because of a circular import
+// is bound lazily and resolved when it is used
+func importedValue(module *object.Module, name string) object.Object {
+    if value, ok := module.Env.Get(name); ok {
+        return value
+    }
+    return &object.ImportBinding{Module: module, Name: name}
+}
+
+// unresolvedImport is the error of using a name imported circularly before its module defined it
+func unresolvedImport(binding *object.ImportBinding, posInfo ast.PositionalInfo) *object.Error {
+    if binding.Module.Loading {
+        return makeError(posInfo, "Cannot use %s of module %s, it is not defined yet because of the circular import %s", binding.Name, binding.Module.Path, binding.Module.Cycle)
+    }
+    return makeError(posInfo, "Cannot find %s in module %s", binding.Name, binding.Module.Path)
+}
+
+// loadModule returns the module at path, its code is only run on the first import
+func loadModule(path string, posInfo ast.PositionalInfo, modules map[string]*object.Module) (*object.Module, object.Object) {
+    if newModule, ok := nativeModules[path]; ok {
+        if _, ok := modules[path]; !ok {
+            modules[path] = newModule()
+        }
+        return modules[path], nil
+    }
+    path, err := resolveModulePath(path)
+    if err != nil {
+        return nil, makeError(posInfo, "%s", err)
+    }
+    // don't load module if already loaded, a module that is still loading is imported circularly,
+    // the names imported from it are resolved when they are used
+    if foundModule, ok := modules[path]; ok {
+        if foundModule.Loading {
+            foundModule.Cycle = importCycle(path, posInfo, modules)
+        }
+        return foundModule, nil
+    }
+    old_MODULEPATH := MODULEPATH
+    MODULEPATH = filepath.Dir(path)
+    defer func() {
+        MODULEPATH = old_MODULEPATH
+    }()
+    moduleCode, errs := frontend.Build(path)
+    if len(errs) > 0 {
+        return nil, makeParserErrors(errs)
+    }
+    module := &object.Module{Env: object.NewEnvironment(), Path: path, ReadOnly: readOnlyModules(), Loading: true, Doc: moduleCode.Doc, LoadedAt: posInfo}
+    if exports, ok := moduleCode.Exports(); ok {
+        module.Exports = map[string]bool{}
+        for _, name := range exports {
+            module.Exports[name] = true
+        }
+    }
+    modules[path] = module
+    result := Eval(moduleCode, module.Env, modules)
+    module.Loading = false
+    if isError(result) {
+        delete(modules, path)
+        if err, ok := result.(*object.Error); ok {
+            return nil, addToStacktrace(posInfo, err)
+        }
+        return nil, result
+    }
+    return module, nil
+}
+
+// importCycle describes the chain of imports from the loading module at path to the import at posInfo importing it again,
+// the chain is followed backwards by the imports which loaded the modules
+func importCycle(path string, posInfo ast.PositionalInfo, modules map[string]*object.Module) string {
+    steps := []string{posInfo.String(), path}
+    for module, ok := modules[posInfo.Path]; ok && module.Path != path; module, ok = modules[module.LoadedAt.Path] {
+        steps = append([]string{module.LoadedAt.String()}, steps...)
+    }
+    return strings.Join(steps, " -> ")
+}
+
+// resolveModulePath makes path absolute, relative paths are looked up in the project, in FMLPATH and then next to the importing module,
+// a bundled program only finds the modules of its bundle
+func resolveModulePath(path string) (string, error) {
+    if MODULEPATH == "" {
+        cwd, err := os.Getwd()
+        if err != nil {
+            return "", err
+        }
+        MODULEPATH = cwd
+    }
+    if frontend.BUNDLE != nil {
+        resolved, ok := frontend.BUNDLE.Resolve(MODULEPATH, path)
+        if !ok {
+            return "", fmt.Errorf("module %s is not part of the bundle", path)
+        }
+        return resolved, nil
+    }
+    return ResolveImport(path, MODULEPATH), nil
+}
+
+// ResolveImport makes the path of an import in dir absolute, relative paths are looked up in the project,
+// in FMLPATH and then in dir
+func ResolveImport(path string, dir string) string {
+    if filepath.IsAbs(path) {
+        return path
+    }
+    if PROJECT != nil {
+        if resolved, ok := PROJECT.Resolve(path); ok {
+            return resolved
+        }
+    }
+    if FMLPATH != "" {
+        core_path := filepath.Join(FMLPATH, path)
+        info, err := os.Stat(core_path)
+        if err == nil && !info.IsDir() {
+            return core_path
+        }
+    }
+    return filepath.Join(dir, path)
+}
+
+func evalModule(lhs *object.Module, index object.Object, posInfo ast.PositionalInfo) object.Object {
+    name := index.String()
+    result, ok := lhs.Env.Get(name)
+    if binding, isBinding := result.(*object.ImportBinding); isBinding {
+        return unresolvedImport(binding, posInfo)
+    }
+    if !ok && lhs.Loading {
+        return makeError(posInfo, "Cannot find %s in module %s, it is not defined yet because of the circular import %s", name, lhs.Path, lhs.Cycle)
+    }
+    if !ok {
+        return makeError(posInfo, "Cannot find %s in module", name)
+    }
+    if !lhs.IsExported(name) {
+        return makeError(posInfo, "%s is not exported by module %s", name, lhs.Path)
+    }
+    return result
+}
+
+func evalModuleIndexSet(module *object.Module, index object.Object, value object.Object, posInfo ast.PositionalInfo) object.Object {
+    name := index.String()
+    if !module.IsExported(name) {
+        return makeError(posInfo, "%s is not exported by module %s", name, module.Path)
+    }
+    if module.ReadOnly {
+        return makeError(posInfo, "Cannot set %s, module %s is read-only", name, module.Path)
+    }
+    ok := module.Env.Set(name, value)
+    if !ok {
+        return makeError(posInfo, "Cannot set value in module")
+    }
+    return value
+}
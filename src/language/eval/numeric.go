@@ -0,0 +1,345 @@
+package eval
+
+import (
+    "fmt"
+    "math"
+    "math/big"
+    "strconv"
+    "language/ast"
+    "language/object"
+    "language/token"
+)
+
+// numberRanks orders the number types, the operand with the lower rank is converted to the type of the other one
+var numberRanks = map[object.ObjectType]int{
+    object.INTEGER_OBJECT: 1,
+    object.BIGINT_OBJECT: 2,
+    object.DECIMAL_OBJECT: 3,
+    object.FLOAT_OBJECT: 4,
+}
+
+// promoteNumbers converts two numbers of different types to a common type, the third result is false if
+// decimals and floats are mixed in arithmetic, since that would silently lose the exactness of the decimal
+func promoteNumbers(op token.Token, lhs object.Object, rhs object.Object) (object.Object, object.Object, bool) {
+    lhsRank := numberRanks[lhs.Type()]
+    rhsRank := numberRanks[rhs.Type()]
+    if lhsRank == 0 || rhsRank == 0 || lhsRank == rhsRank {
+        return lhs, rhs, true
+    }
+
+    target := lhs.Type()
+    if rhsRank > lhsRank {
+        target = rhs.Type()
+    }
+    if target == object.FLOAT_OBJECT && (lhs.Type() == object.DECIMAL_OBJECT || rhs.Type() == object.DECIMAL_OBJECT) && !isComparison(op) {
+        return lhs, rhs, false
+    }
+    return convertNumber(lhs, target), convertNumber(rhs, target), true
+}
+
+func convertNumber(obj object.Object, target object.ObjectType) object.Object {
+    if obj.Type() == target {
+        return obj
+    }
+    switch target {
+    case object.BIGINT_OBJECT:
+        return &object.BigInt{Value: toBigInt(obj)}
+    case object.DECIMAL_OBJECT:
+        return object.NewDecimal(toBigInt(obj))
+    case object.FLOAT_OBJECT:
+        return &object.Float{Value: toFloat(obj)}
+    }
+    return obj
+}
+
+// toBigInt converts integers and bigints, decimals are truncated
+func toBigInt(obj object.Object) *big.Int {
+    switch obj := obj.(type) {
+    case *object.Integer:
+        return big.NewInt(obj.Value)
+    case *object.BigInt:
+        return obj.Value
+    case *object.Decimal:
+        return obj.Truncate()
+    }
+    return new(big.Int)
+}
+
+func toFloat(obj object.Object) float64 {
+    switch obj := obj.(type) {
+    case *object.Integer:
+        return float64(obj.Value)
+    case *object.BigInt:
+        f, _ := new(big.Float).SetInt(obj.Value).Float64()
+        return f
+    case *object.Decimal:
+        return obj.Float64()
+    case *object.Float:
+        return obj.Value
+    }
+    return 0
+}
+
+// normalizeInteger returns an Integer if value fits into one
+func normalizeInteger(value *big.Int) object.Object {
+    if value.IsInt64() {
+        return &object.Integer{Value: value.Int64()}
+    }
+    return &object.BigInt{Value: value}
+}
+
+func isComparison(op token.Token) bool {
+    switch op.Type {
+    case token.LT, token.GT, token.LE, token.GE, token.EQ, token.NEQ:
+        return true
+    }
+    return false
+}
+
+// checkedIntegerArithmetic computes lhs op rhs, the second result describes the error if the result overflows or rhs is zero
+func checkedIntegerArithmetic(op token.Token, lhs int64, rhs int64) (int64, string) {
+    switch op.Type {
+    case token.ADD:
+        result := lhs + rhs
+        if (rhs > 0 && result < lhs) || (rhs < 0 && result > lhs) {
+            return 0, integerOverflow(op, lhs, rhs)
+        }
+        return result, ""
+    case token.SUB:
+        result := lhs - rhs
+        if (rhs > 0 && result > lhs) || (rhs < 0 && result < lhs) {
+            return 0, integerOverflow(op, lhs, rhs)
+        }
+        return result, ""
+    case token.MULT:
+        if lhs == 0 || rhs == 0 {
+            return 0, ""
+        }
+        result := lhs * rhs
+        if result / rhs != lhs || (lhs == -1 && rhs == math.MinInt64) || (rhs == -1 && lhs == math.MinInt64) {
+            return 0, integerOverflow(op, lhs, rhs)
+        }
+        return result, ""
+    }
+
+    if rhs == 0 {
+        return 0, "division by zero"
+    }
+    if lhs == math.MinInt64 && rhs == -1 && op.Type != token.MOD {
+        return 0, integerOverflow(op, lhs, rhs)
+    }
+
+    switch op.Type {
+    case token.DIV:
+        return lhs / rhs, ""
+    case token.MOD:
+        return lhs % rhs, ""
+    case token.FLOORDIV:
+        result := lhs / rhs
+        if lhs % rhs != 0 && (lhs < 0) != (rhs < 0) {
+            result--
+        }
+        return result, ""
+    }
+    return 0, fmt.Sprintf("unsupported integer operator %s", op.Type)
+}
+
+// integerOverflow describes an overflowing operation, it is only formatted on the error path
+func integerOverflow(op token.Token, lhs int64, rhs int64) string {
+    return fmt.Sprintf("integer overflow: %d %s %d", lhs, op.Type, rhs)
+}
+
+// integerShift shifts lhs by rhs bits, shifting out set bits to the left is an overflow
+func integerShift(op token.Token, lhs int64, rhs int64, posInfo ast.PositionalInfo) object.Object {
+    if rhs < 0 {
+        return makeError(posInfo, "negative shift count: %d", rhs)
+    }
+    if op.Type == token.SHR {
+        if rhs >= 64 {
+            rhs = 63
+        }
+        return &object.Integer{Value: lhs >> uint(rhs)}
+    }
+    if rhs >= 64 || (lhs << uint(rhs)) >> uint(rhs) != lhs {
+        return makeError(posInfo, "integer overflow: %d << %d", lhs, rhs)
+    }
+    return &object.Integer{Value: lhs << uint(rhs)}
+}
+
+// integerPower computes lhs ** rhs by squaring, a negative exponent gives a float
+func integerPower(lhs int64, rhs int64, posInfo ast.PositionalInfo) object.Object {
+    if rhs < 0 {
+        return &object.Float{Value: math.Pow(float64(lhs), float64(rhs))}
+    }
+
+    result := int64(1)
+    base := lhs
+    mult := token.Token{Type: token.MULT}
+    for exponent := rhs; exponent > 0; exponent >>= 1 {
+        var err string
+        if exponent & 1 == 1 {
+            result, err = checkedIntegerArithmetic(mult, result, base)
+            if err != "" {
+                return makeError(posInfo, "integer overflow: %d ** %d", lhs, rhs)
+            }
+        }
+        if exponent > 1 {
+            base, err = checkedIntegerArithmetic(mult, base, base)
+            if err != "" {
+                return makeError(posInfo, "integer overflow: %d ** %d", lhs, rhs)
+            }
+        }
+    }
+    return &object.Integer{Value: result}
+}
+
+// floatFloorDivision rounds the quotient down to an integer, like integer floor division
+func floatFloorDivision(lhs float64, rhs float64, posInfo ast.PositionalInfo) object.Object {
+    if rhs == 0 {
+        return makeError(posInfo, "division by zero")
+    }
+    result := math.Floor(lhs / rhs)
+    if math.IsNaN(result) || result < math.MinInt64 || result >= math.MaxInt64 {
+        return makeError(posInfo, "integer overflow: %s ~/ %s", formatFloat(lhs), formatFloat(rhs))
+    }
+    return &object.Integer{Value: int64(result)}
+}
+
+func formatFloat(value float64) string {
+    return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func evalBigIntInfix(op token.Token, lhs *big.Int, rhs *big.Int, posInfo ast.PositionalInfo) object.Object {
+    switch op.Type {
+    case token.ADD:
+        return &object.BigInt{Value: new(big.Int).Add(lhs, rhs)}
+    case token.SUB:
+        return &object.BigInt{Value: new(big.Int).Sub(lhs, rhs)}
+    case token.MULT:
+        return &object.BigInt{Value: new(big.Int).Mul(lhs, rhs)}
+    case token.DIV, token.MOD, token.FLOORDIV:
+        if rhs.Sign() == 0 {
+            return makeError(posInfo, "division by zero")
+        }
+        quotient, remainder := new(big.Int).QuoRem(lhs, rhs, new(big.Int))
+        if op.Type == token.MOD {
+            return &object.BigInt{Value: remainder}
+        }
+        if op.Type == token.FLOORDIV && remainder.Sign() != 0 && (lhs.Sign() < 0) != (rhs.Sign() < 0) {
+            quotient.Sub(quotient, big.NewInt(1))
+        }
+        return &object.BigInt{Value: quotient}
+    case token.POW:
+        if rhs.Sign() < 0 {
+            return &object.Float{Value: math.Pow(toFloat(&object.BigInt{Value: lhs}), toFloat(&object.BigInt{Value: rhs}))}
+        }
+        if !rhs.IsInt64() || rhs.Int64() > math.MaxInt32 {
+            return makeError(posInfo, "exponent %s is too large", rhs.String())
+        }
+        return &object.BigInt{Value: new(big.Int).Exp(lhs, rhs, nil)}
+    case token.BITAND:
+        return &object.BigInt{Value: new(big.Int).And(lhs, rhs)}
+    case token.BITOR:
+        return &object.BigInt{Value: new(big.Int).Or(lhs, rhs)}
+    case token.BITXOR:
+        return &object.BigInt{Value: new(big.Int).Xor(lhs, rhs)}
+    case token.SHL, token.SHR:
+        if rhs.Sign() < 0 {
+            return makeError(posInfo, "negative shift count: %s", rhs.String())
+        }
+        if !rhs.IsInt64() || rhs.Int64() > math.MaxInt32 {
+            return makeError(posInfo, "shift count %s is too large", rhs.String())
+        }
+        if op.Type == token.SHL {
+            return &object.BigInt{Value: new(big.Int).Lsh(lhs, uint(rhs.Int64()))}
+        }
+        return &object.BigInt{Value: new(big.Int).Rsh(lhs, uint(rhs.Int64()))}
+    case token.LT:
+        return boolToBoolean(lhs.Cmp(rhs) < 0)
+    case token.GT:
+        return boolToBoolean(lhs.Cmp(rhs) > 0)
+    case token.LE:
+        return boolToBoolean(lhs.Cmp(rhs) <= 0)
+    case token.GE:
+        return boolToBoolean(lhs.Cmp(rhs) >= 0)
+    case token.EQ:
+        return boolToBoolean(lhs.Cmp(rhs) == 0)
+    case token.NEQ:
+        return boolToBoolean(lhs.Cmp(rhs) != 0)
+    default:
+        return makeError(posInfo, "unsupported infix operator on bigints")
+    }
+}
+
+func evalDecimalInfix(op token.Token, lhs *object.Decimal, rhs *object.Decimal, posInfo ast.PositionalInfo) object.Object {
+    switch op.Type {
+    case token.ADD:
+        return lhs.Add(rhs)
+    case token.SUB:
+        return lhs.Sub(rhs)
+    case token.MULT:
+        return lhs.Mul(rhs)
+    case token.DIV, token.MOD, token.FLOORDIV:
+        if rhs.Sign() == 0 {
+            return makeError(posInfo, "division by zero")
+        }
+        switch op.Type {
+        case token.DIV:
+            return lhs.Quo(rhs)
+        case token.MOD:
+            return lhs.Rem(rhs)
+        }
+        return normalizeInteger(lhs.FloorQuo(rhs))
+    case token.POW:
+        return decimalPower(lhs, rhs, posInfo)
+    case token.LT:
+        return boolToBoolean(lhs.Cmp(rhs) < 0)
+    case token.GT:
+        return boolToBoolean(lhs.Cmp(rhs) > 0)
+    case token.LE:
+        return boolToBoolean(lhs.Cmp(rhs) <= 0)
+    case token.GE:
+        return boolToBoolean(lhs.Cmp(rhs) >= 0)
+    case token.EQ:
+        return boolToBoolean(lhs.Cmp(rhs) == 0)
+    case token.NEQ:
+        return boolToBoolean(lhs.Cmp(rhs) != 0)
+    default:
+        return makeError(posInfo, "unsupported infix operator on decimals")
+    }
+}
+
+// decimalPower only supports integral exponents, since other powers are not exact
+func decimalPower(lhs *object.Decimal, rhs *object.Decimal, posInfo ast.PositionalInfo) object.Object {
+    exponent := rhs.Normalize()
+    if exponent.Scale != 0 {
+        return makeError(posInfo, "the exponent of a decimal has to be integral, got %s", rhs.String())
+    }
+    if !exponent.Unscaled.IsInt64() || exponent.Unscaled.Int64() > math.MaxInt32 || exponent.Unscaled.Int64() < -math.MaxInt32 {
+        return makeError(posInfo, "exponent %s is too large", rhs.String())
+    }
+
+    n := exponent.Unscaled.Int64()
+    negative := n < 0
+    if negative {
+        n = -n
+    }
+    result := object.NewDecimal(big.NewInt(1))
+    base := lhs
+    for ; n > 0; n >>= 1 {
+        if n & 1 == 1 {
+            result = result.Mul(base)
+        }
+        if n > 1 {
+            base = base.Mul(base)
+        }
+    }
+
+    if negative {
+        if result.Sign() == 0 {
+            return makeError(posInfo, "division by zero")
+        }
+        return object.NewDecimal(big.NewInt(1)).Quo(result)
+    }
+    return result
+}
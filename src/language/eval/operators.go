@@ -0,0 +1,183 @@
+package eval
+
+import (
+    "fmt"
+    "strings"
+    "language/ast"
+    "language/object"
+    "language/token"
+)
+
+// operatorMethods are the names of the functions a hash defines to overload an operator, the function gets the right hand side as argument.
+// The names are reserved so that the keys of ordinary hashes don't change the meaning of operators.
+var operatorMethods = map[token.TokenType]string{
+    token.ADD: "__add__",
+    token.SUB: "__sub__",
+    token.MULT: "__mul__",
+    token.DIV: "__div__",
+    token.MOD: "__mod__",
+    token.POW: "__pow__",
+    token.FLOORDIV: "__floordiv__",
+    token.BITAND: "__bitand__",
+    token.BITOR: "__bitor__",
+    token.BITXOR: "__bitxor__",
+    token.SHL: "__shl__",
+    token.SHR: "__shr__",
+    token.EQ: "__eq__",
+    token.NEQ: "__eq__",
+    token.LT: "__lt__",
+    token.LE: "__le__",
+    token.GT: "__gt__",
+    token.GE: "__ge__",
+}
+
+// unaryOperatorMethods are the names of the functions a hash defines to overload a unary operator
+var unaryOperatorMethods = map[token.TokenType]string{
+    token.SUB: "__neg__",
+    token.BITNOT: "__bitnot__",
+}
+
+// the names of the functions a hash defines to handle missing keys in brackets, to be called and to be converted to a string
+const (
+    INDEX_METHOD = "__index__"
+    CALL_METHOD = "__call__"
+    TO_STRING_METHOD = "__toString__"
+)
+
+var operatorBuiltins = map[string]*object.Builtin{
+    "print": &object.Builtin{
+        WithModules: func(modules map[string]*object.Module, args ...object.Object) object.Object {
+            strs, err := displayStrings(args, modules)
+            if err != nil {
+                return err
+            }
+            fmt.Printf("%s", strings.Join(strs, ", "))
+
+            return NULL
+        },
+    },
+    "println": &object.Builtin{
+        WithModules: func(modules map[string]*object.Module, args ...object.Object) object.Object {
+            strs, err := displayStrings(args, modules)
+            if err != nil {
+                return err
+            }
+            fmt.Printf("%s\n", strings.Join(strs, ", "))
+
+            return NULL
+        },
+    },
+    "str": &object.Builtin{
+        WithModules: func(modules map[string]*object.Module, args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+
+            return displayString(args[0], modules)
+        },
+    },
+}
+
+func init() {
+    for name, builtin := range operatorBuiltins {
+        builtins[name] = builtin
+    }
+}
+
+// evalOverloadedInfix calls the operator function of lhs, the second result is false if the operator is not overloaded.
+// For == and != the right hand side is asked as well, if only it defines __eq__.
+func evalOverloadedInfix(op token.Token, lhs object.Object, rhs object.Object, modules map[string]*object.Module, posInfo ast.PositionalInfo) (object.Object, bool) {
+    name, ok := operatorMethods[op.Type]
+    if !ok {
+        return nil, false
+    }
+
+    method, ok := operatorMethod(lhs, name)
+    if !ok && (op.Type == token.EQ || op.Type == token.NEQ) {
+        method, ok = operatorMethod(rhs, name)
+        lhs, rhs = rhs, lhs
+    }
+    if !ok {
+        return nil, false
+    }
+
+    result := applyFunction(method, []object.Object{rhs}, modules, posInfo)
+    if err, ok := result.(*object.Error); ok {
+        return addToStacktrace(posInfo, err), true
+    }
+    if op.Type == token.NEQ {
+        return computeNegExpr(result), true
+    }
+    return result, true
+}
+
+func operatorMethod(obj object.Object, name string) (object.Object, bool) {
+    hash, ok := obj.(*object.Hash)
+    if !ok {
+        return nil, false
+    }
+    return hashFunction(hash, name)
+}
+
+// callableFunction returns the __call__ function of a hash, so the hash can be called like a function
+func callableFunction(obj object.Object) (object.Object, bool) {
+    return operatorMethod(obj, CALL_METHOD)
+}
+
+// displayString converts obj to a string the way str and println do, hashes can define it with a __toString__ function.
+// Containers show their elements this way too.
+func displayString(obj object.Object, modules map[string]*object.Module) object.Object {
+    switch obj := obj.(type) {
+    case *object.Hash:
+        if toString, ok := hashFunction(obj, TO_STRING_METHOD); ok {
+            result := applyFunction(toString, []object.Object{}, modules, ast.PositionalInfo{})
+            if isError(result) {
+                return result
+            }
+            if _, ok := result.(*object.String); !ok {
+                return makeErrorWithEmptyStacktrace("%s has to return a string, got %s", TO_STRING_METHOD, result.Type())
+            }
+            return result
+        }
+        pairs := []string{}
+        for _, pair := range obj.Pairs {
+            strs, err := displayStrings([]object.Object{pair.Key, pair.Value}, modules)
+            if err != nil {
+                return err
+            }
+            pairs = append(pairs, strs[0] + ": " + strs[1])
+        }
+        return &object.String{Value: "{" + strings.Join(pairs, ", ") + "}"}
+    case *object.Array:
+        elements, err := displayStrings(obj.Elements, modules)
+        if err != nil {
+            return err
+        }
+        return &object.String{Value: "[" + strings.Join(elements, ", ") + "]"}
+    case *object.Set:
+        elements, err := displayStrings(obj.Values(), modules)
+        if err != nil {
+            return err
+        }
+        return &object.String{Value: "set(" + strings.Join(elements, ", ") + ")"}
+    case *object.Deque:
+        elements, err := displayStrings(obj.Elements(), modules)
+        if err != nil {
+            return err
+        }
+        return &object.String{Value: "deque(" + strings.Join(elements, ", ") + ")"}
+    }
+    return &object.String{Value: obj.String()}
+}
+
+func displayStrings(objs []object.Object, modules map[string]*object.Module) ([]string, object.Object) {
+    strs := []string{}
+    for _, obj := range objs {
+        str := displayString(obj, modules)
+        if isError(str) {
+            return nil, str
+        }
+        strs = append(strs, str.(*object.String).Value)
+    }
+    return strs, nil
+}
@@ -0,0 +1,107 @@
+package eval
+
+import (
+    "os"
+    "language/object"
+)
+
+var (
+    // ARGS are the command line arguments passed to the script
+    ARGS = []string{}
+    // EXIT ends the process, it can be replaced to run scripts calling os.exit in tests
+    EXIT = os.Exit
+)
+
+// nativeModules are imported by name instead of a path, like import "os"
+var nativeModules = map[string]func() *object.Module{
+    "os": newOsModule,
+}
+
+func IsNativeModule(path string) bool {
+    _, ok := nativeModules[path]
+    return ok
+}
+
+func newOsModule() *object.Module {
+    args := []object.Object{}
+    for _, arg := range ARGS {
+        args = append(args, &object.String{Value: arg})
+    }
+    env := object.NewEnvironment()
+    env.AddConst("args", &object.Array{Elements: args, Frozen: true})
+    for name, builtin := range osBuiltins {
+        env.AddConst(name, builtin)
+    }
+    return &object.Module{Env: env, Path: "os", ReadOnly: true}
+}
+
+var osBuiltins = map[string]*object.Builtin{
+    "env": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 1 {
+                return makeBuiltinError("wrong number of arguments, want 1, got %d", len(args))
+            }
+            name, ok := args[0].(*object.String)
+            if !ok {
+                return makeBuiltinError("expected argument to be of type string")
+            }
+            value, ok := os.LookupEnv(name.Value)
+            if !ok {
+                return NULL
+            }
+            return &object.String{Value: value}
+        },
+    },
+    "setEnv": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 2 {
+                return makeBuiltinError("wrong number of arguments, want 2, got %d", len(args))
+            }
+            name, ok := args[0].(*object.String)
+            if !ok {
+                return makeBuiltinError("expected argument to be of type string")
+            }
+            var err error
+            if args[1] == NULL {
+                err = os.Unsetenv(name.Value)
+            } else if value, ok := args[1].(*object.String); ok {
+                err = os.Setenv(name.Value, value.Value)
+            } else {
+                return makeBuiltinError("expected argument to be of type string or null")
+            }
+            if err != nil {
+                return makeBuiltinError("cannot set %s: %s", name.Value, err)
+            }
+            return NULL
+        },
+    },
+    "cwd": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) != 0 {
+                return makeBuiltinError("wrong number of arguments, want 0, got %d", len(args))
+            }
+            cwd, err := os.Getwd()
+            if err != nil {
+                return makeBuiltinError("%s", err)
+            }
+            return &object.String{Value: cwd}
+        },
+    },
+    "exit": &object.Builtin{
+        Function: func(args ...object.Object) object.Object {
+            if len(args) > 1 {
+                return makeBuiltinError("wrong number of arguments, want 0 or 1, got %d", len(args))
+            }
+            code := int64(0)
+            if len(args) == 1 {
+                integer, ok := args[0].(*object.Integer)
+                if !ok {
+                    return makeBuiltinError("expected argument to be of type int")
+                }
+                code = integer.Value
+            }
+            EXIT(int(code))
+            return NULL
+        },
+    },
+}
@@ -0,0 +1,56 @@
+package eval
+
+import (
+    "os"
+    "language/ast"
+    "language/object"
+)
+
+// CHECKTYPES enables checking the annotated types of arguments and return values when functions are called
+var CHECKTYPES = os.Getenv("FMLCHECKTYPES") != ""
+
+func hasType(annotation *ast.TypeAnnotation, value object.Object) bool {
+    for _, name := range annotation.Names {
+        if hasPatternType(name, value) {
+            return true
+        }
+    }
+    return false
+}
+
+// checkArgumentTypes compares the parameters bound in env with their annotated types
+func checkArgumentTypes(function *object.Function, env *object.Environment) *object.Error {
+    for _, p := range function.Parameters {
+        if p.Type == nil || p.Pattern != nil {
+            continue
+        }
+        value, _ := env.Get(p.Name)
+        if !p.Variadic {
+            if !hasType(p.Type, value) {
+                return makeErrorWithEmptyStacktrace("argument %s in call of %s must be %s, got %s", p.Name, function.Signature(), p.Type, value.Type())
+            }
+            continue
+        }
+        for i, element := range value.(*object.Array).Elements {
+            if !hasType(p.Type, element) {
+                return makeErrorWithEmptyStacktrace("argument %s[%d] in call of %s must be %s, got %s", p.Name, i, function.Signature(), p.Type, element.Type())
+            }
+        }
+    }
+    return nil
+}
+
+// checkReturnType compares the result of a call with the annotated return type, a tail call is checked
+// by applyFunction when the last function called returns
+func checkReturnType(function *object.Function, result object.Object) *object.Error {
+    if function.ReturnType == nil || isError(result) {
+        return nil
+    }
+    if _, ok := result.(*object.TailCall); ok {
+        return nil
+    }
+    if !hasType(function.ReturnType, result) {
+        return makeErrorWithEmptyStacktrace("%s must return %s, got %s", function.Signature(), function.ReturnType, result.Type())
+    }
+    return nil
+}
@@ -0,0 +1,93 @@
+package format
+
+import (
+    "strings"
+)
+
+const INDENT = "    "
+
+// lineState is what the end of a line leaves open for the next one
+type lineState struct {
+    depth int
+    inString bool
+    commentDepth int
+}
+
+// Format indents every line by the brackets opened before it and removes trailing whitespace,
+// lines continuing a string or a multiline comment are kept as they are
+func Format(code string) string {
+    lines := strings.Split(strings.Replace(code, "\r\n", "\n", -1), "\n")
+    result := []string{}
+    state := lineState{}
+    for _, line := range lines {
+        verbatim := state.inString || state.commentDepth > 0
+        next, leadingClosers := scanLine(line, state)
+        if verbatim {
+            result = append(result, line)
+        } else {
+            trimmed := strings.TrimSpace(line)
+            if next.inString {
+                // whitespace at the end of an unterminated string belongs to the string
+                trimmed = strings.TrimLeft(line, " \t")
+            }
+            depth := state.depth - leadingClosers
+            if depth < 0 || trimmed == "" {
+                depth = 0
+            }
+            result = append(result, strings.Repeat(INDENT, depth) + trimmed)
+        }
+        state = next
+    }
+    for len(result) > 0 && result[len(result) - 1] == "" {
+        result = result[:len(result) - 1]
+    }
+    return strings.Join(result, "\n") + "\n"
+}
+
+// scanLine returns the state after line and the number of closing brackets it starts with
+func scanLine(line string, state lineState) (lineState, int) {
+    runes := []rune(line)
+    leadingClosers := 0
+    leading := !state.inString && state.commentDepth == 0
+    for i := 0; i < len(runes); i++ {
+        r := runes[i]
+        next := rune(0)
+        if i + 1 < len(runes) {
+            next = runes[i + 1]
+        }
+        switch {
+        case state.inString:
+            if r == '\\' {
+                i++
+            } else if r == '"' {
+                state.inString = false
+            }
+        case state.commentDepth > 0:
+            if r == '/' && next == '*' {
+                state.commentDepth++
+                i++
+            } else if r == '*' && next == '/' {
+                state.commentDepth--
+                i++
+            }
+        case r == '/' && next == '/':
+            return state, leadingClosers
+        case r == '/' && next == '*':
+            state.commentDepth++
+            i++
+        case r == '"':
+            state.inString = true
+        case r == '{' || r == '[' || r == '(':
+            state.depth++
+        case r == '}' || r == ']' || r == ')':
+            state.depth--
+            if leading {
+                leadingClosers++
+            }
+        }
+        if r != ' ' && r != '\t' && r != '}' && r != ']' && r != ')' {
+            leading = false
+        }
+    }
+    return state, leadingClosers
+}
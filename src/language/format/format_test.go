@@ -0,0 +1,35 @@
+package format
+
+import (
+    "testing"
+)
+
+func TestFormat(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"let a = 1;  ", "let a = 1;\n"},
+        {"fun f() {\nreturn 1;\n}\n\n\n", "fun f() {\n    return 1;\n}\n"},
+        {"if a {\n  b(\n1,\n  2\n);\n} else {\nc;\n}", "if a {\n    b(\n        1,\n        2\n    );\n} else {\n    c;\n}\n"},
+        {"let a = [\n1, [2,\n3]];", "let a = [\n    1, [2,\n        3]];\n"},
+        {"let h = #{\n\"a\": 1\n};", "let h = #{\n    \"a\": 1\n};\n"},
+        {"{\n// a comment with {\n  x;\n}", "{\n    // a comment with {\n    x;\n}\n"},
+        {"{\n/* a {\n  kept as it is\n*/\nx;\n}", "{\n    /* a {\n  kept as it is\n*/\n    x;\n}\n"},
+        {"/* nested /* comments */\n  still a comment */\n  x;", "/* nested /* comments */\n  still a comment */\nx;\n"},
+        {"let s = \"a {  \n  kept\";\n  x;", "let s = \"a {  \n  kept\";\nx;\n"},
+        {"let s = \"\\\"{\";\n  x;", "let s = \"\\\"{\";\nx;\n"},
+        {"}\n  x;", "}\nx;\n"},
+        {"", "\n"},
+    }
+
+    for _, tt := range tests {
+        formatted := Format(tt.input)
+        if formatted != tt.expected {
+            t.Fatalf("expected %q to be formatted as %q but got %q", tt.input, tt.expected, formatted)
+        }
+        if Format(formatted) != formatted {
+            t.Fatalf("expected formatting %q to change nothing", formatted)
+        }
+    }
+}
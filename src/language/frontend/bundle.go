@@ -0,0 +1,39 @@
+package frontend
+
+import (
+    "path/filepath"
+)
+
+// Bundle holds the sources of a program and all of its imports, so that it runs without the files
+type Bundle struct {
+    Main string
+    Sources map[string]string
+    // Imports maps the directory of an importing module and an import path to the imported module
+    Imports map[string]string
+}
+
+// BUNDLE is used instead of the file system to load modules if it is set
+var BUNDLE *Bundle
+
+func NewBundle(main string) *Bundle {
+    return &Bundle{Main: main, Sources: map[string]string{}, Imports: map[string]string{}}
+}
+
+func importKey(dir string, path string) string {
+    return dir + "\x00" + path
+}
+
+func (b *Bundle) AddImport(dir string, path string, resolved string) {
+    b.Imports[importKey(dir, path)] = resolved
+}
+
+// Resolve returns the module imported by path in dir
+func (b *Bundle) Resolve(dir string, path string) (string, bool) {
+    if resolved, ok := b.Imports[importKey(dir, path)]; ok {
+        return resolved, true
+    }
+    if _, ok := b.Sources[path]; ok && filepath.IsAbs(path) {
+        return path, true
+    }
+    return "", false
+}
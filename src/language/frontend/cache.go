@@ -0,0 +1,168 @@
+package frontend
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/gob"
+    "encoding/hex"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "language/ast"
+)
+
+var (
+    // CACHE enables storing parsed modules in CACHEDIR, set FMLNOCACHE to disable it
+    CACHE = os.Getenv("FMLNOCACHE") == ""
+    // CACHEDIR defaults to fml in the user cache directory, it can be set with FMLCACHE
+    CACHEDIR = os.Getenv("FMLCACHE")
+)
+
+// PARSER_VERSION has to be changed whenever the parser builds a different AST from the same source,
+// changes of the layout of the nodes are found by SCHEMA
+const PARSER_VERSION = "1"
+
+// cacheEntry is the parsed module stored for a path, it is only valid for the same source, parser version and AST schema
+type cacheEntry struct {
+    Version string
+    Schema string
+    Hash string
+    Program *ast.Program
+}
+
+// NODES are the types of the AST stored behind the Statement, Expression and Pattern interfaces, gob has to know them
+var NODES = []interface{}{
+    &ast.IntegerLiteralExpression{}, &ast.BigIntLiteralExpression{}, &ast.DecimalLiteralExpression{},
+    &ast.FloatLiteralExpression{}, &ast.StringLiteralExpression{}, &ast.BoolLiteralExpression{},
+    &ast.IdentifierExpression{}, &ast.NullLiteralExpression{}, &ast.UnaryExpression{},
+    &ast.InfixExpression{}, &ast.ConditionalExpression{}, &ast.FunctionLiteralExpression{},
+    &ast.CallExpression{}, &ast.SpreadExpression{}, &ast.NamedArgumentExpression{},
+    &ast.ArrayLiteral{}, &ast.AssignExpression{}, &ast.IndexExpression{}, &ast.HashLiteral{},
+    &ast.RangeExpression{}, &ast.MatchExpression{},
+    &ast.WildcardPattern{}, &ast.IdentifierPattern{}, &ast.LiteralPattern{}, &ast.RangePattern{},
+    &ast.TypePattern{}, &ast.ArrayPattern{}, &ast.HashPattern{},
+    &ast.ExpressionStatement{}, &ast.IfStatement{}, &ast.TryCatchStatement{}, &ast.LetStatement{},
+    &ast.ConstStatement{}, &ast.BlockStatement{}, &ast.ReturnStatement{}, &ast.YieldStatement{},
+    &ast.BreakStatement{}, &ast.ContinueStatement{}, &ast.WhileStatement{}, &ast.RangeLoopStatement{},
+    &ast.KVRangeLoopStatement{}, &ast.ImportStatement{}, &ast.ImportGroupStatement{},
+    &ast.ExportStatement{}, &ast.FunctionDeclarationStatement{},
+}
+
+// SCHEMA identifies the layout of the cached AST, it is derived from the fields of the cache entry and the node types,
+// so cached modules are not used after the AST changed
+var SCHEMA = schemaHash(append([]interface{}{&cacheEntry{}}, NODES...))
+
+func init() {
+    for _, node := range NODES {
+        gob.Register(node)
+    }
+}
+
+func schemaHash(nodes []interface{}) string {
+    var out strings.Builder
+    seen := map[reflect.Type]bool{}
+    for _, node := range nodes {
+        describeType(&out, reflect.TypeOf(node), seen)
+    }
+    hash := sha256.Sum256([]byte(out.String()))
+    return hex.EncodeToString(hash[:])
+}
+
+// describeType writes the fields of the struct types t refers to, each type is described once
+func describeType(out *strings.Builder, t reflect.Type, seen map[reflect.Type]bool) {
+    switch t.Kind() {
+    case reflect.Ptr, reflect.Slice, reflect.Array:
+        describeType(out, t.Elem(), seen)
+    case reflect.Map:
+        describeType(out, t.Key(), seen)
+        describeType(out, t.Elem(), seen)
+    case reflect.Struct:
+        if seen[t] {
+            return
+        }
+        seen[t] = true
+        out.WriteString(t.String() + " {")
+        for i := 0; i < t.NumField(); i++ {
+            out.WriteString(" " + t.Field(i).Name + " " + t.Field(i).Type.String() + ";")
+        }
+        out.WriteString(" }\n")
+        for i := 0; i < t.NumField(); i++ {
+            describeType(out, t.Field(i).Type, seen)
+        }
+    }
+}
+
+func cacheDir() (string, bool) {
+    if CACHEDIR != "" {
+        return CACHEDIR, true
+    }
+    dir, err := os.UserCacheDir()
+    if err != nil {
+        return "", false
+    }
+    return filepath.Join(dir, "fml"), true
+}
+
+func cachePath(path string) (string, bool) {
+    dir, ok := cacheDir()
+    if !ok {
+        return "", false
+    }
+    key := sha256.Sum256([]byte(path))
+    return filepath.Join(dir, hex.EncodeToString(key[:]) + ".gob"), true
+}
+
+func sourceHash(code string) string {
+    hash := sha256.Sum256([]byte(code))
+    return hex.EncodeToString(hash[:])
+}
+
+// loadCached returns the cached module of path if it was parsed from code by the same parser version with the same AST schema
+func loadCached(path string, code string) (*ast.Program, bool) {
+    cacheFile, ok := cachePath(path)
+    if !ok {
+        return nil, false
+    }
+    content, err := ioutil.ReadFile(cacheFile)
+    if err != nil {
+        return nil, false
+    }
+    entry := cacheEntry{}
+    if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&entry); err != nil {
+        return nil, false
+    }
+    if entry.Version != PARSER_VERSION || entry.Schema != SCHEMA || entry.Hash != sourceHash(code) || entry.Program == nil {
+        return nil, false
+    }
+    return entry.Program, true
+}
+
+// storeCached writes the module, failures are ignored as the module can always be parsed again
+func storeCached(path string, code string, program *ast.Program) {
+    cacheFile, ok := cachePath(path)
+    if !ok {
+        return
+    }
+    var content bytes.Buffer
+    entry := cacheEntry{Version: PARSER_VERSION, Schema: SCHEMA, Hash: sourceHash(code), Program: program}
+    if err := gob.NewEncoder(&content).Encode(&entry); err != nil {
+        return
+    }
+    if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+        return
+    }
+    // write to a temporary file first, so that concurrent runs never read a partial entry
+    tmp, err := ioutil.TempFile(filepath.Dir(cacheFile), "module")
+    if err != nil {
+        return
+    }
+    _, err = tmp.Write(content.Bytes())
+    if closeErr := tmp.Close(); err == nil {
+        err = closeErr
+    }
+    if err != nil || os.Rename(tmp.Name(), cacheFile) != nil {
+        os.Remove(tmp.Name())
+    }
+}
@@ -1,6 +1,8 @@
 package frontend
 
 import (
+    "fmt"
+    "os"
     "io/ioutil"
     "path/filepath"
     "language/scanner"
@@ -17,10 +19,45 @@ func Build(path string) (*ast.Program, []error) {
     if err != nil {
         return nil, []error{err}
     }
-    return parse(code, path)
+    if CACHE {
+        if program, ok := loadCached(path, code); ok {
+            printWarnings(program.Warnings)
+            return program, nil
+        }
+    }
+    program, errs := parse(code, path)
+    if program != nil {
+        printWarnings(program.Warnings)
+    }
+    if CACHE && len(errs) == 0 {
+        storeCached(path, code, program)
+    }
+    return program, errs
+}
+
+// BuildCode parses code given on the command line, path is only used for positions
+func BuildCode(code string, path string) (*ast.Program, []error) {
+    program, errs := parse(code, path)
+    if program != nil {
+        printWarnings(program.Warnings)
+    }
+    return program, errs
+}
+
+func printWarnings(warnings []string) {
+    for _, warning := range warnings {
+        fmt.Fprintf(os.Stderr, "\t%s\n", warning)
+    }
 }
 
 func readFile(path string) (string, error) {
+    if BUNDLE != nil {
+        code, ok := BUNDLE.Sources[path]
+        if !ok {
+            return "", fmt.Errorf("%s is not part of the bundle", path)
+        }
+        return code, nil
+    }
     content, err := ioutil.ReadFile(path)
     if err != nil {
         return "", err
@@ -0,0 +1,199 @@
+package frontend
+
+import (
+    "bytes"
+    "encoding/gob"
+    "go/ast"
+    "go/parser"
+    "go/token"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+)
+
+func withCache(t *testing.T) (string, func()) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    oldCache, oldDir := CACHE, CACHEDIR
+    CACHE, CACHEDIR = true, filepath.Join(dir, "cache")
+    return dir, func() {
+        CACHE, CACHEDIR = oldCache, oldDir
+        os.RemoveAll(dir)
+    }
+}
+
+func writeModule(t *testing.T, path string, code string) {
+    if err := ioutil.WriteFile(path, []byte(code), 0644); err != nil {
+        t.Fatalf("could not write module: %s", err)
+    }
+}
+
+func TestCachedBuild(t *testing.T) {
+    dir, cleanup := withCache(t)
+    defer cleanup()
+    path := filepath.Join(dir, "module.fml")
+    writeModule(t, path, "let a = {\"x\": [1, 2n, 3.5d]};")
+
+    program, errs := Build(path)
+    if len(errs) > 0 {
+        t.Fatalf("unexpected errors: %v", errs)
+    }
+    cacheFile, _ := cachePath(path)
+    if _, err := os.Stat(cacheFile); err != nil {
+        t.Fatalf("expected the module to be cached: %s", err)
+    }
+    cached, ok := loadCached(path, "let a = {\"x\": [1, 2n, 3.5d]};")
+    if !ok || cached.String() != program.String() {
+        t.Fatalf("expected cached program %s", program.String())
+    }
+
+    // a changed file invalidates the entry
+    writeModule(t, path, "let b = 2;")
+    program, errs = Build(path)
+    if len(errs) > 0 || program.String() != "let b = 2;" {
+        t.Fatalf("expected the changed module but got %s %v", program.String(), errs)
+    }
+
+    // entries of other parser versions or schemas and broken entries are ignored
+    stale := []cacheEntry{
+        {Version: "old", Schema: SCHEMA, Hash: sourceHash("let b = 2;"), Program: program},
+        {Version: PARSER_VERSION, Schema: "old", Hash: sourceHash("let b = 2;"), Program: program},
+    }
+    for _, entry := range stale {
+        var content bytes.Buffer
+        if err := gob.NewEncoder(&content).Encode(&entry); err != nil {
+            t.Fatalf("could not encode entry: %s", err)
+        }
+        writeModule(t, cacheFile, content.String())
+        if _, ok := loadCached(path, "let b = 2;"); ok {
+            t.Fatalf("expected an entry of version %s and schema %s to be ignored", entry.Version, entry.Schema)
+        }
+    }
+    writeModule(t, cacheFile, "broken")
+    if _, ok := loadCached(path, "let b = 2;"); ok {
+        t.Fatalf("expected a broken entry to be ignored")
+    }
+    program, errs = Build(path)
+    if len(errs) > 0 || program.String() != "let b = 2;" {
+        t.Fatalf("expected the module to be parsed again but got %s %v", program.String(), errs)
+    }
+
+    // modules with errors are not cached
+    writeModule(t, path, "let = 3;")
+    os.Remove(cacheFile)
+    if _, errs := Build(path); len(errs) == 0 {
+        t.Fatalf("expected parser errors")
+    }
+    if _, err := os.Stat(cacheFile); err == nil {
+        t.Fatalf("expected a module with errors not to be cached")
+    }
+}
+
+func TestDisabledCache(t *testing.T) {
+    dir, cleanup := withCache(t)
+    defer cleanup()
+    CACHE = false
+    path := filepath.Join(dir, "module.fml")
+    writeModule(t, path, "let a = 1;")
+
+    if _, errs := Build(path); len(errs) > 0 {
+        t.Fatalf("unexpected errors: %v", errs)
+    }
+    if _, err := os.Stat(CACHEDIR); err == nil {
+        t.Fatalf("expected no cache directory")
+    }
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+    _, cleanup := withCache(t)
+    defer cleanup()
+    paths, _ := filepath.Glob(filepath.Join("..", "examples", "*.fml"))
+    more, _ := filepath.Glob(filepath.Join("..", "corelibrary", "*.fml"))
+    paths = append(paths, more...)
+    if len(paths) == 0 {
+        t.Fatalf("expected example modules")
+    }
+
+    for _, path := range paths {
+        program, errs := Build(path)
+        if len(errs) > 0 {
+            t.Fatalf("unexpected errors in %s: %v", path, errs)
+        }
+        absPath, _ := filepath.Abs(path)
+        code, _ := readFile(absPath)
+        cached, ok := loadCached(absPath, code)
+        if !ok {
+            t.Fatalf("expected %s to be cached", path)
+        }
+        if cached.String() != program.String() {
+            t.Fatalf("expected cached %s to be\n%s\nbut got\n%s", path, program.String(), cached.String())
+        }
+    }
+}
+
+// TestRegisteredNodes fails if a node type of the ast package is missing in NODES, gob could not cache modules using it
+func TestRegisteredNodes(t *testing.T) {
+    packages, err := parser.ParseDir(token.NewFileSet(), filepath.Join("..", "ast"), nil, 0)
+    if err != nil {
+        t.Fatalf("could not parse the ast package: %s", err)
+    }
+    nodes := []string{}
+    for _, pkg := range packages {
+        for _, file := range pkg.Files {
+            for _, decl := range file.Decls {
+                function, ok := decl.(*ast.FuncDecl)
+                if !ok || function.Recv == nil || !strings.HasSuffix(function.Name.Name, "Node") {
+                    continue
+                }
+                receiver := function.Recv.List[0].Type
+                if star, ok := receiver.(*ast.StarExpr); ok {
+                    receiver = star.X
+                }
+                nodes = append(nodes, receiver.(*ast.Ident).Name)
+            }
+        }
+    }
+    registered := []string{}
+    for _, node := range NODES {
+        registered = append(registered, reflect.TypeOf(node).Elem().Name())
+    }
+    sort.Strings(nodes)
+    sort.Strings(registered)
+    if !reflect.DeepEqual(nodes, registered) {
+        t.Fatalf("expected the registered nodes to be\n%v\nbut got\n%v", nodes, registered)
+    }
+}
+
+func TestSchemaHash(t *testing.T) {
+    type node struct {
+        Value int
+    }
+    type changedNode struct {
+        Value string
+    }
+    type parent struct {
+        Child *node
+    }
+    type changedParent struct {
+        Child *changedNode
+    }
+
+    if schemaHash([]interface{}{&node{}}) != schemaHash([]interface{}{&node{}}) {
+        t.Fatalf("expected the same schema for the same types")
+    }
+    if schemaHash([]interface{}{&node{}}) == schemaHash([]interface{}{&changedNode{}}) {
+        t.Fatalf("expected a changed field to change the schema")
+    }
+    if schemaHash([]interface{}{&parent{}}) == schemaHash([]interface{}{&changedParent{}}) {
+        t.Fatalf("expected a changed nested type to change the schema")
+    }
+    if schemaHash([]interface{}{&node{}}) == schemaHash([]interface{}{&node{}, &parent{}}) {
+        t.Fatalf("expected a registered type to change the schema")
+    }
+}
@@ -1,19 +1,266 @@
 package main
 
 import (
-    "os"
+    "errors"
+    "flag"
     "fmt"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "language/eval"
+    "language/frontend"
     "language/repl"
-    "language/run"
 )
 
+// command is a subcommand of the interpreter, the global flags are accepted by every command
+type command struct {
+    usage string
+    description string
+    // setup registers the flags of the command and returns the function running it with the remaining arguments
+    setup func(flags *flag.FlagSet) func(args []string) int
+}
+
+// VERSION of the interpreter printed by fml version, the module cache has its own frontend.PARSER_VERSION and frontend.SCHEMA
+const VERSION = "0.1.0"
+
+var commands map[string]*command
+
+func init() {
+    commands = map[string]*command{
+        "run": runCommand,
+        "repl": &command{
+            usage: "repl",
+            description: "start the interactive interpreter, the default without arguments",
+            setup: func(flags *flag.FlagSet) func(args []string) int {
+                return func(args []string) int {
+                    if len(args) > 0 {
+                        return usageError(flags)
+                    }
+                    repl.Start(os.Stdin, os.Stdout)
+                    return 0
+                }
+            },
+        },
+        "test": testCommand,
+        "fmt": fmtCommand,
+        "check": checkCommand,
+        "doc": docCommand,
+        "build": buildCommand,
+        "mod": &command{
+            usage: "mod init [name] | mod vendor | mod verify",
+            description: "manage the fml.json manifest and the dependencies of the project in the working directory",
+            setup: func(flags *flag.FlagSet) func(args []string) int {
+                return func(args []string) int {
+                    return exitCode(mod(args))
+                }
+            },
+        },
+        "version": &command{
+            usage: "version",
+            description: "print the version of the interpreter",
+            setup: func(flags *flag.FlagSet) func(args []string) int {
+                return func(args []string) int {
+                    fmt.Printf("fml %s\n", VERSION)
+                    return 0
+                }
+            },
+        },
+        "help": &command{
+            usage: "help [command]",
+            description: "print the usage of the interpreter or of a command",
+            setup: func(flags *flag.FlagSet) func(args []string) int {
+                return func(args []string) int {
+                    if len(args) == 0 {
+                        printUsage()
+                        return 0
+                    }
+                    cmd, ok := commands[args[0]]
+                    if !ok {
+                        fmt.Fprintf(os.Stderr, "unknown command %s\n", args[0])
+                        return 2
+                    }
+                    commandFlags := newFlagSet(args[0], cmd)
+                    commandFlags.SetOutput(os.Stdout)
+                    // registering the flags again resets them to their defaults
+                    helpGlobals := globals
+                    addGlobalFlags(commandFlags)
+                    globals = helpGlobals
+                    cmd.setup(commandFlags)
+                    commandFlags.Usage()
+                    return 0
+                }
+            },
+        },
+    }
+}
+
+// globalFlags are set by every flag set of the commands
+type globalFlags struct {
+    fmlpath string
+    trace bool
+    backend string
+    noCache bool
+    checkTypes bool
+    readOnlyModules bool
+}
+
+var globals = globalFlags{}
+
+func addGlobalFlags(flags *flag.FlagSet) {
+    flags.StringVar(&globals.fmlpath, "fmlpath", "", "directory of the core library, overrides FMLPATH")
+    flags.BoolVar(&globals.trace, "trace", false, "print every function call to stderr")
+    flags.StringVar(&globals.backend, "backend", "tree", "backend evaluating the program, only tree is available")
+    flags.BoolVar(&globals.noCache, "no-cache", false, "neither read nor write the module cache")
+    flags.BoolVar(&globals.checkTypes, "check-types", false, "check the annotated types of arguments and return values when functions are called")
+    flags.BoolVar(&globals.readOnlyModules, "readonly-modules", false, "forbid importers to assign to the variables of a module, like FMLREADONLYMODULES")
+}
+
+// applyGlobalFlags configures the interpreter, it returns false if a flag is invalid
+func applyGlobalFlags() bool {
+    if globals.backend != "tree" {
+        fmt.Fprintf(os.Stderr, "unknown backend %s, available backends: tree\n", globals.backend)
+        return false
+    }
+    if globals.fmlpath != "" {
+        fmlpath, err := filepath.Abs(globals.fmlpath)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "invalid fmlpath %s: %s\n", globals.fmlpath, err)
+            return false
+        }
+        eval.FMLPATH = fmlpath
+    }
+    if globals.trace {
+        eval.TRACE = os.Stderr
+    }
+    if globals.noCache {
+        frontend.CACHE = false
+    }
+    if globals.checkTypes {
+        eval.CHECKTYPES = true
+    }
+    if globals.readOnlyModules {
+        eval.READONLYMODULES = true
+    }
+    return true
+}
+
+func newFlagSet(name string, cmd *command) *flag.FlagSet {
+    flags := flag.NewFlagSet(name, flag.ContinueOnError)
+    flags.Usage = func() {
+        fmt.Fprintf(flags.Output(), "usage: fml %s\n\n%s\n\nflags:\n", cmd.usage, cmd.description)
+        flags.PrintDefaults()
+    }
+    return flags
+}
+
 func main() {
-    cmdArgs := os.Args[1:]
-    if len(cmdArgs) == 0 {
-        repl.Start(os.Stdin, os.Stdout)
-    } else if len(cmdArgs) == 1 {
-        run.Run(cmdArgs[0])
-    } else {
-        fmt.Printf("You can only run this command with 0 or 1 arguments.\nIf you run it without arguments, you start the REPL\nIf you run it with one argument, it gets interpreted as a filepath and the file gets evalauted")
+    if runBundle() {
+        return
+    }
+    os.Exit(dispatch(os.Args[1:]))
+}
+
+// dispatch runs the command named by args, arguments not starting with a command are run as a program
+func dispatch(args []string) int {
+    // global flags may precede the command, anything else is left to the run command
+    leading := flag.NewFlagSet("fml", flag.ContinueOnError)
+    leading.SetOutput(ioutil.Discard)
+    addGlobalFlags(leading)
+    err := leading.Parse(args)
+    if err == flag.ErrHelp {
+        printUsage()
+        return 0
+    }
+    name, rest := "run", args
+    if err == nil {
+        rest = leading.Args()
+        if len(rest) == 0 {
+            name = "repl"
+        } else if _, ok := commands[rest[0]]; ok {
+            name, rest = rest[0], rest[1:]
+        }
+    }
+
+    // registering the flags again resets them to their defaults
+    leadingGlobals := globals
+    cmd := commands[name]
+    flags := newFlagSet(name, cmd)
+    addGlobalFlags(flags)
+    globals = leadingGlobals
+    run := cmd.setup(flags)
+    if err := flags.Parse(rest); err != nil {
+        if err == flag.ErrHelp {
+            return 0
+        }
+        return 2
+    }
+    if !applyGlobalFlags() {
+        return 2
+    }
+    return run(flags.Args())
+}
+
+func printUsage() {
+    names := []string{}
+    for name := range commands {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    lines := []string{"usage: fml [flags] <command> [arguments]", "       fml [flags] file.fml [arguments]", "", "commands:"}
+    for _, name := range names {
+        lines = append(lines, fmt.Sprintf("    %-8s %s", name, commands[name].description))
+    }
+    lines = append(lines, "", "Run fml help <command> for the usage of a command.", "", "flags:")
+    fmt.Println(strings.Join(lines, "\n"))
+    flags := flag.NewFlagSet("fml", flag.ContinueOnError)
+    flags.SetOutput(os.Stdout)
+    addGlobalFlags(flags)
+    flags.PrintDefaults()
+}
+
+var errInvalidFlag = errors.New("invalid flag")
+
+// parseInterspersed parses flags which may follow the positional arguments, global flags are applied again
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+    positional := []string{}
+    for {
+        if err := flags.Parse(args); err != nil {
+            return nil, err
+        }
+        args = flags.Args()
+        if len(args) == 0 {
+            if !applyGlobalFlags() {
+                return nil, errInvalidFlag
+            }
+            return positional, nil
+        }
+        positional = append(positional, args[0])
+        args = args[1:]
+    }
+}
+
+// flagSet reports if the flag name was given on the command line, even if its value is the default
+func flagSet(flags *flag.FlagSet, name string) bool {
+    set := false
+    flags.Visit(func(f *flag.Flag) {
+        if f.Name == name {
+            set = true
+        }
+    })
+    return set
+}
+
+func usageError(flags *flag.FlagSet) int {
+    flags.Usage()
+    return 2
+}
+
+func exitCode(err error) int {
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+        return 1
     }
+    return 0
 }
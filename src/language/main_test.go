@@ -0,0 +1,48 @@
+package main
+
+import (
+    "testing"
+    "language/eval"
+    "language/frontend"
+)
+
+func TestDispatch(t *testing.T) {
+    frontend.CACHE = false
+    defer func() {
+        eval.TRACE = nil
+        eval.FMLPATH = ""
+        eval.CHECKTYPES = false
+        eval.READONLYMODULES = false
+    }()
+    tests := []struct {
+        args []string
+        expected int
+    }{
+        {[]string{"version"}, 0},
+        {[]string{"help", "run"}, 0},
+        {[]string{"help", "unknown"}, 2},
+        {[]string{"-e", "let a = 1;"}, 0},
+        {[]string{"-e", ""}, 1},
+        {[]string{"--trace", "run", "-e", "let a = 1;"}, 0},
+        {[]string{"run", "-e", "1 / 0;"}, 1},
+        {[]string{"--check-types", "-e", "const f = fun(a: int) {}; f(1);"}, 0},
+        {[]string{"-e", "const f = fun(a: int) {}; f(1.5);", "--check-types"}, 1},
+        {[]string{"--readonly-modules", "-e", "1;"}, 0},
+        {[]string{"--backend", "vm", "-e", "1;"}, 2},
+        {[]string{"run", "--unknown"}, 2},
+        {[]string{"run"}, 2},
+        {[]string{"check"}, 2},
+        {[]string{"build"}, 2},
+        {[]string{"doc"}, 2},
+        {[]string{"doc", "-o", "/dev/null", "corelibrary"}, 0},
+        {[]string{"doc", "missing.fml"}, 1},
+        {[]string{"missing.fml"}, 1},
+    }
+
+    for _, tt := range tests {
+        globals = globalFlags{}
+        if code := dispatch(tt.args); code != tt.expected {
+            t.Fatalf("expected %v to exit with %d but got %d", tt.args, tt.expected, code)
+        }
+    }
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "language/project"
+)
+
+const MOD_USAGE = "usage: mod init [name] | mod vendor | mod verify"
+
+// mod manages the manifest of the project in the current directory
+func mod(args []string) error {
+    if len(args) == 0 {
+        return errors.New(MOD_USAGE)
+    }
+    cwd, err := os.Getwd()
+    if err != nil {
+        return err
+    }
+    switch args[0] {
+    case "init":
+        if len(args) > 2 {
+            return errors.New(MOD_USAGE)
+        }
+        if _, err := os.Stat(filepath.Join(cwd, project.MANIFEST_FILE)); err == nil {
+            return fmt.Errorf("%s already exists", project.MANIFEST_FILE)
+        }
+        name := filepath.Base(cwd)
+        if len(args) == 2 {
+            name = args[1]
+        }
+        if err := project.New(name, cwd).Save(); err != nil {
+            return err
+        }
+        fmt.Printf("created %s for %s\n", project.MANIFEST_FILE, name)
+    case "vendor", "verify":
+        if len(args) != 1 {
+            return errors.New(MOD_USAGE)
+        }
+        manifest, err := project.Find(cwd)
+        if err != nil {
+            return err
+        }
+        if manifest == nil {
+            return fmt.Errorf("no %s found, run mod init first", project.MANIFEST_FILE)
+        }
+        if args[0] == "verify" {
+            if err := manifest.Verify(); err != nil {
+                return err
+            }
+            fmt.Printf("all dependencies match %s\n", project.LOCK_FILE)
+            return nil
+        }
+        lock, err := manifest.Vendor()
+        if err != nil {
+            return err
+        }
+        fmt.Printf("vendored %d dependencies into %s\n", len(lock.Dependencies), filepath.Join(manifest.Root, project.VENDOR_DIR))
+    default:
+        return errors.New(MOD_USAGE)
+    }
+    return nil
+}
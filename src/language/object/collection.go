@@ -0,0 +1,255 @@
+package object
+
+import (
+    "bytes"
+    "strings"
+)
+
+const (
+    SET_OBJECT = "SET"
+    DEQUE_OBJECT = "DEQUE"
+)
+
+// Set keeps its elements in insertion order, order lists the keys of Elements in the order they were added,
+// so elements must only be added and removed with Add and Remove
+type Set struct {
+    Elements map[HashKey]Object
+    Frozen bool
+    order []setSlot
+    // positions are the indices of the keys in order
+    positions map[HashKey]int
+    removed int
+}
+
+// setSlot is a key in the order of a set, removed keys stay in place until the order is compacted
+type setSlot struct {
+    key HashKey
+    removed bool
+}
+
+func NewSet() *Set {
+    return &Set{Elements: make(map[HashKey]Object), positions: make(map[HashKey]int)}
+}
+
+func (s *Set) Type() ObjectType {
+    return SET_OBJECT
+}
+
+func (s *Set) String() string {
+    var out bytes.Buffer
+
+    elements := []string{}
+    for _, e := range s.Values() {
+        elements = append(elements, e.String())
+    }
+
+    out.WriteString("set(")
+    out.WriteString(strings.Join(elements, ", "))
+    out.WriteString(")")
+
+    return out.String()
+}
+
+// Values returns the elements in insertion order
+func (s *Set) Values() []Object {
+    values := make([]Object, 0, len(s.Elements))
+    for _, slot := range s.order {
+        if !slot.removed {
+            values = append(values, s.Elements[slot.key])
+        }
+    }
+    return values
+}
+
+func (s *Set) Add(element Object) bool {
+    key, ok := HashKeyOf(element)
+    if !ok {
+        return false
+    }
+    if _, ok := s.Elements[key]; !ok {
+        s.positions[key] = len(s.order)
+        s.order = append(s.order, setSlot{key: key})
+    }
+    s.Elements[key] = element
+    return true
+}
+
+// Remove marks the key of element as removed in the order, the order is compacted once half of it is removed
+func (s *Set) Remove(element Object) bool {
+    hashed, ok := HashKeyOf(element)
+    if !ok {
+        return false
+    }
+    if _, ok = s.Elements[hashed]; !ok {
+        return false
+    }
+    delete(s.Elements, hashed)
+    s.order[s.positions[hashed]].removed = true
+    delete(s.positions, hashed)
+    s.removed++
+    if s.removed > len(s.order) / 2 {
+        s.compact()
+    }
+    return true
+}
+
+func (s *Set) compact() {
+    order := make([]setSlot, 0, len(s.Elements))
+    for _, slot := range s.order {
+        if !slot.removed {
+            s.positions[slot.key] = len(order)
+            order = append(order, slot)
+        }
+    }
+    s.order = order
+    s.removed = 0
+}
+
+func (s *Set) Contains(element Object) bool {
+    key, ok := HashKeyOf(element)
+    if !ok {
+        return false
+    }
+    _, ok = s.Elements[key]
+    return ok
+}
+
+func (s *Set) Union(other *Set) *Set {
+    result := NewSet()
+    for _, v := range s.Values() {
+        result.Add(v)
+    }
+    for _, v := range other.Values() {
+        result.Add(v)
+    }
+    return result
+}
+
+func (s *Set) Intersection(other *Set) *Set {
+    result := NewSet()
+    for _, v := range s.Values() {
+        if other.Contains(v) {
+            result.Add(v)
+        }
+    }
+    return result
+}
+
+func (s *Set) Difference(other *Set) *Set {
+    result := NewSet()
+    for _, v := range s.Values() {
+        if !other.Contains(v) {
+            result.Add(v)
+        }
+    }
+    return result
+}
+
+
+// Deque is a growable ring buffer, so appending and removing at both ends is O(1) amortised
+type Deque struct {
+    buffer []Object
+    head int
+    size int
+    Frozen bool
+}
+
+func NewDeque() *Deque {
+    return &Deque{buffer: make([]Object, 8), head: 0, size: 0}
+}
+
+func (d *Deque) Type() ObjectType {
+    return DEQUE_OBJECT
+}
+
+func (d *Deque) String() string {
+    var out bytes.Buffer
+
+    elements := []string{}
+    for _, e := range d.Elements() {
+        elements = append(elements, e.String())
+    }
+
+    out.WriteString("deque(")
+    out.WriteString(strings.Join(elements, ", "))
+    out.WriteString(")")
+
+    return out.String()
+}
+
+func (d *Deque) Len() int {
+    return d.size
+}
+
+func (d *Deque) Get(idx int) (Object, bool) {
+    if idx < 0 || idx >= d.size {
+        return nil, false
+    }
+    return d.buffer[d.bufferIndex(idx)], true
+}
+
+func (d *Deque) Set(idx int, value Object) bool {
+    if idx < 0 || idx >= d.size {
+        return false
+    }
+    d.buffer[d.bufferIndex(idx)] = value
+    return true
+}
+
+func (d *Deque) PushBack(value Object) {
+    d.grow()
+    d.buffer[d.bufferIndex(d.size)] = value
+    d.size++
+}
+
+func (d *Deque) PushFront(value Object) {
+    d.grow()
+    d.head = (d.head - 1 + len(d.buffer)) % len(d.buffer)
+    d.buffer[d.head] = value
+    d.size++
+}
+
+func (d *Deque) PopBack() (Object, bool) {
+    if d.size == 0 {
+        return nil, false
+    }
+    idx := d.bufferIndex(d.size - 1)
+    value := d.buffer[idx]
+    d.buffer[idx] = nil
+    d.size--
+    return value, true
+}
+
+func (d *Deque) PopFront() (Object, bool) {
+    if d.size == 0 {
+        return nil, false
+    }
+    value := d.buffer[d.head]
+    d.buffer[d.head] = nil
+    d.head = (d.head + 1) % len(d.buffer)
+    d.size--
+    return value, true
+}
+
+// Elements returns a copy of the elements from front to back
+func (d *Deque) Elements() []Object {
+    result := make([]Object, d.size)
+    for i := range result {
+        result[i] = d.buffer[d.bufferIndex(i)]
+    }
+    return result
+}
+
+func (d *Deque) bufferIndex(idx int) int {
+    return (d.head + idx) % len(d.buffer)
+}
+
+func (d *Deque) grow() {
+    if d.size < len(d.buffer) {
+        return
+    }
+    newBuffer := make([]Object, len(d.buffer) * 2)
+    copy(newBuffer, d.Elements())
+    d.buffer = newBuffer
+    d.head = 0
+}
@@ -1,9 +1,14 @@
 package object
 
+import (
+    "sort"
+)
+
 type Environment struct {
     store map[string]Object
     constNames map[string]bool
     outer *Environment
+    generator *Coroutine
 }
 
 func NewEnvironment() *Environment {
@@ -17,10 +22,17 @@ func NewEnclosingEnvironment(outer *Environment) *Environment {
     return env
 }
 
+// Get returns the value of name, a name imported circularly is returned as ImportBinding until its module defines it
 func (e *Environment) Get(name string) (Object, bool) {
     value, ok := e.store[name]
     if !ok && e.outer != nil {
-        value, ok = e.outer.Get(name)
+        return e.outer.Get(name)
+    }
+    if binding, isBinding := value.(*ImportBinding); isBinding {
+        if resolved, found := binding.Resolve(); found {
+            e.store[name] = resolved
+            return resolved, true
+        }
     }
     return value, ok
 }
@@ -59,6 +71,16 @@ func (e *Environment) AddConst(name string, value Object) bool {
     return true
 }
 
+// Names returns the sorted names defined in e, without the ones of enclosing environments
+func (e *Environment) Names() []string {
+    names := make([]string, 0, len(e.store))
+    for name := range e.store {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
 func (e *Environment) hasEntry(name string) bool {
     _, has := e.store[name]
     return has
@@ -68,3 +90,18 @@ func (e *Environment) isConst(name string) bool {
     isConst, ok := e.constNames[name]
     return ok && isConst
 }
+
+func (e *Environment) SetGenerator(generator *Coroutine) {
+    e.generator = generator
+}
+
+// Generator returns the generator of the innermost enclosing generator function
+func (e *Environment) Generator() (*Coroutine, bool) {
+    if e.generator != nil {
+        return e.generator, true
+    }
+    if e.outer != nil {
+        return e.outer.Generator()
+    }
+    return nil, false
+}
@@ -0,0 +1,120 @@
+package object
+
+import (
+    "encoding/binary"
+    "hash"
+    "hash/fnv"
+    "sort"
+)
+
+// Freeze makes arrays, hashes, sets and deques and everything they contain immutable, other objects are left as they are
+func Freeze(obj Object) {
+    switch obj := obj.(type) {
+    case *Array:
+        if obj.Frozen {
+            return
+        }
+        obj.Frozen = true
+        for _, e := range obj.Elements {
+            Freeze(e)
+        }
+    case *Hash:
+        if obj.Frozen {
+            return
+        }
+        obj.Frozen = true
+        for _, pair := range obj.Pairs {
+            Freeze(pair.Value)
+        }
+    case *Set:
+        // the elements of sets are hashable, so they are immutable already
+        obj.Frozen = true
+    case *Deque:
+        if obj.Frozen {
+            return
+        }
+        obj.Frozen = true
+        for _, e := range obj.Elements() {
+            Freeze(e)
+        }
+    }
+}
+
+// HashKeyOf returns the hash key of obj, frozen arrays and hashes are hashable if all their elements are
+func HashKeyOf(obj Object) (HashKey, bool) {
+    return hashKeyOf(obj, map[Object]bool{})
+}
+
+func hashKeyOf(obj Object, visiting map[Object]bool) (HashKey, bool) {
+    switch obj := obj.(type) {
+    case Hashable:
+        return obj.HashKey(), true
+    case *Array:
+        if !obj.Frozen {
+            return HashKey{}, false
+        }
+        if visiting[obj] {
+            return HashKey{Type: obj.Type()}, true
+        }
+        visiting[obj] = true
+        defer delete(visiting, obj)
+
+        h := fnv.New64a()
+        for _, e := range obj.Elements {
+            key, ok := hashKeyOf(e, visiting)
+            if !ok {
+                return HashKey{}, false
+            }
+            writeHashKey(h, key)
+        }
+        return HashKey{Type: obj.Type(), Value: h.Sum64()}, true
+    case *Hash:
+        if !obj.Frozen {
+            return HashKey{}, false
+        }
+        if visiting[obj] {
+            return HashKey{Type: obj.Type()}, true
+        }
+        visiting[obj] = true
+        defer delete(visiting, obj)
+
+        // the pairs are sorted by their key, so the hash key does not depend on the order of the map
+        pairs := make([]HashKey, 0, len(obj.Pairs))
+        for key, pair := range obj.Pairs {
+            value, ok := hashKeyOf(pair.Value, visiting)
+            if !ok {
+                return HashKey{}, false
+            }
+            pairs = append(pairs, key, value)
+        }
+        order := make([]int, len(pairs) / 2)
+        for i := range order {
+            order[i] = i * 2
+        }
+        sort.Slice(order, func(i, j int) bool {
+            return lessHashKey(pairs[order[i]], pairs[order[j]])
+        })
+
+        h := fnv.New64a()
+        for _, i := range order {
+            writeHashKey(h, pairs[i])
+            writeHashKey(h, pairs[i + 1])
+        }
+        return HashKey{Type: obj.Type(), Value: h.Sum64()}, true
+    }
+    return HashKey{}, false
+}
+
+func writeHashKey(h hash.Hash64, key HashKey) {
+    h.Write([]byte(key.Type))
+    var value [8]byte
+    binary.LittleEndian.PutUint64(value[:], key.Value)
+    h.Write(value[:])
+}
+
+func lessHashKey(lhs HashKey, rhs HashKey) bool {
+    if lhs.Type != rhs.Type {
+        return lhs.Type < rhs.Type
+    }
+    return lhs.Value < rhs.Value
+}
@@ -0,0 +1,122 @@
+package object
+
+import (
+    "runtime"
+    "sync"
+    "language/ast"
+)
+
+// Generator runs a function body as a coroutine, the body hands values to the consumer with Yield.
+// The body runs in its own goroutine, but only ever while the consumer waits for the next value,
+// so the body and the consumer never run at the same time.
+// The goroutine only references the Coroutine, so a generator dropped by the consumer is closed when it is collected.
+type Generator struct {
+    *Coroutine
+    // CreatedBy is the position of the call of the generator function
+    CreatedBy ast.PositionalInfo
+}
+
+// Coroutine is the state of a generator shared with the goroutine running its body
+type Coroutine struct {
+    run func(c *Coroutine) Object
+    values chan Object
+    resume chan struct{}
+    done chan struct{}
+    closeOnce sync.Once
+    started bool
+    finished bool
+    buffered Object
+}
+
+func NewGenerator(run func(c *Coroutine) Object, createdBy ast.PositionalInfo) *Generator {
+    g := &Generator{Coroutine: &Coroutine{run: run, values: make(chan Object), resume: make(chan struct{}), done: make(chan struct{})}, CreatedBy: createdBy}
+    runtime.SetFinalizer(g, func(g *Generator) {
+        g.Close()
+    })
+    return g
+}
+
+func (g *Generator) Type() ObjectType {
+    return ITERATOR_OBJECT
+}
+
+func (g *Generator) String() string {
+    return "generator"
+}
+
+func (g *Generator) Iterator() Iterator {
+    return g
+}
+
+// Yield is called by the generator body and suspends it until the consumer asks for the next value,
+// the goroutine of the body ends if the generator is closed meanwhile
+func (c *Coroutine) Yield(value Object) {
+    select {
+    case c.values <- value:
+    case <-c.done:
+        runtime.Goexit()
+    }
+    select {
+    case <-c.resume:
+    case <-c.done:
+        runtime.Goexit()
+    }
+}
+
+// Close ends a suspended body, the generator has no more values afterwards
+func (c *Coroutine) Close() {
+    c.closeOnce.Do(func() {
+        close(c.done)
+    })
+    c.finished = true
+    c.buffered = nil
+}
+
+func (c *Coroutine) HasNext() bool {
+    if c.buffered != nil {
+        return true
+    }
+    if c.finished {
+        return false
+    }
+    if !c.started {
+        c.started = true
+        go c.execute()
+    } else {
+        c.resume <- struct{}{}
+    }
+    value, ok := <-c.values
+    if !ok {
+        c.finished = true
+        return false
+    }
+    c.buffered = value
+    return true
+}
+
+func (c *Coroutine) Next() Object {
+    if !c.HasNext() {
+        return nil
+    }
+    value := c.buffered
+    c.buffered = nil
+    return value
+}
+
+func (c *Coroutine) execute() {
+    result := c.run(c)
+    if result != nil && (result.Type() == ERROR_OBJECT || result.Type() == PARSER_ERRORS_OBJECT) {
+        // errors of the body are handed to the consumer as the last value
+        select {
+        case c.values <- result:
+        case <-c.done:
+            return
+        }
+        select {
+        case <-c.resume:
+        case <-c.done:
+            return
+        }
+    }
+    close(c.values)
+}
@@ -0,0 +1,196 @@
+package object
+
+import (
+    "bytes"
+    "fmt"
+)
+
+const (
+    RANGE_OBJECT = "RANGE"
+    ITERATOR_OBJECT = "ITERATOR"
+)
+
+// Iterator is the protocol used by range loops and collection builtins.
+// Errors raised while producing an element are returned by Next as *Error.
+type Iterator interface {
+    Object
+    HasNext() bool
+    Next() Object
+}
+
+type Iterable interface {
+    Iterator() Iterator
+}
+
+
+type Range struct {
+    Start int64
+    End int64
+    Step int64
+    Inclusive bool
+}
+
+// NewRange creates a range counting from start towards end, the step defaults to 1 or -1 depending on the direction
+func NewRange(start, end int64, inclusive bool) *Range {
+    step := int64(1)
+    if start > end {
+        step = -1
+    }
+    return &Range{Start: start, End: end, Step: step, Inclusive: inclusive}
+}
+
+func (r *Range) Type() ObjectType {
+    return RANGE_OBJECT
+}
+
+func (r *Range) String() string {
+    var out bytes.Buffer
+
+    out.WriteString(fmt.Sprintf("%d", r.Start))
+    if r.Inclusive {
+        out.WriteString("..=")
+    } else {
+        out.WriteString("..")
+    }
+    out.WriteString(fmt.Sprintf("%d", r.End))
+    if r.Step != 1 && r.Step != -1 {
+        out.WriteString(fmt.Sprintf(" step %d", r.Step))
+    }
+
+    return out.String()
+}
+
+func (r *Range) Len() int64 {
+    distance := r.End - r.Start
+    if r.Inclusive {
+        if r.Step > 0 {
+            distance++
+        } else {
+            distance--
+        }
+    }
+    if (r.Step > 0 && distance <= 0) || (r.Step < 0 && distance >= 0) {
+        return 0
+    }
+    return (distance + r.Step - sign(r.Step)) / r.Step
+}
+
+func (r *Range) At(idx int64) (Object, bool) {
+    if idx < 0 || idx >= r.Len() {
+        return nil, false
+    }
+    return &Integer{Value: r.Start + idx * r.Step}, true
+}
+
+func (r *Range) Contains(value int64) bool {
+    offset := value - r.Start
+    if offset % r.Step != 0 {
+        return false
+    }
+    idx := offset / r.Step
+    return idx >= 0 && idx < r.Len()
+}
+
+func (r *Range) Iterator() Iterator {
+    return &RangeIterator{theRange: r, length: r.Len(), idx: 0}
+}
+
+func sign(value int64) int64 {
+    if value < 0 {
+        return -1
+    }
+    return 1
+}
+
+
+type RangeIterator struct {
+    theRange *Range
+    length int64
+    idx int64
+}
+
+func (r *RangeIterator) Type() ObjectType {
+    return ITERATOR_OBJECT
+}
+
+func (r *RangeIterator) String() string {
+    return "iterator(" + r.theRange.String() + ")"
+}
+
+func (r *RangeIterator) HasNext() bool {
+    return r.idx < r.length
+}
+
+func (r *RangeIterator) Next() Object {
+    value, _ := r.theRange.At(r.idx)
+    r.idx++
+    return value
+}
+
+func (r *RangeIterator) Iterator() Iterator {
+    return r
+}
+
+
+// SliceIterator iterates over a snapshot of elements
+type SliceIterator struct {
+    elements []Object
+    idx int
+}
+
+func NewSliceIterator(elements []Object) *SliceIterator {
+    return &SliceIterator{elements: elements, idx: 0}
+}
+
+func (s *SliceIterator) Type() ObjectType {
+    return ITERATOR_OBJECT
+}
+
+func (s *SliceIterator) String() string {
+    return "iterator"
+}
+
+func (s *SliceIterator) HasNext() bool {
+    return s.idx < len(s.elements)
+}
+
+func (s *SliceIterator) Next() Object {
+    value := s.elements[s.idx]
+    s.idx++
+    return value
+}
+
+func (s *SliceIterator) Iterator() Iterator {
+    return s
+}
+
+
+func (a *Array) Iterator() Iterator {
+    return NewSliceIterator(a.Elements)
+}
+
+func (d *Deque) Iterator() Iterator {
+    return NewSliceIterator(d.Elements())
+}
+
+func (s *Set) Iterator() Iterator {
+    return NewSliceIterator(s.Values())
+}
+
+// Iterator of a hash returns its keys
+func (h *Hash) Iterator() Iterator {
+    elements := make([]Object, 0, len(h.Pairs))
+    for _, p := range h.Pairs {
+        elements = append(elements, p.Key)
+    }
+    return NewSliceIterator(elements)
+}
+
+// Iterator of a string returns its characters
+func (s *String) Iterator() Iterator {
+    elements := []Object{}
+    for _, r := range s.Value {
+        elements = append(elements, &String{Value: string(r)})
+    }
+    return NewSliceIterator(elements)
+}
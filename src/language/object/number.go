@@ -0,0 +1,251 @@
+package object
+
+import (
+    "hash/fnv"
+    "math"
+    "math/big"
+    "strconv"
+    "strings"
+)
+
+const (
+    BIGINT_OBJECT = "BIGINT"
+    DECIMAL_OBJECT = "DECIMAL"
+)
+
+// DECIMAL_DIVISION_SCALE is the number of fractional digits a decimal division is rounded to, if the operands do not have more
+const DECIMAL_DIVISION_SCALE = 20
+
+var bigTen = big.NewInt(10)
+
+// HashKey of a float is the one of the equal integer or decimal, so numbers which are == are the same key
+func (f *Float) HashKey() HashKey {
+    if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+        return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+    }
+    if f.Value == math.Trunc(f.Value) {
+        integer, _ := big.NewFloat(f.Value).Int(nil)
+        return (&BigInt{Value: integer}).HashKey()
+    }
+    decimal, _ := ParseDecimal(strconv.FormatFloat(f.Value, 'f', -1, 64))
+    return decimal.HashKey()
+}
+
+// BigInt is an integer of arbitrary size, it hashes like an Integer if it fits into one
+type BigInt struct {
+    Value *big.Int
+}
+
+func (b *BigInt) Type() ObjectType {
+    return BIGINT_OBJECT
+}
+
+func (b *BigInt) String() string {
+    return b.Value.String()
+}
+
+func (b *BigInt) HashKey() HashKey {
+    if b.Value.IsInt64() {
+        return (&Integer{Value: b.Value.Int64()}).HashKey()
+    }
+    h := fnv.New64a()
+    h.Write(b.Value.Bytes())
+    if b.Value.Sign() < 0 {
+        h.Write([]byte("-"))
+    }
+    return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+
+// Decimal is the exact decimal number Unscaled * 10^-Scale, it keeps its scale so 1.50 stays 1.50
+type Decimal struct {
+    Unscaled *big.Int
+    Scale int
+}
+
+func NewDecimal(value *big.Int) *Decimal {
+    return &Decimal{Unscaled: new(big.Int).Set(value), Scale: 0}
+}
+
+// ParseDecimal parses decimals like 12, -0.5 or 3.1415
+func ParseDecimal(s string) (*Decimal, bool) {
+    sign := ""
+    if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+        sign, s = s[:1], s[1:]
+    }
+    integral, fraction := s, ""
+    if point := strings.Index(s, "."); point >= 0 {
+        integral, fraction = s[:point], s[point + 1:]
+        if fraction == "" {
+            return nil, false
+        }
+    }
+    if integral == "" || !isDigits(integral) || !isDigits(fraction) {
+        return nil, false
+    }
+    unscaled, ok := new(big.Int).SetString(sign + integral + fraction, 10)
+    if !ok {
+        return nil, false
+    }
+    return &Decimal{Unscaled: unscaled, Scale: len(fraction)}, true
+}
+
+func isDigits(s string) bool {
+    for _, c := range s {
+        if c < '0' || c > '9' {
+            return false
+        }
+    }
+    return true
+}
+
+func (d *Decimal) Type() ObjectType {
+    return DECIMAL_OBJECT
+}
+
+func (d *Decimal) String() string {
+    digits := new(big.Int).Abs(d.Unscaled).String()
+    if d.Scale > 0 {
+        if len(digits) <= d.Scale {
+            digits = strings.Repeat("0", d.Scale - len(digits) + 1) + digits
+        }
+        digits = digits[:len(digits) - d.Scale] + "." + digits[len(digits) - d.Scale:]
+    }
+    if d.Unscaled.Sign() < 0 {
+        return "-" + digits
+    }
+    return digits
+}
+
+// HashKey ignores trailing zeros, so equal decimals have the same key and integral decimals hash like integers
+func (d *Decimal) HashKey() HashKey {
+    normalized := d.Normalize()
+    if normalized.Scale == 0 {
+        return (&BigInt{Value: normalized.Unscaled}).HashKey()
+    }
+    h := fnv.New64a()
+    h.Write([]byte(normalized.String()))
+    return HashKey{Type: d.Type(), Value: h.Sum64()}
+}
+
+// Normalize removes trailing zeros of the fractional part
+func (d *Decimal) Normalize() *Decimal {
+    unscaled := new(big.Int).Set(d.Unscaled)
+    scale := d.Scale
+    remainder := new(big.Int)
+    for scale > 0 {
+        quotient, rem := new(big.Int).QuoRem(unscaled, bigTen, remainder)
+        if rem.Sign() != 0 {
+            break
+        }
+        unscaled = quotient
+        scale--
+    }
+    return &Decimal{Unscaled: unscaled, Scale: scale}
+}
+
+func (d *Decimal) Rat() *big.Rat {
+    return new(big.Rat).SetFrac(d.Unscaled, pow10(d.Scale))
+}
+
+func (d *Decimal) Float64() float64 {
+    f, _ := d.Rat().Float64()
+    return f
+}
+
+// Truncate returns the integral part of d
+func (d *Decimal) Truncate() *big.Int {
+    return new(big.Int).Quo(d.Unscaled, pow10(d.Scale))
+}
+
+func (d *Decimal) Neg() *Decimal {
+    return &Decimal{Unscaled: new(big.Int).Neg(d.Unscaled), Scale: d.Scale}
+}
+
+func (d *Decimal) Add(other *Decimal) *Decimal {
+    lhs, rhs, scale := alignDecimals(d, other)
+    return &Decimal{Unscaled: lhs.Add(lhs, rhs), Scale: scale}
+}
+
+func (d *Decimal) Sub(other *Decimal) *Decimal {
+    lhs, rhs, scale := alignDecimals(d, other)
+    return &Decimal{Unscaled: lhs.Sub(lhs, rhs), Scale: scale}
+}
+
+func (d *Decimal) Mul(other *Decimal) *Decimal {
+    return &Decimal{Unscaled: new(big.Int).Mul(d.Unscaled, other.Unscaled), Scale: d.Scale + other.Scale}
+}
+
+// Quo divides and rounds half to even at DECIMAL_DIVISION_SCALE digits, trailing zeros beyond the scale of the operands are removed
+func (d *Decimal) Quo(other *Decimal) *Decimal {
+    minScale := d.Scale
+    if other.Scale > minScale {
+        minScale = other.Scale
+    }
+    scale := minScale
+    if scale < DECIMAL_DIVISION_SCALE {
+        scale = DECIMAL_DIVISION_SCALE
+    }
+
+    numerator := new(big.Int).Mul(d.Unscaled, pow10(scale + other.Scale - d.Scale))
+    quotient, remainder := new(big.Int).QuoRem(numerator, other.Unscaled, new(big.Int))
+
+    twiceRemainder := new(big.Int).Abs(remainder)
+    twiceRemainder.Lsh(twiceRemainder, 1)
+    cmp := twiceRemainder.Cmp(new(big.Int).Abs(other.Unscaled))
+    if cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1) {
+        if numerator.Sign() * other.Unscaled.Sign() < 0 {
+            quotient.Sub(quotient, big.NewInt(1))
+        } else {
+            quotient.Add(quotient, big.NewInt(1))
+        }
+    }
+
+    result := &Decimal{Unscaled: quotient, Scale: scale}
+    normalized := result.Normalize()
+    if normalized.Scale < minScale {
+        return normalized.Rescale(minScale)
+    }
+    return normalized
+}
+
+// Rem is the remainder of the truncated division, like % on integers
+func (d *Decimal) Rem(other *Decimal) *Decimal {
+    lhs, rhs, scale := alignDecimals(d, other)
+    return &Decimal{Unscaled: lhs.Rem(lhs, rhs), Scale: scale}
+}
+
+// FloorQuo is the quotient rounded towards negative infinity
+func (d *Decimal) FloorQuo(other *Decimal) *big.Int {
+    lhs, rhs, _ := alignDecimals(d, other)
+    quotient, remainder := new(big.Int).QuoRem(lhs, rhs, new(big.Int))
+    if remainder.Sign() != 0 && (lhs.Sign() < 0) != (rhs.Sign() < 0) {
+        quotient.Sub(quotient, big.NewInt(1))
+    }
+    return quotient
+}
+
+func (d *Decimal) Cmp(other *Decimal) int {
+    lhs, rhs, _ := alignDecimals(d, other)
+    return lhs.Cmp(rhs)
+}
+
+func (d *Decimal) Sign() int {
+    return d.Unscaled.Sign()
+}
+
+// Rescale pads the fractional part with zeros up to scale, scale must not be smaller than the scale of d
+func (d *Decimal) Rescale(scale int) *Decimal {
+    return &Decimal{Unscaled: new(big.Int).Mul(d.Unscaled, pow10(scale - d.Scale)), Scale: scale}
+}
+
+func alignDecimals(lhs *Decimal, rhs *Decimal) (*big.Int, *big.Int, int) {
+    if lhs.Scale < rhs.Scale {
+        return lhs.Rescale(rhs.Scale).Unscaled, new(big.Int).Set(rhs.Unscaled), rhs.Scale
+    }
+    return new(big.Int).Set(lhs.Unscaled), rhs.Rescale(lhs.Scale).Unscaled, lhs.Scale
+}
+
+func pow10(n int) *big.Int {
+    return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
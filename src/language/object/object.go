@@ -15,6 +15,7 @@ const (
     STRING_OBJECT = "STRING"
     NULL_OBJECT = "NULL"
     RETURN_OBJECT = "RETURN"
+    TAIL_CALL_OBJECT = "TAILCALL"
     BREAK_OBJECT = "BREAK"
     CONTINUE_OBJECT = "CONTINUE"
     FUNCTION_OBJECT = "FUNCTION"
@@ -24,6 +25,7 @@ const (
     MODULE_OBJECT = "MODULE"
     ERROR_OBJECT = "ERROR"
     PARSER_ERRORS_OBJECT = "PARSERERRORS"
+    IMPORT_BINDING_OBJECT = "IMPORTBINDING"
 )
 
 type ObjectType string
@@ -135,6 +137,22 @@ func (r *Return) String() string {
 }
 
 
+// TailCall is returned instead of the result of a call in tail position, the caller performs the call after its own frame is gone
+type TailCall struct {
+    Function Object
+    Arguments []Object
+    PosInfo ast.PositionalInfo
+}
+
+func (t *TailCall) Type() ObjectType {
+    return TAIL_CALL_OBJECT
+}
+
+func (t *TailCall) String() string {
+    return "tail call of " + t.Function.String()
+}
+
+
 type Break struct {
 }
 
@@ -159,9 +177,24 @@ func (c *Continue) String() string {
 }
 
 
+// StackFrame is a position in the code, Function is the name of the function the position is part of
+type StackFrame struct {
+    Function string
+    Position ast.PositionalInfo
+}
+
+func (s StackFrame) String() string {
+    function := s.Function
+    if function == "" {
+        function = "<module>"
+    }
+    return fmt.Sprintf("%s (%s:%d:%d)", function, s.Position.Path, s.Position.Line, s.Position.Column)
+}
+
+
 type Error struct {
     Message string
-    StackTrace []ast.PositionalInfo
+    StackTrace []StackFrame
 }
 
 func (e *Error) Type() ObjectType {
@@ -207,31 +240,49 @@ func (p *ParserErrors) String() string {
 
 
 type Function struct {
-    Parameters []string
+    Name string
+    Parameters []*ast.Parameter
+    ReturnType *ast.TypeAnnotation
     Body *ast.BlockStatement
     Env *Environment
+    IsGenerator bool
+    // Doc is the doc comment of the function literal
+    Doc string
 }
 
 func (f *Function) Type() ObjectType {
     return FUNCTION_OBJECT
 }
 
-func (f *Function) String() string {
-    var out bytes.Buffer
-
-    out.WriteString("fun(")
-    out.WriteString(strings.Join(f.Parameters, ", "))
-    out.WriteString(")")
-    out.WriteString(f.Body.String())
+// Signature returns the function head, it is used in error messages
+func (f *Function) Signature() string {
+    returnType := ""
+    if f.ReturnType != nil {
+        returnType = ": " + f.ReturnType.String()
+    }
+    if f.Name != "" {
+        return f.Name + "(" + ast.ParametersString(f.Parameters) + ")" + returnType
+    }
+    return "fun(" + ast.ParametersString(f.Parameters) + ")" + returnType
+}
 
-    return out.String()
+func (f *Function) String() string {
+    if f.Name != "" {
+        return "fun " + f.Signature()
+    }
+    return f.Signature()
 }
 
 
 type BuiltinFunction func(args ...Object) Object
 
+// BuiltinFunctionWithModules is a builtin which calls functions of the program, it gets the modules of the caller
+type BuiltinFunctionWithModules func(modules map[string]*Module, args ...Object) Object
+
 type Builtin struct {
     Function BuiltinFunction
+    // WithModules is called instead of Function if it is set
+    WithModules BuiltinFunctionWithModules
 }
 
 func (b *Builtin) Type() ObjectType {
@@ -245,6 +296,7 @@ func (b *Builtin) String() string {
 
 type Array struct {
     Elements []Object
+    Frozen bool
 }
 
 func (a *Array) Type() ObjectType {
@@ -282,6 +334,7 @@ type HashPair struct {
 
 type Hash struct {
     Pairs map[HashKey]HashPair
+    Frozen bool
 }
 
 func (h *Hash) Type() ObjectType {
@@ -307,6 +360,60 @@ func (h *Hash) String() string {
 type Module struct {
     Path string
     Env *Environment
+    // ReadOnly modules can not be assigned to by importers
+    ReadOnly bool
+    // Exports are the names visible to importers, all names are visible if it is nil
+    Exports map[string]bool
+    // Loading is set while the module code runs, names may be missing then because of circular imports
+    Loading bool
+    // Doc is the doc comment at the start of the module file
+    Doc string
+    // Cycle describes the circular import that accessed the module while it was loading
+    Cycle string
+    // LoadedAt is the position of the import which loaded the module, it is empty for the main module
+    LoadedAt ast.PositionalInfo
+}
+
+// ImportBinding is a name imported from a module which had not defined it yet because of a circular import,
+// environments replace it by the value once the module defines the name
+type ImportBinding struct {
+    Module *Module
+    Name string
+}
+
+func (b *ImportBinding) Type() ObjectType {
+    return IMPORT_BINDING_OBJECT
+}
+
+func (b *ImportBinding) String() string {
+    return b.Name + " of module " + b.Module.Path
+}
+
+// Resolve returns the value of the name once the module defined it
+func (b *ImportBinding) Resolve() (Object, bool) {
+    value, ok := b.Module.Env.Get(b.Name)
+    if !ok {
+        return nil, false
+    }
+    if _, unresolved := value.(*ImportBinding); unresolved {
+        return nil, false
+    }
+    return value, true
+}
+
+func (m *Module) IsExported(name string) bool {
+    return m.Exports == nil || m.Exports[name]
+}
+
+// ExportedNames returns the sorted names visible to importers
+func (m *Module) ExportedNames() []string {
+    names := []string{}
+    for _, name := range m.Env.Names() {
+        if m.IsExported(name) {
+            names = append(names, name)
+        }
+    }
+    return names
 }
 
 func (m *Module) Type() ObjectType {
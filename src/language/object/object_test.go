@@ -1,6 +1,8 @@
 package object
 
 import (
+    "math"
+    "math/big"
     "testing"
 )
 
@@ -60,3 +62,206 @@ func TestBoolHashKey(t *testing.T) {
         t.Errorf("booleans with different content have same hash")
     }
 }
+
+func TestDequeGrowsAroundTheEnds(t *testing.T) {
+    d := NewDeque()
+    for i := 0; i < 20; i++ {
+        d.PushBack(&Integer{Value: int64(i)})
+        d.PushFront(&Integer{Value: int64(-i)})
+    }
+
+    if d.Len() != 40 {
+        t.Fatalf("expected deque to have 40 elements, got %d", d.Len())
+    }
+
+    front, _ := d.PopFront()
+    if front.(*Integer).Value != -19 {
+        t.Errorf("expected front to be -19, got %s", front.String())
+    }
+
+    back, _ := d.PopBack()
+    if back.(*Integer).Value != 19 {
+        t.Errorf("expected back to be 19, got %s", back.String())
+    }
+
+    elements := d.Elements()
+    if elements[0].(*Integer).Value != -18 || elements[len(elements)-1].(*Integer).Value != 18 {
+        t.Errorf("deque elements are in the wrong order: %s", d.String())
+    }
+}
+
+func TestSetHashKeyIdentity(t *testing.T) {
+    s := NewSet()
+    s.Add(&String{Value: "Hello"})
+    s.Add(&String{Value: "Hello"})
+    s.Add(&Integer{Value: 1337})
+
+    if len(s.Elements) != 2 || len(s.Values()) != 2 {
+        t.Errorf("expected set to have 2 elements, got %d", len(s.Elements))
+    }
+
+    if !s.Contains(&String{Value: "Hello"}) {
+        t.Errorf("expected set to contain Hello")
+    }
+
+    if s.Add(&Array{}) {
+        t.Errorf("arrays should not be addable to a set")
+    }
+
+    if !s.Add(&Array{Elements: []Object{&Integer{Value: 1}}, Frozen: true}) {
+        t.Errorf("frozen arrays should be addable to a set")
+    }
+}
+
+func TestNumberHashKeys(t *testing.T) {
+    large, _ := new(big.Int).SetString("100000000000000000000", 10)
+    half, _ := ParseDecimal("0.5")
+    tests := []struct {
+        lhs Hashable
+        rhs Hashable
+        equal bool
+    }{
+        {&Integer{Value: 1}, &Float{Value: 1.0}, true},
+        {&Integer{Value: 0}, &Float{Value: math.Copysign(0, -1)}, true},
+        {&BigInt{Value: large}, &Float{Value: 1e20}, true},
+        {half, &Float{Value: 0.5}, true},
+        {&Integer{Value: 1}, &Float{Value: 1.5}, false},
+        {&Float{Value: math.Inf(1)}, &Float{Value: math.Inf(-1)}, false},
+    }
+
+    for _, tt := range tests {
+        if (tt.lhs.HashKey() == tt.rhs.HashKey()) != tt.equal {
+            t.Errorf("expected the hash keys of %s and %s to be equal: %t", tt.lhs.(Object).String(), tt.rhs.(Object).String(), tt.equal)
+        }
+    }
+}
+
+func TestSetOrder(t *testing.T) {
+    s := NewSet()
+    for i := 0; i < 1000; i++ {
+        s.Add(&Integer{Value: int64(i)})
+    }
+    for i := 0; i < 1000; i += 2 {
+        if !s.Remove(&Integer{Value: int64(i)}) {
+            t.Fatalf("expected %d to be removed", i)
+        }
+    }
+    s.Add(&Integer{Value: 0})
+
+    values := s.Values()
+    if len(values) != 501 || len(s.order) > 2 * len(values) {
+        t.Fatalf("expected 501 elements in a compacted order, got %d of %d", len(values), len(s.order))
+    }
+    for i, value := range values[:500] {
+        if value.(*Integer).Value != int64(2 * i + 1) {
+            t.Fatalf("expected element %d to be %d but got %s", i, 2 * i + 1, value.String())
+        }
+    }
+    if values[500].(*Integer).Value != 0 {
+        t.Fatalf("expected the element added again to be last but got %s", values[500].String())
+    }
+    if s.Remove(&Integer{Value: 2}) {
+        t.Fatalf("expected removing a missing element to fail")
+    }
+}
+
+func TestDecimal(t *testing.T) {
+    tests := []struct {
+        lhs string
+        rhs string
+        sum string
+        product string
+        quotient string
+    }{
+        {"1.50", "2", "3.50", "3.00", "0.75"},
+        {"10.00", "3", "13.00", "30.00", "3.33333333333333333333"},
+        {"-0.05", "0.1", "0.05", "-0.005", "-0.50"},
+        {"2", "3", "5", "6", "0.66666666666666666667"},
+        {"1", "8", "9", "8", "0.125"},
+    }
+
+    for _, tt := range tests {
+        lhs, ok := ParseDecimal(tt.lhs)
+        if !ok {
+            t.Fatalf("could not parse %s", tt.lhs)
+        }
+        rhs, ok := ParseDecimal(tt.rhs)
+        if !ok {
+            t.Fatalf("could not parse %s", tt.rhs)
+        }
+        if lhs.Add(rhs).String() != tt.sum {
+            t.Errorf("expected %s + %s to be %s, got %s", tt.lhs, tt.rhs, tt.sum, lhs.Add(rhs).String())
+        }
+        if lhs.Mul(rhs).String() != tt.product {
+            t.Errorf("expected %s * %s to be %s, got %s", tt.lhs, tt.rhs, tt.product, lhs.Mul(rhs).String())
+        }
+        if lhs.Quo(rhs).String() != tt.quotient {
+            t.Errorf("expected %s / %s to be %s, got %s", tt.lhs, tt.rhs, tt.quotient, lhs.Quo(rhs).String())
+        }
+    }
+
+    for _, invalid := range []string{"", "-", "1.", ".5", "1.2.3", "1e5", "--1"} {
+        if _, ok := ParseDecimal(invalid); ok {
+            t.Errorf("expected %q not to be a decimal", invalid)
+        }
+    }
+
+    a, _ := ParseDecimal("1.50")
+    b, _ := ParseDecimal("1.5")
+    if a.HashKey() != b.HashKey() {
+        t.Errorf("decimals which differ in trailing zeros have different hash")
+    }
+    c, _ := ParseDecimal("2.00")
+    if c.HashKey() != (&Integer{Value: 2}).HashKey() {
+        t.Errorf("integral decimals should hash like integers")
+    }
+}
+
+func TestFrozenHashKey(t *testing.T) {
+    array1 := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+    array2 := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+    Freeze(array1)
+    Freeze(array2)
+
+    key1, ok1 := HashKeyOf(array1)
+    key2, ok2 := HashKeyOf(array2)
+    if !ok1 || !ok2 || key1 != key2 {
+        t.Errorf("frozen arrays with same content have different hash")
+    }
+
+    reversed := &Array{Elements: []Object{&String{Value: "a"}, &Integer{Value: 1}}, Frozen: true}
+    key3, _ := HashKeyOf(reversed)
+    if key1 == key3 {
+        t.Errorf("frozen arrays with different order have same hash")
+    }
+
+    if _, ok := HashKeyOf(&Array{Elements: []Object{&Integer{Value: 1}}}); ok {
+        t.Errorf("arrays which are not frozen should not be hashable")
+    }
+
+    if _, ok := HashKeyOf(&Array{Elements: []Object{&Array{}}, Frozen: true}); ok {
+        t.Errorf("frozen arrays with unhashable elements should not be hashable")
+    }
+
+    hash1 := &Hash{Pairs: map[HashKey]HashPair{}}
+    hash2 := &Hash{Pairs: map[HashKey]HashPair{}}
+    for _, name := range []string{"a", "b", "c"} {
+        key := &String{Value: name}
+        hash1.Pairs[key.HashKey()] = HashPair{Key: key, Value: array1}
+        hash2.Pairs[key.HashKey()] = HashPair{Key: key, Value: array2}
+    }
+    Freeze(hash1)
+    Freeze(hash2)
+    key4, ok4 := HashKeyOf(hash1)
+    key5, ok5 := HashKeyOf(hash2)
+    if !ok4 || !ok5 || key4 != key5 {
+        t.Errorf("frozen hashes with same content have different hash")
+    }
+
+    cyclic := &Array{Elements: []Object{&Integer{Value: 1}}}
+    cyclic.Elements = append(cyclic.Elements, cyclic)
+    Freeze(cyclic)
+    if _, ok := HashKeyOf(cyclic); !ok {
+        t.Errorf("cyclic frozen arrays should be hashable")
+    }
+}
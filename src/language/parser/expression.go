@@ -2,7 +2,9 @@ package parser
 
 import (
     "fmt"
+    "math/big"
     "strconv"
+    "strings"
     "language/ast"
     "language/token"
 )
@@ -66,6 +68,27 @@ func (p *Parser) infix(lhs ast.Expression) ast.Expression {
     return &ast.InfixExpression{Op: op, Lhs: lhs, Rhs: rhs, PosInfo: p.tokToPos(op)}
 }
 
+func (p *Parser) rangeExpr(start ast.Expression) ast.Expression {
+    op := p.advance()
+    if op.Type != token.RANGE && op.Type != token.RANGEINCLUSIVE {
+        p.pushNewError("expected range operator", op)
+        return nil
+    }
+    end := p.expressionWithPrecedence(RANGE)
+    if end == nil {
+        return nil
+    }
+    var step ast.Expression
+    if p.match(token.STEP) {
+        step = p.expressionWithPrecedence(RANGE)
+        if step == nil {
+            return nil
+        }
+    }
+
+    return &ast.RangeExpression{Start: start, End: end, Step: step, Inclusive: op.Type == token.RANGEINCLUSIVE, PosInfo: p.tokToPos(op)}
+}
+
 func (p *Parser) funcLit() ast.Expression {
     p.openFunctionDefinition()
     defer p.closeFunctionDefinition()
@@ -74,6 +97,10 @@ func (p *Parser) funcLit() ast.Expression {
         p.pushNewError("Expected function literal", p.peek())
         return nil
     }
+    name := ""
+    if p.is(token.IDENTIFIER) {
+        name = p.advance().Literal
+    }
     if !p.match(token.LPAREN) {
         p.pushNewError("expected (", p.peek())
         return nil
@@ -86,31 +113,86 @@ func (p *Parser) funcLit() ast.Expression {
         p.pushNewError("expected )", p.peek())
         return nil
     }
+    returnType, ok := p.optionalTypeAnnotation()
+    if !ok {
+        return nil
+    }
     body := p.block()
     if body == nil {
         return nil
     }
 
-    return &ast.FunctionLiteralExpression{Parameters: params, Body: body, PosInfo: p.tokToPos(funToken)}
+    return &ast.FunctionLiteralExpression{Name: name, Parameters: params, ReturnType: returnType, Body: body, IsGenerator: p.isGeneratorDefinition(), Doc: funToken.Doc, PosInfo: p.tokToPos(funToken)}
 }
 
-func (p *Parser) functionParameters() ([]string, bool) {
-    params := make([]string, 0)
-    if p.peek().Type == token.IDENTIFIER {
-        param := p.advance()
-        params = append(params, param.Literal)
+func (p *Parser) functionParameters() ([]*ast.Parameter, bool) {
+    params := make([]*ast.Parameter, 0)
+    if p.is(token.RPAREN) {
+        return params, false
     }
-    for p.peek().Type == token.COMMA {
-        p.advance()
-        if p.peek().Type != token.IDENTIFIER {
-            p.pushNewError("", p.peek())
+    hadDefault := false
+    for {
+        paramToken := p.peek()
+        param := p.parameter()
+        if param == nil {
+            return params, true
+        }
+        if param.Variadic && !p.is(token.RPAREN) {
+            p.pushNewError("the variadic parameter has to be the last parameter", p.peek())
             return params, true
         }
-        param := p.advance()
-        params = append(params, param.Literal)
+        if hadDefault && param.Default == nil && !param.Variadic {
+            p.pushNewError("a parameter without default value must not follow a parameter with default value", paramToken)
+            return params, true
+        }
+        hadDefault = hadDefault || param.Default != nil
+        params = append(params, param)
+        if !p.match(token.COMMA) {
+            return params, false
+        }
+    }
+}
+
+func (p *Parser) parameter() *ast.Parameter {
+    paramToken := p.peek()
+    if p.match(token.ELLIPSIS) {
+        name := p.advance()
+        if name.Type != token.IDENTIFIER {
+            p.pushNewError("expected identifier", name)
+            return nil
+        }
+        annotation, ok := p.optionalTypeAnnotation()
+        if !ok {
+            return nil
+        }
+        return &ast.Parameter{Name: name.Literal, Type: annotation, Variadic: true, PosInfo: p.tokToPos(paramToken)}
+    }
+
+    param := &ast.Parameter{PosInfo: p.tokToPos(paramToken)}
+    if p.isDestructuringPattern() {
+        param.Pattern = p.destructuringPattern()
+        if param.Pattern == nil {
+            return nil
+        }
+    } else if p.match(token.IDENTIFIER) {
+        param.Name = paramToken.Literal
+        annotation, ok := p.optionalTypeAnnotation()
+        if !ok {
+            return nil
+        }
+        param.Type = annotation
+    } else {
+        p.pushNewError("expected parameter", paramToken)
+        return nil
     }
 
-    return params, false
+    if p.match(token.ASSIGN) {
+        param.Default = p.expression()
+        if param.Default == nil {
+            return nil
+        }
+    }
+    return param
 }
 
 func (p *Parser) conditional(cond ast.Expression) ast.Expression {
@@ -153,7 +235,7 @@ func (p *Parser) functionArguments() ([]ast.Expression, bool) {
     args := []ast.Expression{}
 
     if p.peek().Type != token.RPAREN {
-        arg := p.expression()
+        arg := p.argument(false)
         if arg == nil {
             return args, true
         }
@@ -162,7 +244,8 @@ func (p *Parser) functionArguments() ([]ast.Expression, bool) {
     for p.peek().Type == token.COMMA {
         p.advance()
         
-        arg := p.expression()
+        _, hadNamed := args[len(args)-1].(*ast.NamedArgumentExpression)
+        arg := p.argument(hadNamed)
         if arg == nil {
             return args, true
         }
@@ -172,6 +255,34 @@ func (p *Parser) functionArguments() ([]ast.Expression, bool) {
     return args, false
 }
 
+// argument parses a positional, spread or named argument, after a named argument only named arguments are allowed
+func (p *Parser) argument(onlyNamed bool) ast.Expression {
+    argToken := p.peek()
+    if p.are(token.IDENTIFIER, token.COLON) {
+        p.advance()
+        p.advance()
+        value := p.expression()
+        if value == nil {
+            return nil
+        }
+        return &ast.NamedArgumentExpression{Name: argToken.Literal, Value: value, PosInfo: p.tokToPos(argToken)}
+    }
+
+    if onlyNamed {
+        p.pushNewError("positional arguments must not follow named arguments", argToken)
+        return nil
+    }
+
+    if p.match(token.ELLIPSIS) {
+        value := p.expression()
+        if value == nil {
+            return nil
+        }
+        return &ast.SpreadExpression{Value: value, PosInfo: p.tokToPos(argToken)}
+    }
+    return p.expression()
+}
+
 func (p *Parser) grouping() ast.Expression {
     if !p.match(token.LPAREN) {
         p.pushNewError("expected '('", p.peek())
@@ -195,9 +306,10 @@ func (p *Parser) parseInt() ast.Expression {
     intToken := p.peek()
     if p.is(token.INT) {
         tok := p.advance()
-        intValue, err := strconv.ParseInt(tok.Literal, 10, 64)
+        digits, base := numberLiteral(tok.Literal)
+        intValue, err := strconv.ParseInt(digits, base, 64)
         if err != nil {
-            p.pushError(err)
+            p.pushNewError(fmt.Sprintf("invalid integer-literal %s: %s", tok.Literal, err.(*strconv.NumError).Err), tok)
             return nil
         }
         return &ast.IntegerLiteralExpression{Value: intValue, PosInfo: p.tokToPos(intToken)}
@@ -207,11 +319,28 @@ func (p *Parser) parseInt() ast.Expression {
     return nil
 }
 
+// numberLiteral removes the digit separators of a number literal and returns its digits without the base prefix
+func numberLiteral(literal string) (string, int) {
+    digits := strings.Replace(literal, "_", "", -1)
+    if len(digits) > 2 && digits[0] == '0' {
+        switch digits[1] {
+        case 'x':
+            return digits[2:], 16
+        case 'b':
+            return digits[2:], 2
+        case 'o':
+            return digits[2:], 8
+        }
+    }
+    return digits, 10
+}
+
 func (p *Parser) parseFloat() ast.Expression {
     floatToken := p.peek()
     if p.is(token.FLOAT) {
         tok := p.advance()
-        floatValue, err := strconv.ParseFloat(tok.Literal, 64)
+        digits, _ := numberLiteral(tok.Literal)
+        floatValue, err := strconv.ParseFloat(digits, 64)
         if err != nil {
             p.pushError(err)
             return nil
@@ -223,6 +352,35 @@ func (p *Parser) parseFloat() ast.Expression {
     return nil
 }
 
+func (p *Parser) parseBigInt() ast.Expression {
+    bigIntToken := p.peek()
+    if p.is(token.BIGINT) {
+        tok := p.advance()
+        digits, base := numberLiteral(strings.TrimSuffix(tok.Literal, "n"))
+        value, ok := new(big.Int).SetString(digits, base)
+        if !ok {
+            p.pushNewError(fmt.Sprintf("invalid bigint-literal %s", tok.Literal), tok)
+            return nil
+        }
+        return &ast.BigIntLiteralExpression{Value: value, PosInfo: p.tokToPos(bigIntToken)}
+    }
+
+    p.pushNewError("expected bigint-literal", p.peek())
+    return nil
+}
+
+func (p *Parser) parseDecimal() ast.Expression {
+    decimalToken := p.peek()
+    if p.is(token.DECIMAL) {
+        tok := p.advance()
+        digits, _ := numberLiteral(strings.TrimSuffix(tok.Literal, "d"))
+        return &ast.DecimalLiteralExpression{Value: digits, PosInfo: p.tokToPos(decimalToken)}
+    }
+
+    p.pushNewError("expected decimal-literal", p.peek())
+    return nil
+}
+
 func (p *Parser) parseString() ast.Expression {
     stringToken := p.peek()
     if p.is(token.STRING) {
@@ -301,6 +459,33 @@ func (p *Parser) parseHash() ast.Expression {
     return &ast.HashLiteral{Pairs: pairs, PosInfo: p.tokToPos(hashToken)}
 }
 
+// parseFrozen parses #[...] and #{...}, which evaluate to deeply frozen arrays and hashes
+func (p *Parser) parseFrozen() ast.Expression {
+    if !p.match(token.FROZEN) {
+        p.pushNewError("expected #", p.peek())
+        return nil
+    }
+
+    switch p.peek().Type {
+    case token.LBRACKET:
+        array, ok := p.parseArray().(*ast.ArrayLiteral)
+        if !ok {
+            return nil
+        }
+        array.Frozen = true
+        return array
+    case token.LBRACE:
+        hash, ok := p.parseHash().(*ast.HashLiteral)
+        if !ok {
+            return nil
+        }
+        hash.Frozen = true
+        return hash
+    }
+    p.pushNewError("expected [ or { after #", p.peek())
+    return nil
+}
+
 func (p *Parser) parseArray() ast.Expression {
     bracketToken := p.peek()
     if !p.match(token.LBRACKET) {
@@ -340,14 +525,27 @@ func (p *Parser) property(lhs ast.Expression) ast.Expression {
         return nil
     }
 
-    if p.peek().Type != token.IDENTIFIER {
+    name, ok := p.propertyName()
+    if !ok {
         p.pushNewError("expected identifier", p.peek())
         return nil
     }
-    name := p.advance()
     index := &ast.StringLiteralExpression{Value: name.Literal, PosInfo: p.tokToPos(name)}
 
-    return &ast.IndexExpression{Left: lhs, Index: index, PosInfo: p.tokToPos(dotToken)}
+    return &ast.IndexExpression{Left: lhs, Index: index, Property: true, PosInfo: p.tokToPos(dotToken)}
+}
+
+// propertyName accepts identifiers and keywords, so that keywords can be used as names of properties
+func (p *Parser) propertyName() (token.Token, bool) {
+    if p.is(token.IDENTIFIER) {
+        return p.advance(), true
+    }
+    if keyword, ok := token.KeywordFromType(p.peek().Type); ok {
+        name := p.advance()
+        name.Literal = keyword
+        return name, true
+    }
+    return p.peek(), false
 }
 
 func (p *Parser) index(lhs ast.Expression) ast.Expression {
@@ -377,6 +575,8 @@ func isUnaryOperator(tok token.Token) bool {
         return true
     case token.NEG:
         return true
+    case token.BITNOT:
+        return true
     }
     return false
 }
@@ -393,6 +593,10 @@ func isInfixOperator(tok token.Token) bool {
         return true
     case token.MOD:
         return true
+    case token.POW:
+        return true
+    case token.FLOORDIV:
+        return true
     case token.AND:
         return true
     case token.OR:
@@ -401,6 +605,8 @@ func isInfixOperator(tok token.Token) bool {
         return true
     case token.NEQ:
         return true
+    case token.IS:
+        return true
     case token.LT:
         return true
     case token.GT:
@@ -421,12 +627,14 @@ func isInfixOperator(tok token.Token) bool {
         return true
     case token.MODASSIGN:
         return true
+    case token.BITAND, token.BITOR, token.BITXOR, token.SHL, token.SHR:
+        return true
+    case token.BITANDASSIGN, token.BITORASSIGN, token.BITXORASSIGN, token.SHLASSIGN, token.SHRASSIGN:
+        return true
     case token.NULLCOAL:
         return true
     case token.DOT:
         return true
-    case token.RANGE:
-        return true
     case token.QUESTION:
         return true
     }
@@ -434,5 +642,5 @@ func isInfixOperator(tok token.Token) bool {
 }
 
 func isRightAssoc(prec int) bool {
-    return prec == TERNARY || prec == ASSIGN || prec == NULLCOALESCING || prec == PREFIX // prefix should never be returned since it is unary, not infix
+    return prec == TERNARY || prec == ASSIGN || prec == NULLCOALESCING || prec == POWER || prec == PREFIX // prefix should never be returned since it is unary, not infix
 }
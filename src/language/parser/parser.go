@@ -14,7 +14,10 @@ type Parser struct {
     prefixParseFunctions map[token.TokenType]prefixParseFunction
     infixParseFunctions map[token.TokenType]infixParseFunction
     errors []error
+    warnings []string
     numberOfEnclosingFunctions int
+    isGeneratorStack []bool
+    tryDepthStack []int
     isInLoopStack []bool
     filePath string
 }
@@ -53,6 +56,8 @@ func (p *Parser) Parse() (*ast.Program, []error) {
     if !p.isAtEnd() && len(p.errors) == 0 {
         p.pushNewError("There are unparsed tokens left", p.peek())
     }
+    result.Warnings = p.warnings
+    result.Doc = p.scanner.ModuleDoc()
     return &result, p.errors
 }
 
@@ -65,6 +70,10 @@ func (p *Parser) pushNewError(msg string, at token.Token) {
     p.pushError(errors.New(positionalMsg))
 }
 
+func (p *Parser) pushNewWarning(msg string, at ast.PositionalInfo) {
+    p.warnings = append(p.warnings, fmt.Sprintf("%s: warning: %s", at.String(), msg))
+}
+
 func (p *Parser) advance() token.Token {
     idxOfLastBufferElement := len(p.tokenBuffer) - 1
     result := p.tokenBuffer[0]
@@ -114,14 +123,44 @@ func (p *Parser) HadErrors() bool {
 
 func (p *Parser) openFunctionDefinition() {
     p.numberOfEnclosingFunctions++
+    p.isGeneratorStack = append(p.isGeneratorStack, false)
+    p.tryDepthStack = append(p.tryDepthStack, 0)
     p.pushLoopStack(false)
 }
 
 func (p *Parser) closeFunctionDefinition() {
     p.numberOfEnclosingFunctions--
+    p.isGeneratorStack = p.isGeneratorStack[:len(p.isGeneratorStack)-1]
+    p.tryDepthStack = p.tryDepthStack[:len(p.tryDepthStack)-1]
     p.popLoopStack()
 }
 
+// markGenerator marks the innermost function definition as generator, because it contains a yield
+func (p *Parser) markGenerator() {
+    p.isGeneratorStack[len(p.isGeneratorStack)-1] = true
+}
+
+func (p *Parser) isGeneratorDefinition() bool {
+    return p.isInFunctionDefinition() && p.isGeneratorStack[len(p.isGeneratorStack)-1]
+}
+
+func (p *Parser) enterTry() {
+    if len(p.tryDepthStack) > 0 {
+        p.tryDepthStack[len(p.tryDepthStack)-1]++
+    }
+}
+
+func (p *Parser) exitTry() {
+    if len(p.tryDepthStack) > 0 {
+        p.tryDepthStack[len(p.tryDepthStack)-1]--
+    }
+}
+
+// isInTry checks if the innermost function definition is inside of a try block
+func (p *Parser) isInTry() bool {
+    return len(p.tryDepthStack) > 0 && p.tryDepthStack[len(p.tryDepthStack)-1] > 0
+}
+
 func (p *Parser) isInFunctionDefinition() bool {
     return p.numberOfEnclosingFunctions > 0
 }
@@ -1,6 +1,7 @@
 package parser
 
 import (
+    "strings"
     "testing"
     "language/ast"
     "language/scanner"
@@ -87,6 +88,58 @@ func TestImport(t *testing.T) {
     }
 }
 
+func TestImportForms(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"import \"lib/my_module.fml\";", "import lib/my_module.fml as my_module"},
+        {"import \"lib/my_module.fml\" as m;", "import lib/my_module.fml as m"},
+        {"from \"lib/math.fml\" import a;", "from lib/math.fml import a"},
+        {"from \"lib/math.fml\" import a, b as c;", "from lib/math.fml import a, b as c"},
+        {"from \"lib/math.fml\" import (\n    a,\n    b as c,\n);", "from lib/math.fml import a, b as c"},
+        {"from \"lib/math.fml\" import *;", "from lib/math.fml import *"},
+        {"from \"lib/math.fml\" import *", "from lib/math.fml import *"},
+        {"import (\"a.fml\", \"b.fml\" as c);", "import (a.fml as a, b.fml as c)"},
+        {"import (\n    \"a.fml\",\n    \"b.fml\" as c,\n)", "import (a.fml as a, b.fml as c)"},
+        {"from(1);", "from(1);"},
+    }
+
+    for _, tt := range tests {
+        program := parseProgram(t, tt.input)
+        handleProgramLength(t, program, 1)
+        if program.Statements[0].String() != tt.expected {
+            t.Fatalf("expected %s but got %s", tt.expected, program.Statements[0].String())
+        }
+    }
+}
+
+func TestImportErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"import \"my-module.fml\";", "line: 1, column: 8, Literal: \"my-module.fml\" [STRING]: cannot derive a module name from my-module.fml, name it with as"},
+        {"import \"if.fml\";", "line: 1, column: 8, Literal: \"if.fml\" [STRING]: cannot derive a module name from if.fml, name it with as"},
+        {"from \"a.fml\" import 1;", "line: 1, column: 21, Literal: \"1\" [INT]: expected identifier"},
+        {"from \"a.fml\" import *, a;", "line: 1, column: 22, Literal: \"\" [,]: cannot import other names or an alias with *"},
+        {"from \"a.fml\" import ();", "line: 1, column: 23, Literal: \"\" [;]: expected at least one name to import"},
+        {"import (\"a.fml\";", "line: 1, column: 16, Literal: \"\" [;]: expected )"},
+    }
+
+    for _, tt := range tests {
+        s := scanner.New(tt.input)
+        p := New(s, "test")
+        _, err := p.Parse()
+        if len(err) == 0 {
+            t.Fatalf("expected an error \"%s\" but got none", tt.expected)
+        }
+        if err[0].Error() != tt.expected {
+            t.Fatalf("Expected error msg to be \"%s\" but got \"%s\"", tt.expected, err[0].Error())
+        }
+    }
+}
+
 func TestTryCatch(t *testing.T) {
     input := "try {} catch exception {}"
 
@@ -154,6 +207,150 @@ func TestBreakAndContinueStatements(t *testing.T) {
     }
 }
 
+func TestYieldStatement(t *testing.T) {
+    tests := []struct {
+        input string
+        expectError bool
+        expected string
+    }{
+        {"yield 1;", true, "line: 1, column: 7, Literal: \"1\" [INT]: yield is only allowed in function definitions"},
+        {"loop forever { yield 1; }", true, "line: 1, column: 22, Literal: \"1\" [INT]: yield is only allowed in function definitions"},
+        {"fun() { yield 1; };", false, "true"},
+        {"fun() { loop i in 0..10 { yield i; } };", false, "true"},
+        {"fun() { return 1; };", false, "false"},
+        {"fun() { const inner = fun() { yield 1; }; };", false, "false"},
+    }
+
+    for _, tt := range tests {
+        s := scanner.New(tt.input)
+        p := New(s, "test")
+        program, err := p.Parse()
+        hadError := len(err) != 0
+        if hadError != tt.expectError {
+            if tt.expectError {
+                t.Fatalf("expected an error \"%s\" but got none", tt.expected)
+            } else {
+                handleParserErrors(t, err)
+            }
+        }
+        if hadError {
+            errorMsg := err[0].Error()
+            if errorMsg != tt.expected {
+                t.Fatalf("Expected error msg to be \"%s\" but got \"%s\"", tt.expected, errorMsg)
+            }
+            continue
+        }
+        handleProgramLength(t, program, 1)
+        exprStmt := toExprStmt(t, program.Statements[0])
+        funcLit, ok := exprStmt.Expr.(*ast.FunctionLiteralExpression)
+        if !ok {
+            t.Fatalf("Expected FunctionLiteralExpression but got %T", exprStmt.Expr)
+        }
+        isGenerator := "false"
+        if funcLit.IsGenerator {
+            isGenerator = "true"
+        }
+        if isGenerator != tt.expected {
+            t.Fatalf("Expected IsGenerator to be %s for %s", tt.expected, tt.input)
+        }
+    }
+}
+
+func TestMatchExpression(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"match a { 1 => \"one\", _ => \"many\" };", "match a { 1 => \"one\"; _ => \"many\"; }"},
+        {"match a { -1..=1 => 0 0..10 => 1 };", "match a { (-1)..=1 => 0; 0..10 => 1; }"},
+        {"match a { [x, _, ...rest] => rest };", "match a { [x, _, ...rest] => rest; }"},
+        {"match a { {name, \"age\": n: int} => n };", "match a { {\"name\": name, \"age\": n: int} => n; }"},
+        {"match a { n if n > 0 => { n; } };", "match a { n if (n>0) => { n; } }"},
+        {"match a { _: null => 0, x: function => x() };", "match a { _: null => 0; x: function => x(); }"},
+    }
+
+    for _, tt := range tests {
+        program := parseProgram(t, tt.input)
+        handleProgramLength(t, program, 1)
+
+        exprStmt := toExprStmt(t, program.Statements[0])
+        matchExpr, ok := exprStmt.Expr.(*ast.MatchExpression)
+        if !ok {
+            t.Fatalf("Expected MatchExpression but got %T", exprStmt.Expr)
+        }
+        if matchExpr.String() != tt.expected {
+            t.Fatalf("expected \"%s\" but got \"%s\"", tt.expected, matchExpr.String())
+        }
+    }
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"match a { 1 \"one\" };", "line: 1, column: 13, Literal: \"one\" [STRING]: expected =>"},
+        {"match a { x: number => x };", "line: 1, column: 14, Literal: \"number\" [IDENTIFIER]: expected type name"},
+        {"match a { [...rest, x] => x };", "line: 1, column: 19, Literal: \"\" [,]: the rest pattern has to be the last element"},
+        {"match a { a + 1 => 1 };", "line: 1, column: 13, Literal: \"\" [+]: expected =>"},
+    }
+
+    for _, tt := range tests {
+        s := scanner.New(tt.input)
+        p := New(s, "test")
+        _, err := p.Parse()
+        if len(err) == 0 {
+            t.Fatalf("expected an error \"%s\" but got none", tt.expected)
+        }
+        if err[0].Error() != tt.expected {
+            t.Fatalf("Expected error msg to be \"%s\" but got \"%s\"", tt.expected, err[0].Error())
+        }
+    }
+}
+
+func TestMatchExpressionWarnings(t *testing.T) {
+    tests := []struct {
+        input string
+        expectedWarnings int
+    }{
+        {"match a { 1 => 1, _ => 2 };", 0},
+        {"match a { _ => 1, 1 => 2, 2 => 3 };", 2},
+        {"match a { x => 1, _ => 2 };", 1},
+        {"match a { x if x > 1 => 1, _ => 2 };", 0},
+        {"match a { 1 => 1, 1 => 2, _ => 3 };", 1},
+        {"match a { [x] => 1, [x] => 2 };", 0},
+    }
+
+    for _, tt := range tests {
+        program := parseProgram(t, tt.input)
+        if len(program.Warnings) != tt.expectedWarnings {
+            t.Fatalf("expected %d warnings for %s but got %v", tt.expectedWarnings, tt.input, program.Warnings)
+        }
+    }
+}
+
+func TestDestructuring(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"let [a, b, ...rest] = arr;", "let [a, b, ...rest] = arr;"},
+        {"const {name, age} = person;", "const {\"name\": name, \"age\": age} = person;"},
+        {"let {point: [x, y]} = shape;", "let {\"point\": [x, y]} = shape;"},
+        {"loop [k, v] in pairs { }", "loop [k, v] in pairs{ }"},
+        {"loop i, {name} in people { }", "loop i, {\"name\": name} in people{ }"},
+    }
+
+    for _, tt := range tests {
+        program := parseProgram(t, tt.input)
+        handleProgramLength(t, program, 1)
+
+        if program.Statements[0].String() != tt.expected {
+            t.Fatalf("expected \"%s\" but got \"%s\"", tt.expected, program.Statements[0].String())
+        }
+    }
+}
+
 func TestLetStatement(t *testing.T) {
     tests := []struct {
         input string
@@ -226,6 +423,7 @@ func TestLoopStatement(t *testing.T) {
         {"loop forever { doIt(); }", "true", "{ doIt(); }"},
         {"loop element in 1..11 { println(element); }", "element in (1..11)", "{ println(element); }"},
         {"loop i, e in 1..11 { println(i, \": \", e); }", "i, e in (1..11)", "{ println(i, \": \", e); }"},
+        {"loop i in 0..=a step 2 { println(i); }", "i in (0..=a step 2)", "{ println(i); }"},
     }
 
     for _, tt := range tests {
@@ -500,6 +698,8 @@ func TestFunctionLiteralExpression(t *testing.T) {
         {"fun(a, b, c) { a+b*c; };", []string{"a", "b", "c"}},
         {"fun(a) {};", []string{"a"}},
         {"fun() {};", []string{}},
+        {"fun([a, b], {c, \"d\": d}, e) {};", []string{"[a, b]", "{\"c\": c, \"d\": d}", "e"}},
+        {"fun(a, b = 1, [c] = [a + b], ...rest) {};", []string{"a", "b = 1", "[c] = [(a+b)]", "...rest"}},
     }
 
     for _, tt := range tests {
@@ -519,8 +719,8 @@ func TestFunctionLiteralExpression(t *testing.T) {
         }
 
         for i, p := range funcLitStmt.Parameters {
-            if p != tt.params[i] {
-                t.Fatalf("Expected parameter number %d to be %s but got %s", i + 1, tt.params[i], p)
+            if p.String() != tt.params[i] {
+                t.Fatalf("Expected parameter number %d to be %s but got %s", i + 1, tt.params[i], p.String())
             }
         }
 
@@ -539,6 +739,8 @@ func TestCallExpression(t *testing.T) {
         {"a();", "a", []string{}},
         {"a(1, 2, 3);", "a", []string{"1", "2", "3"}},
         {"a(1, 2*3, 3);", "a", []string{"1", "(2*3)", "3"}},
+        {"a(...b, 1);", "a", []string{"...b", "1"}},
+        {"a(1, b: 2, c: x ? 1 : 2);", "a", []string{"1", "b: 2", "c: (x?1:2)"}},
     }
 
     for _, tt := range tests {
@@ -571,6 +773,283 @@ func TestCallExpression(t *testing.T) {
     }
 }
 
+func TestFunctionDeclaration(t *testing.T) {
+    tests := []struct {
+        input string
+        name string
+        expected string
+    }{
+        {"fun add(a, b) { return a + b; }", "add", "fun add(a, b){ return (a+b); }"},
+        {"fun noop() {};", "noop", "fun noop(){ }"},
+        {"fun gen() { yield 1; }", "gen", "fun gen(){ yield 1; }"},
+    }
+
+    for _, tt := range tests {
+        program := parseProgram(t, tt.input)
+        handleProgramLength(t, program, 1)
+
+        declaration, ok := program.Statements[0].(*ast.FunctionDeclarationStatement)
+        if !ok {
+            t.Fatalf("Expected FunctionDeclarationStatement but got %T", program.Statements[0])
+        }
+        if declaration.Function.Name != tt.name {
+            t.Fatalf("Expected function name to be %s but got %s", tt.name, declaration.Function.Name)
+        }
+        if declaration.String() != tt.expected {
+            t.Fatalf("expected \"%s\" but got \"%s\"", tt.expected, declaration.String())
+        }
+    }
+}
+
+func TestTailCallMarking(t *testing.T) {
+    tests := []struct {
+        input string
+        expected []bool
+    }{
+        {"fun f() { return g(); }", []bool{true}},
+        {"fun f() { return g() + 1; }", []bool{false}},
+        {"fun f() { return g(h()); }", []bool{true, false}},
+        {"fun f() { return a ? g() : h(); }", []bool{true, true}},
+        {"fun f() { return match a { 1 => g(), _ => { h(); } }; }", []bool{true, false}},
+        {"fun f() { try { return g(); } catch e { return h(); } }", []bool{false, true}},
+        {"fun f() { try { const i = fun() { return g(); }; } catch e {} }", []bool{true}},
+    }
+
+    for _, tt := range tests {
+        program := parseProgram(t, tt.input)
+        handleProgramLength(t, program, 1)
+
+        calls := []bool{}
+        collectCalls(program.Statements[0], &calls)
+        if len(calls) != len(tt.expected) {
+            t.Fatalf("expected %d calls in %s but got %d", len(tt.expected), tt.input, len(calls))
+        }
+        for i, isTailCall := range calls {
+            if isTailCall != tt.expected[i] {
+                t.Fatalf("expected call %d in %s to have IsTailCall %t", i, tt.input, tt.expected[i])
+            }
+        }
+    }
+}
+
+// collectCalls collects whether the calls in node are tail calls in the order they appear in the source
+func collectCalls(node ast.Node, calls *[]bool) {
+    switch node := node.(type) {
+    case *ast.FunctionDeclarationStatement:
+        collectCalls(node.Function, calls)
+    case *ast.FunctionLiteralExpression:
+        collectCalls(node.Body, calls)
+    case *ast.BlockStatement:
+        for _, s := range node.Statements {
+            collectCalls(s, calls)
+        }
+    case *ast.TryCatchStatement:
+        collectCalls(node.Try, calls)
+        collectCalls(node.Catch, calls)
+    case *ast.ReturnStatement:
+        collectCalls(node.Result, calls)
+    case *ast.ConstStatement:
+        collectCalls(node.Initializer, calls)
+    case *ast.ExpressionStatement:
+        collectCalls(node.Expr, calls)
+    case *ast.InfixExpression:
+        collectCalls(node.Lhs, calls)
+        collectCalls(node.Rhs, calls)
+    case *ast.ConditionalExpression:
+        collectCalls(node.Then, calls)
+        collectCalls(node.Else, calls)
+    case *ast.MatchExpression:
+        for _, arm := range node.Arms {
+            collectCalls(arm.Body, calls)
+        }
+    case *ast.CallExpression:
+        *calls = append(*calls, node.IsTailCall)
+        for _, a := range node.Arguments {
+            collectCalls(a, calls)
+        }
+    }
+}
+
+func TestParameterAndArgumentErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"fun(...a, b) {};", "line: 1, column: 9, Literal: \"\" [,]: the variadic parameter has to be the last parameter"},
+        {"fun(a = 1, b) {};", "line: 1, column: 12, Literal: \"b\" [IDENTIFIER]: a parameter without default value must not follow a parameter with default value"},
+        {"fun(...[a]) {};", "line: 1, column: 8, Literal: \"\" [[]: expected identifier"},
+        {"f(a: 1, 2);", "line: 1, column: 9, Literal: \"2\" [INT]: positional arguments must not follow named arguments"},
+    }
+
+    for _, tt := range tests {
+        s := scanner.New(tt.input)
+        p := New(s, "test")
+        _, err := p.Parse()
+        if len(err) == 0 {
+            t.Fatalf("expected an error \"%s\" but got none", tt.expected)
+        }
+        if err[0].Error() != tt.expected {
+            t.Fatalf("Expected error msg to be \"%s\" but got \"%s\"", tt.expected, err[0].Error())
+        }
+    }
+}
+
+func TestNumberLiteralErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"0b102;", "line: 1, column: 1, Literal: \"0b102\" [INT]: invalid integer-literal 0b102: invalid syntax"},
+        {"0x;", "line: 1, column: 1, Literal: \"0x\" [INT]: invalid integer-literal 0x: invalid syntax"},
+        {"0x8000_0000_0000_0000;", "line: 1, column: 1, Literal: \"0x8000_0000_0000_0000\" [INT]: invalid integer-literal 0x8000_0000_0000_0000: value out of range"},
+        {"0o8n;", "line: 1, column: 1, Literal: \"0o8n\" [BIGINT]: invalid bigint-literal 0o8n"},
+    }
+
+    for _, tt := range tests {
+        s := scanner.New(tt.input)
+        p := New(s, "test")
+        _, err := p.Parse()
+        if len(err) == 0 {
+            t.Fatalf("expected an error \"%s\" but got none", tt.expected)
+        }
+        if err[0].Error() != tt.expected {
+            t.Fatalf("Expected error msg to be \"%s\" but got \"%s\"", tt.expected, err[0].Error())
+        }
+    }
+}
+
+func TestExportStatement(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+        exports []string
+    }{
+        {"export let a = 1;", "export let a = 1;", []string{"a"}},
+        {"export const [a, _, b] = c;", "export const [a, _, b] = c;", []string{"a", "b"}},
+        {"export fun f(x) { return x; }", "export fun f(x){ return x; }", []string{"f"}},
+    }
+
+    for _, tt := range tests {
+        s := scanner.New(tt.input)
+        p := New(s, "test")
+        program, err := p.Parse()
+        handleParserErrors(t, err)
+
+        exports, ok := program.Exports()
+        if !ok {
+            t.Fatalf("expected %s to have exports", tt.input)
+        }
+        if strings.Join(exports, ",") != strings.Join(tt.exports, ",") {
+            t.Fatalf("expected exports %v but got %v", tt.exports, exports)
+        }
+        if program.Statements[0].String() != tt.expected {
+            t.Fatalf("expected %s but got %s", tt.expected, program.Statements[0].String())
+        }
+    }
+}
+
+func TestExportErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"export 1;", "line: 1, column: 8, Literal: \"1\" [INT]: expected let, const or a function declaration after export"},
+        {"if (true) { export let a = 1; }", "line: 1, column: 13, Literal: \"\" [EXPORT]: export is only allowed at module level"},
+    }
+
+    for _, tt := range tests {
+        s := scanner.New(tt.input)
+        p := New(s, "test")
+        _, err := p.Parse()
+        if len(err) == 0 {
+            t.Fatalf("expected an error \"%s\" but got none", tt.expected)
+        }
+        if err[0].Error() != tt.expected {
+            t.Fatalf("Expected error msg to be \"%s\" but got \"%s\"", tt.expected, err[0].Error())
+        }
+    }
+}
+
+func TestTypeAnnotations(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"let a: int = 1;", "let a: int = 1;"},
+        {"const name: string? = null;", "const name: string | null = null;"},
+        {"let value: int | float | null = x;", "let value: int | float | null = x;"},
+        {"const f = fun(a: int, b: any = 2, ...rest: string): bool { return true; };", "const f = fun(a: int, b: any = 2, ...rest: string): bool{ return true; };"},
+        {"fun g(x, [y, z]): array? { return [x, y, z]; }", "fun g(x, [y, z]): array | null{ return [x, y, z]; }"},
+        {"let c = a ? b : d;", "let c = (a?b:d);"},
+    }
+
+    for _, tt := range tests {
+        program := parseProgram(t, tt.input)
+        handleProgramLength(t, program, 1)
+        if program.Statements[0].String() != tt.expected {
+            t.Fatalf("expected %s but got %s", tt.expected, program.Statements[0].String())
+        }
+    }
+}
+
+func TestDocComments(t *testing.T) {
+    input := `/// Module doc.
+
+/// Adds.
+fun add(a, b) { return a + b; }
+/// A constant.
+export const C = 1;
+/// Exported function.
+export const f = fun() {};
+/// Variable.
+let v = fun() {};
+`
+    program := parseProgram(t, input)
+    handleProgramLength(t, program, 4)
+    if program.Doc != "Module doc." {
+        t.Fatalf("expected the module doc but got %q", program.Doc)
+    }
+    if doc := program.Statements[0].(*ast.FunctionDeclarationStatement).Function.Doc; doc != "Adds." {
+        t.Fatalf("expected the doc of add but got %q", doc)
+    }
+    constant := program.Statements[1].(*ast.ExportStatement).Statement.(*ast.ConstStatement)
+    if constant.Doc != "A constant." {
+        t.Fatalf("expected the doc of C but got %q", constant.Doc)
+    }
+    function := program.Statements[2].(*ast.ExportStatement).Statement.(*ast.ConstStatement)
+    if function.Doc != "Exported function." || function.Initializer.(*ast.FunctionLiteralExpression).Doc != "Exported function." {
+        t.Fatalf("expected the doc of f on the statement and the function but got %q", function.Doc)
+    }
+    variable := program.Statements[3].(*ast.LetStatement)
+    if variable.Doc != "Variable." || variable.Initializer.(*ast.FunctionLiteralExpression).Doc != "Variable." {
+        t.Fatalf("expected the doc of v on the statement and the function but got %q", variable.Doc)
+    }
+}
+
+func TestTypeAnnotationErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        expected string
+    }{
+        {"let a: integer = 1;", "line: 1, column: 8, Literal: \"integer\" [IDENTIFIER]: expected type name"},
+        {"const f = fun(a: 1) {};", "line: 1, column: 18, Literal: \"1\" [INT]: expected type name"},
+        {"const f = fun(a): int | {};", "line: 1, column: 25, Literal: \"\" [{]: expected type name"},
+        {"let [a, b]: array = c;", "line: 1, column: 11, Literal: \"\" [:]: Expected ="},
+    }
+
+    for _, tt := range tests {
+        s := scanner.New(tt.input)
+        p := New(s, "test")
+        _, err := p.Parse()
+        if len(err) == 0 {
+            t.Fatalf("expected an error \"%s\" but got none", tt.expected)
+        }
+        if err[0].Error() != tt.expected {
+            t.Fatalf("Expected error msg to be \"%s\" but got \"%s\"", tt.expected, err[0].Error())
+        }
+    }
+}
+
 func TestIdentifierExpression(t *testing.T) {
     tests := []struct {
         input string
@@ -647,6 +1126,22 @@ func TestExpression(t *testing.T) {
         {"a /= b;", "(a/=b)"},
         {"a *= b;", "(a*=b)"},
         {"a %= b;", "(a%=b)"},
+        {"a is b == c is d;", "(((a is b)==c) is d)"},
+        {"a is b && c;", "((a is b)&&c)"},
+        {"a ** b ** c;", "(a**(b**c))"},
+        {"-a ** b * c;", "((-(a**b))*c)"},
+        {"a ~/ b + c % d;", "((a~/b)+(c%d))"},
+        {"a.b ** 2;", "((a[\"b\"])**2)"},
+        {"123456789012345678901234567890n + 1.50d;", "(123456789012345678901234567890n+1.50d)"},
+        {"a | b ^ c & d << 1 + 2;", "(a|(b^(c&(d<<(1+2)))))"},
+        {"a & 1 == 0;", "((a&1)==0)"},
+        {"~a & b;", "((~a)&b)"},
+        {"a >> b < c;", "((a>>b)<c)"},
+        {"a <<= b |= 1;", "(a<<=(b|=1))"},
+        {"0xff + 0b1010 + 0o17 + 1_000;", "(((255+10)+15)+1000)"},
+        {"0xffn;", "255n"},
+        {"1_000.25d;", "1000.25d"},
+        {"#[1, #{\"a\": [2]}];", "#[1, #{\"a\": [2]}]"},
         {"something = a == b ? 1 : \"hello world\";", "(something=((a==b)?1:\"hello world\"))"},
         {"add = fun(a, b) { return a + b; };", "(add=fun(a, b){ return (a+b); })"},
     }
@@ -0,0 +1,322 @@
+package parser
+
+import (
+    "language/ast"
+    "language/token"
+)
+
+var patternTypeNames = map[string]bool{
+    "int": true,
+    "float": true,
+    "bigint": true,
+    "decimal": true,
+    "bool": true,
+    "string": true,
+    "null": true,
+    "array": true,
+    "hash": true,
+    "function": true,
+    "set": true,
+    "deque": true,
+    "range": true,
+    "iterator": true,
+    "module": true,
+    "any": true,
+}
+
+func (p *Parser) matchExpr() ast.Expression {
+    matchToken := p.peek()
+    if !p.match(token.MATCH) {
+        p.pushNewError("expected match expression", p.peek())
+        return nil
+    }
+
+    subject := p.expression()
+    if subject == nil {
+        return nil
+    }
+
+    if !p.match(token.LBRACE) {
+        p.pushNewError("expected {", p.peek())
+        return nil
+    }
+
+    arms := []*ast.MatchArm{}
+    for !p.is(token.RBRACE) {
+        if p.isAtEnd() {
+            p.pushNewError("Unexpected end of file", p.peek())
+            return nil
+        }
+        arm := p.matchArm()
+        if arm == nil {
+            return nil
+        }
+        arms = append(arms, arm)
+        if !p.is(token.RBRACE) {
+            p.match(token.COMMA)
+        }
+    }
+    p.advance()
+
+    p.warnUnreachableArms(arms)
+
+    return &ast.MatchExpression{Subject: subject, Arms: arms, PosInfo: p.tokToPos(matchToken)}
+}
+
+func (p *Parser) matchArm() *ast.MatchArm {
+    armToken := p.peek()
+    pattern := p.pattern()
+    if pattern == nil {
+        return nil
+    }
+
+    var guard ast.Expression
+    if p.match(token.IF) {
+        guard = p.expression()
+        if guard == nil {
+            return nil
+        }
+    }
+
+    if !p.match(token.ARROW) {
+        p.pushNewError("expected =>", p.peek())
+        return nil
+    }
+
+    var body ast.Statement
+    if p.is(token.LBRACE) {
+        block := p.block()
+        if block == nil {
+            return nil
+        }
+        body = block
+    } else {
+        expr := p.expression()
+        if expr == nil {
+            return nil
+        }
+        body = &ast.ExpressionStatement{Expr: expr, PosInfo: expr.Position()}
+    }
+
+    return &ast.MatchArm{Pattern: pattern, Guard: guard, Body: body, PosInfo: p.tokToPos(armToken)}
+}
+
+// warnUnreachableArms warns about arms following an arm which matches everything and about repeated literal patterns
+func (p *Parser) warnUnreachableArms(arms []*ast.MatchArm) {
+    seenLiterals := make(map[string]bool)
+    for i, arm := range arms {
+        if arm.Guard != nil {
+            continue
+        }
+        if isIrrefutable(arm.Pattern) {
+            for _, unreachable := range arms[i+1:] {
+                p.pushNewWarning("unreachable match arm, the arm before matches every value", unreachable.Position())
+            }
+            return
+        }
+        if literal, ok := arm.Pattern.(*ast.LiteralPattern); ok {
+            if seenLiterals[literal.String()] {
+                p.pushNewWarning("unreachable match arm, the same literal is matched before", arm.Position())
+            }
+            seenLiterals[literal.String()] = true
+        }
+    }
+}
+
+func isIrrefutable(pattern ast.Pattern) bool {
+    switch pattern.(type) {
+    case *ast.WildcardPattern, *ast.IdentifierPattern:
+        return true
+    }
+    return false
+}
+
+func (p *Parser) pattern() ast.Pattern {
+    tok := p.peek()
+    switch tok.Type {
+    case token.IDENTIFIER:
+        p.advance()
+        if p.match(token.COLON) {
+            typeName := p.advance()
+            if typeName.Type == token.NULL {
+                typeName.Literal = "null"
+            } else if typeName.Type != token.IDENTIFIER || !patternTypeNames[typeName.Literal] {
+                p.pushNewError("expected type name", typeName)
+                return nil
+            }
+            return &ast.TypePattern{Name: tok.Literal, TypeName: typeName.Literal, PosInfo: p.tokToPos(tok)}
+        }
+        if tok.Literal == "_" {
+            return &ast.WildcardPattern{PosInfo: p.tokToPos(tok)}
+        }
+        return &ast.IdentifierPattern{Name: tok.Literal, PosInfo: p.tokToPos(tok)}
+    case token.LBRACKET:
+        return p.arrayPattern()
+    case token.LBRACE:
+        return p.hashPattern()
+    case token.INT, token.FLOAT, token.BIGINT, token.DECIMAL, token.STRING, token.TRUE, token.FALSE, token.NULL, token.SUB:
+        return p.literalPattern()
+    }
+    p.pushNewError("expected pattern", tok)
+    return nil
+}
+
+// isDestructuringPattern checks if the next tokens start a pattern which is allowed where a name can be bound
+func (p *Parser) isDestructuringPattern() bool {
+    return p.is(token.LBRACKET) || p.is(token.LBRACE)
+}
+
+func (p *Parser) destructuringPattern() ast.Pattern {
+    if p.is(token.LBRACKET) {
+        return p.arrayPattern()
+    }
+    return p.hashPattern()
+}
+
+func (p *Parser) literalPattern() ast.Pattern {
+    tok := p.peek()
+    start := p.patternLiteral()
+    if start == nil {
+        return nil
+    }
+
+    if p.is(token.RANGE) || p.is(token.RANGEINCLUSIVE) {
+        inclusive := p.advance().Type == token.RANGEINCLUSIVE
+        end := p.patternLiteral()
+        if end == nil {
+            return nil
+        }
+        return &ast.RangePattern{Start: start, End: end, Inclusive: inclusive, PosInfo: p.tokToPos(tok)}
+    }
+    return &ast.LiteralPattern{Value: start, PosInfo: p.tokToPos(tok)}
+}
+
+func (p *Parser) patternLiteral() ast.Expression {
+    switch p.peek().Type {
+    case token.INT:
+        return p.parseInt()
+    case token.FLOAT:
+        return p.parseFloat()
+    case token.BIGINT:
+        return p.parseBigInt()
+    case token.DECIMAL:
+        return p.parseDecimal()
+    case token.STRING:
+        return p.parseString()
+    case token.TRUE, token.FALSE:
+        return p.parseBool()
+    case token.NULL:
+        return p.parseNull()
+    case token.SUB:
+        op := p.advance()
+        var value ast.Expression
+        if p.is(token.INT) {
+            value = p.parseInt()
+        } else if p.is(token.FLOAT) {
+            value = p.parseFloat()
+        } else if p.is(token.BIGINT) {
+            value = p.parseBigInt()
+        } else if p.is(token.DECIMAL) {
+            value = p.parseDecimal()
+        } else {
+            p.pushNewError("expected number", p.peek())
+            return nil
+        }
+        if value == nil {
+            return nil
+        }
+        return &ast.UnaryExpression{Op: op, Rhs: value, PosInfo: p.tokToPos(op)}
+    }
+    p.pushNewError("expected literal", p.peek())
+    return nil
+}
+
+func (p *Parser) arrayPattern() ast.Pattern {
+    bracketToken := p.peek()
+    if !p.match(token.LBRACKET) {
+        p.pushNewError("expected array pattern", p.peek())
+        return nil
+    }
+
+    elements := []ast.Pattern{}
+    for !p.is(token.RBRACKET) {
+        if p.match(token.ELLIPSIS) {
+            rest := p.advance()
+            if rest.Type != token.IDENTIFIER {
+                p.pushNewError("expected identifier", rest)
+                return nil
+            }
+            if !p.match(token.RBRACKET) {
+                p.pushNewError("the rest pattern has to be the last element", p.peek())
+                return nil
+            }
+            return &ast.ArrayPattern{Elements: elements, HasRest: true, Rest: rest.Literal, PosInfo: p.tokToPos(bracketToken)}
+        }
+
+        element := p.pattern()
+        if element == nil {
+            return nil
+        }
+        elements = append(elements, element)
+
+        if !p.is(token.RBRACKET) && !p.match(token.COMMA) {
+            p.pushNewError("expected , or ]", p.peek())
+            return nil
+        }
+    }
+    p.advance()
+
+    return &ast.ArrayPattern{Elements: elements, PosInfo: p.tokToPos(bracketToken)}
+}
+
+func (p *Parser) hashPattern() ast.Pattern {
+    braceToken := p.peek()
+    if !p.match(token.LBRACE) {
+        p.pushNewError("expected hash pattern", p.peek())
+        return nil
+    }
+
+    keys := []ast.Expression{}
+    values := []ast.Pattern{}
+    for !p.is(token.RBRACE) {
+        keyToken := p.peek()
+        var key ast.Expression
+        switch keyToken.Type {
+        case token.IDENTIFIER:
+            p.advance()
+            key = &ast.StringLiteralExpression{Value: keyToken.Literal, PosInfo: p.tokToPos(keyToken)}
+        case token.STRING, token.INT, token.TRUE, token.FALSE:
+            key = p.patternLiteral()
+        default:
+            p.pushNewError("expected key of hash pattern", keyToken)
+            return nil
+        }
+        if key == nil {
+            return nil
+        }
+
+        var value ast.Pattern
+        if p.match(token.COLON) {
+            value = p.pattern()
+            if value == nil {
+                return nil
+            }
+        } else if keyToken.Type == token.IDENTIFIER {
+            // {name} is a shorthand for {name: name}
+            value = &ast.IdentifierPattern{Name: keyToken.Literal, PosInfo: p.tokToPos(keyToken)}
+        } else {
+            p.pushNewError("expected :", p.peek())
+            return nil
+        }
+        keys = append(keys, key)
+        values = append(values, value)
+
+        if !p.is(token.RBRACE) && !p.match(token.COMMA) {
+            p.pushNewError("expected , or }", p.peek())
+            return nil
+        }
+    }
+    p.advance()
+
+    return &ast.HashPattern{Keys: keys, Values: values, PosInfo: p.tokToPos(braceToken)}
+}
@@ -8,6 +8,8 @@ import (
 func (p *Parser) registerPrefixFunctions() {
     p.prefixParseFunctions[token.INT] = p.parseInt
     p.prefixParseFunctions[token.FLOAT] = p.parseFloat
+    p.prefixParseFunctions[token.BIGINT] = p.parseBigInt
+    p.prefixParseFunctions[token.DECIMAL] = p.parseDecimal
     p.prefixParseFunctions[token.STRING] = p.parseString
     p.prefixParseFunctions[token.NULL] = p.parseNull
     p.prefixParseFunctions[token.TRUE] = p.parseBool
@@ -15,11 +17,14 @@ func (p *Parser) registerPrefixFunctions() {
     p.prefixParseFunctions[token.IDENTIFIER] = p.parseIdentifier
     p.prefixParseFunctions[token.LBRACKET] = p.parseArray
     p.prefixParseFunctions[token.LBRACE] = p.parseHash
+    p.prefixParseFunctions[token.FROZEN] = p.parseFrozen
     p.prefixParseFunctions[token.SUB] = p.unary
     p.prefixParseFunctions[token.ADD] = p.unary
     p.prefixParseFunctions[token.NEG] = p.unary
+    p.prefixParseFunctions[token.BITNOT] = p.unary
     p.prefixParseFunctions[token.LPAREN] = p.grouping
     p.prefixParseFunctions[token.FUN] = p.funcLit
+    p.prefixParseFunctions[token.MATCH] = p.matchExpr
 }
 
 func (p *Parser) registerInfixFunctions() {
@@ -28,10 +33,18 @@ func (p *Parser) registerInfixFunctions() {
     p.infixParseFunctions[token.MULT] = p.infix
     p.infixParseFunctions[token.DIV] = p.infix
     p.infixParseFunctions[token.MOD] = p.infix
+    p.infixParseFunctions[token.POW] = p.infix
+    p.infixParseFunctions[token.FLOORDIV] = p.infix
+    p.infixParseFunctions[token.BITAND] = p.infix
+    p.infixParseFunctions[token.BITOR] = p.infix
+    p.infixParseFunctions[token.BITXOR] = p.infix
+    p.infixParseFunctions[token.SHL] = p.infix
+    p.infixParseFunctions[token.SHR] = p.infix
     p.infixParseFunctions[token.AND] = p.infix
     p.infixParseFunctions[token.OR] = p.infix
     p.infixParseFunctions[token.EQ] = p.infix
     p.infixParseFunctions[token.NEQ] = p.infix
+    p.infixParseFunctions[token.IS] = p.infix
     p.infixParseFunctions[token.LT] = p.infix
     p.infixParseFunctions[token.GT] = p.infix
     p.infixParseFunctions[token.LE] = p.infix
@@ -42,10 +55,16 @@ func (p *Parser) registerInfixFunctions() {
     p.infixParseFunctions[token.MULTASSIGN] = p.infix
     p.infixParseFunctions[token.DIVASSIGN] = p.infix
     p.infixParseFunctions[token.MODASSIGN] = p.infix
+    p.infixParseFunctions[token.BITANDASSIGN] = p.infix
+    p.infixParseFunctions[token.BITORASSIGN] = p.infix
+    p.infixParseFunctions[token.BITXORASSIGN] = p.infix
+    p.infixParseFunctions[token.SHLASSIGN] = p.infix
+    p.infixParseFunctions[token.SHRASSIGN] = p.infix
     p.infixParseFunctions[token.DOT] = p.property
     p.infixParseFunctions[token.LPAREN] = p.call
     p.infixParseFunctions[token.LBRACKET] = p.index
-    p.infixParseFunctions[token.RANGE] = p.infix
+    p.infixParseFunctions[token.RANGE] = p.rangeExpr
+    p.infixParseFunctions[token.RANGEINCLUSIVE] = p.rangeExpr
     p.infixParseFunctions[token.NULLCOAL] = p.infix
     p.infixParseFunctions[token.QUESTION] = p.conditional
 }
@@ -73,15 +92,21 @@ const (
     CONJUNCTION
     EQUALS
     COMPARE
+    BITOR
+    BITXOR
+    BITAND
+    SHIFT
     SUM
     PRODUCT
     RANGE
     PREFIX
+    POWER
     POSTFIX
 )
 
 var precedences = map[token.TokenType]int {
     token.RANGE: RANGE,
+    token.RANGEINCLUSIVE: RANGE,
     token.NULLCOAL: NULLCOALESCING,
     token.ASSIGN: ASSIGN,
     token.ADDASSIGN: ASSIGN,
@@ -89,20 +114,33 @@ var precedences = map[token.TokenType]int {
     token.MULTASSIGN: ASSIGN,
     token.DIVASSIGN: ASSIGN,
     token.MODASSIGN: ASSIGN,
+    token.BITANDASSIGN: ASSIGN,
+    token.BITORASSIGN: ASSIGN,
+    token.BITXORASSIGN: ASSIGN,
+    token.SHLASSIGN: ASSIGN,
+    token.SHRASSIGN: ASSIGN,
     token.QUESTION: TERNARY,
     token.AND: CONJUNCTION,
     token.OR: DISJUNCTION,
     token.EQ: EQUALS,
     token.NEQ: EQUALS,
+    token.IS: EQUALS,
     token.LT: COMPARE,
     token.GT: COMPARE,
     token.LE: COMPARE,
     token.GE: COMPARE,
+    token.BITOR: BITOR,
+    token.BITXOR: BITXOR,
+    token.BITAND: BITAND,
+    token.SHL: SHIFT,
+    token.SHR: SHIFT,
     token.ADD: SUM,
     token.SUB: SUM,
     token.MULT: PRODUCT,
     token.DIV: PRODUCT,
     token.MOD: PRODUCT,
+    token.FLOORDIV: PRODUCT,
+    token.POW: POWER,
     token.DOT: POSTFIX,
     token.LPAREN: POSTFIX,
     token.LBRACKET: POSTFIX,
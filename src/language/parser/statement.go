@@ -1,6 +1,9 @@
 package parser
 
 import (
+    "path/filepath"
+    "strings"
+    "unicode"
     "language/ast"
     "language/token"
 )
@@ -10,9 +13,15 @@ func (p *Parser) parseModuleLevelStmt() ast.Statement {
     switch nextType {
     case token.IMPORT:
         return p.parseImport()
-    default:
-        return p.parseStmt()
+    case token.EXPORT:
+        return p.parseExport()
+    case token.IDENTIFIER:
+        // from is no keyword, so that it can still be used as a name
+        if p.peek().Literal == "from" && p.peek2().Type == token.STRING {
+            return p.parseFromImport()
+        }
     }
+    return p.parseStmt()
 }
 
 func (p *Parser) parseStmt() ast.Statement {
@@ -28,12 +37,21 @@ func (p *Parser) parseStmt() ast.Statement {
         return p.parseLoop()
     case token.RETURN:
         return p.parseReturn()
+    case token.YIELD:
+        return p.parseYield()
     case token.BREAK:
         return p.parseBreak()
     case token.CONTINUE:
         return p.parseContinue()
     case token.TRY:
         return p.parseTryCatch()
+    case token.FUN:
+        if p.peek2().Type == token.IDENTIFIER {
+            return p.parseFunctionDeclaration()
+        }
+    case token.EXPORT:
+        p.pushNewError("export is only allowed at module level", p.peek())
+        return nil
     }
     return p.parseExprStmt()
 }
@@ -44,11 +62,24 @@ func (p *Parser) parseLet() *ast.LetStatement {
         p.pushNewError("Expected let statement", p.peek())
     }
 
-    name := p.advance()
-    if name.Type != token.IDENTIFIER {
+    var pattern ast.Pattern
+    name := p.peek()
+    if p.isDestructuringPattern() {
+        pattern = p.destructuringPattern()
+        if pattern == nil {
+            return nil
+        }
+    } else if p.advance().Type != token.IDENTIFIER {
         p.pushNewError("Expected an identifier", name)
         return nil
     }
+    var annotation *ast.TypeAnnotation
+    if pattern == nil {
+        var ok bool
+        if annotation, ok = p.optionalTypeAnnotation(); !ok {
+            return nil
+        }
+    }
 
     if !p.match(token.ASSIGN) {
         p.pushNewError("Expected =", p.peek())
@@ -62,7 +93,10 @@ func (p *Parser) parseLet() *ast.LetStatement {
 
     p.match(token.SEMICOLON)
 
-    return &ast.LetStatement{Name: name.Literal, Initializer: expr, PosInfo: p.tokToPos(letToken)}
+    if function, ok := expr.(*ast.FunctionLiteralExpression); ok && function.Doc == "" {
+        function.Doc = letToken.Doc
+    }
+    return &ast.LetStatement{Name: name.Literal, Pattern: pattern, Type: annotation, Initializer: expr, Doc: letToken.Doc, PosInfo: p.tokToPos(letToken)}
 }
 
 func (p *Parser) parseConst() *ast.ConstStatement {
@@ -71,11 +105,24 @@ func (p *Parser) parseConst() *ast.ConstStatement {
         p.pushNewError("Expected const statement", p.peek())
     }
 
-    name := p.advance()
-    if name.Type != token.IDENTIFIER {
+    var pattern ast.Pattern
+    name := p.peek()
+    if p.isDestructuringPattern() {
+        pattern = p.destructuringPattern()
+        if pattern == nil {
+            return nil
+        }
+    } else if p.advance().Type != token.IDENTIFIER {
         p.pushNewError("Expected an identifier", name)
         return nil
     }
+    var annotation *ast.TypeAnnotation
+    if pattern == nil {
+        var ok bool
+        if annotation, ok = p.optionalTypeAnnotation(); !ok {
+            return nil
+        }
+    }
 
     if !p.match(token.ASSIGN) {
         p.pushNewError("Expected =", p.peek())
@@ -89,7 +136,10 @@ func (p *Parser) parseConst() *ast.ConstStatement {
 
     p.match(token.SEMICOLON)
 
-    return &ast.ConstStatement{Name: name.Literal, Initializer: expr, PosInfo: p.tokToPos(constToken)}
+    if function, ok := expr.(*ast.FunctionLiteralExpression); ok && function.Doc == "" {
+        function.Doc = constToken.Doc
+    }
+    return &ast.ConstStatement{Name: name.Literal, Pattern: pattern, Type: annotation, Initializer: expr, Doc: constToken.Doc, PosInfo: p.tokToPos(constToken)}
 }
 
 func (p *Parser) parseIf() *ast.IfStatement {
@@ -148,9 +198,21 @@ func (p *Parser) parseLoop() ast.Statement {
         }
 
         return &ast.WhileStatement{Head: head, Body: block}
-    } else if p.are(token.IDENTIFIER, token.IN) {
-        name := p.advance()
-        p.advance()
+    } else if p.are(token.IDENTIFIER, token.IN) || p.isDestructuringPattern() {
+        name := p.peek()
+        var pattern ast.Pattern
+        if p.isDestructuringPattern() {
+            pattern = p.destructuringPattern()
+            if pattern == nil {
+                return nil
+            }
+        } else {
+            p.advance()
+        }
+        if !p.match(token.IN) {
+            p.pushNewError("expected in", p.peek())
+            return nil
+        }
         rangeExpr := p.expression()
         if rangeExpr == nil {
             return nil
@@ -161,15 +223,21 @@ func (p *Parser) parseLoop() ast.Statement {
             return nil
         }
 
-        return &ast.RangeLoopStatement{Name: name.Literal, RangeExpr: rangeExpr, Body: block, PosInfo: p.tokToPos(loopToken)}
+        return &ast.RangeLoopStatement{Name: name.Literal, Pattern: pattern, RangeExpr: rangeExpr, Body: block, PosInfo: p.tokToPos(loopToken)}
     } else if p.are(token.IDENTIFIER, token.COMMA) {
         idxName := p.advance()
         p.advance()
-        if !p.is(token.IDENTIFIER) {
-            p.pushNewError("expected identifier", p.peek())
+        elementName := p.peek()
+        var elementPattern ast.Pattern
+        if p.isDestructuringPattern() {
+            elementPattern = p.destructuringPattern()
+            if elementPattern == nil {
+                return nil
+            }
+        } else if p.advance().Type != token.IDENTIFIER {
+            p.pushNewError("expected identifier", elementName)
             return nil
         }
-        elementName := p.advance()
         if !p.match(token.IN) {
             p.pushNewError("expected in", p.peek())
             return nil
@@ -184,7 +252,7 @@ func (p *Parser) parseLoop() ast.Statement {
             return nil
         }
 
-        return &ast.KVRangeLoopStatement{IndexName: idxName.Literal, ElementName: elementName.Literal, RangeExpr: rangeExpr, Body: block, PosInfo: p.tokToPos(loopToken)}
+        return &ast.KVRangeLoopStatement{IndexName: idxName.Literal, ElementName: elementName.Literal, ElementPattern: elementPattern, RangeExpr: rangeExpr, Body: block, PosInfo: p.tokToPos(loopToken)}
     } else {
         head := p.expression()
 
@@ -217,12 +285,62 @@ func (p *Parser) parseReturn() *ast.ReturnStatement {
         return &ast.ReturnStatement{Result: &ast.NullLiteralExpression{PosInfo: p.tokToPos(returnToken)}, PosInfo: p.tokToPos(returnToken)}
     }
     result := p.expression()
+    if result == nil {
+        return nil
+    }
+    // errors of calls in a try block have to be caught, so they can not replace the frame of the function
+    if !p.isInTry() {
+        markTailCalls(result)
+    }
 
     p.match(token.SEMICOLON)
 
     return &ast.ReturnStatement{Result: result, PosInfo: p.tokToPos(returnToken)}
 }
 
+// markTailCalls marks the calls whose result is returned directly
+func markTailCalls(expr ast.Expression) {
+    switch expr := expr.(type) {
+    case *ast.CallExpression:
+        expr.IsTailCall = true
+    case *ast.ConditionalExpression:
+        markTailCalls(expr.Then)
+        markTailCalls(expr.Else)
+    case *ast.MatchExpression:
+        for _, arm := range expr.Arms {
+            if body, ok := arm.Body.(*ast.ExpressionStatement); ok {
+                markTailCalls(body.Expr)
+            }
+        }
+    }
+}
+
+func (p *Parser) parseYield() *ast.YieldStatement {
+    yieldToken := p.peek()
+    if !p.match(token.YIELD) {
+        p.pushNewError("expected yield statement", p.peek())
+        return nil
+    }
+
+    if !p.isInFunctionDefinition() {
+        p.pushNewError("yield is only allowed in function definitions", p.peek())
+        return nil
+    }
+    p.markGenerator()
+
+    if p.match(token.SEMICOLON) {
+        return &ast.YieldStatement{Value: &ast.NullLiteralExpression{PosInfo: p.tokToPos(yieldToken)}, PosInfo: p.tokToPos(yieldToken)}
+    }
+    value := p.expression()
+    if value == nil {
+        return nil
+    }
+
+    p.match(token.SEMICOLON)
+
+    return &ast.YieldStatement{Value: value, PosInfo: p.tokToPos(yieldToken)}
+}
+
 func (p *Parser) parseBreak() *ast.BreakStatement {
     if !p.isInLoop() {
         p.pushNewError("Break is only allowed inside a loop", p.peek())
@@ -263,7 +381,9 @@ func (p *Parser) parseTryCatch() *ast.TryCatchStatement {
         p.pushNewError("Expected try", p.peek())
         return nil
     }
+    p.enterTry()
     tryBlock := p.block()
+    p.exitTry()
     if tryBlock == nil {
         return nil
     }
@@ -314,6 +434,16 @@ func (p *Parser) block() *ast.BlockStatement {
     return &ast.BlockStatement{Statements: stmts, PosInfo: p.tokToPos(braceToken)}
 }
 
+func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclarationStatement {
+    funToken := p.peek()
+    function, ok := p.funcLit().(*ast.FunctionLiteralExpression)
+    if !ok || function == nil {
+        return nil
+    }
+    p.match(token.SEMICOLON)
+    return &ast.FunctionDeclarationStatement{Function: function, PosInfo: p.tokToPos(funToken)}
+}
+
 func (p *Parser) parseExprStmt() *ast.ExpressionStatement {
     expr := p.expression()
 
@@ -324,13 +454,107 @@ func (p *Parser) parseExprStmt() *ast.ExpressionStatement {
     return &ast.ExpressionStatement{Expr: expr, PosInfo: expr.Position()}
 }
 
-func (p *Parser) parseImport() *ast.ImportStatement {
+// parseExport parses an exported let, const or function declaration, exports are only allowed at module level
+func (p *Parser) parseExport() ast.Statement {
+    exportToken := p.peek()
+    if !p.match(token.EXPORT) {
+        p.pushNewError("expected export", p.peek())
+        return nil
+    }
+
+    var stmt ast.Statement
+    switch p.peek().Type {
+    case token.LET:
+        let := p.parseLet()
+        if let == nil {
+            return nil
+        }
+        stmt = let
+    case token.CONST:
+        constant := p.parseConst()
+        if constant == nil {
+            return nil
+        }
+        stmt = constant
+    case token.FUN:
+        if p.peek2().Type != token.IDENTIFIER {
+            break
+        }
+        declaration := p.parseFunctionDeclaration()
+        if declaration == nil {
+            return nil
+        }
+        stmt = declaration
+    }
+    if stmt == nil {
+        p.pushNewError("expected let, const or a function declaration after export", p.peek())
+        return nil
+    }
+
+    setDoc(stmt, exportToken.Doc)
+    return &ast.ExportStatement{Statement: stmt, PosInfo: p.tokToPos(exportToken)}
+}
+
+// setDoc attaches the doc comment before the export keyword to the exported declaration
+func setDoc(stmt ast.Statement, doc string) {
+    if doc == "" {
+        return
+    }
+    switch stmt := stmt.(type) {
+    case *ast.LetStatement:
+        stmt.Doc = doc
+        if function, ok := stmt.Initializer.(*ast.FunctionLiteralExpression); ok {
+            function.Doc = doc
+        }
+    case *ast.ConstStatement:
+        stmt.Doc = doc
+        if function, ok := stmt.Initializer.(*ast.FunctionLiteralExpression); ok {
+            function.Doc = doc
+        }
+    case *ast.FunctionDeclarationStatement:
+        stmt.Function.Doc = doc
+    }
+}
+
+func (p *Parser) parseImport() ast.Statement {
     importToken := p.peek()
     if !p.match(token.IMPORT) {
         p.pushNewError("expected import", p.peek())
         return nil
     }
 
+    if p.match(token.LPAREN) {
+        group := &ast.ImportGroupStatement{Imports: []*ast.ImportStatement{}, PosInfo: p.tokToPos(importToken)}
+        for !p.is(token.RPAREN) && !p.isAtEnd() {
+            imp := p.parseModuleImport(p.peek())
+            if imp == nil {
+                return nil
+            }
+            group.Imports = append(group.Imports, imp)
+            if !p.match(token.COMMA) {
+                break
+            }
+        }
+        if !p.match(token.RPAREN) {
+            p.pushNewError("expected )", p.peek())
+            return nil
+        }
+        p.match(token.SEMICOLON)
+        return group
+    }
+
+    imp := p.parseModuleImport(importToken)
+    if imp == nil {
+        return nil
+    }
+
+    p.match(token.SEMICOLON)
+
+    return imp
+}
+
+// parseModuleImport parses "path" as name, the name defaults to the file name of the path without its extension
+func (p *Parser) parseModuleImport(importToken token.Token) *ast.ImportStatement {
     path := p.advance()
     if path.Type != token.STRING {
         p.pushNewError("expected string", path)
@@ -338,8 +562,12 @@ func (p *Parser) parseImport() *ast.ImportStatement {
     }
 
     if !p.match(token.AS) {
-        p.pushNewError("expected as", p.peek())
-        return nil
+        name, ok := moduleName(path.Literal)
+        if !ok {
+            p.pushNewError("cannot derive a module name from " + path.Literal + ", name it with as", path)
+            return nil
+        }
+        return &ast.ImportStatement{Path: path.Literal, Name: name, PosInfo: p.tokToPos(importToken)}
     }
 
     name := p.advance()
@@ -348,7 +576,84 @@ func (p *Parser) parseImport() *ast.ImportStatement {
         return nil
     }
 
+    return &ast.ImportStatement{Path: path.Literal, Name: name.Literal, PosInfo: p.tokToPos(importToken)}
+}
+
+// parseFromImport parses from "path" import a, b as c, its grouped form from "path" import (a, b as c)
+// or the wildcard form from "path" import *
+func (p *Parser) parseFromImport() ast.Statement {
+    fromToken := p.advance()
+
+    path := p.advance()
+    if path.Type != token.STRING {
+        p.pushNewError("expected string", path)
+        return nil
+    }
+
+    if !p.match(token.IMPORT) {
+        p.pushNewError("expected import", p.peek())
+        return nil
+    }
+
+    if p.match(token.MULT) {
+        if p.is(token.COMMA) || p.is(token.AS) {
+            p.pushNewError("cannot import other names or an alias with *", p.peek())
+            return nil
+        }
+        p.match(token.SEMICOLON)
+        return &ast.ImportStatement{Path: path.Literal, Wildcard: true, PosInfo: p.tokToPos(fromToken)}
+    }
+
+    grouped := p.match(token.LPAREN)
+    names := []ast.ImportName{}
+    for {
+        if grouped && p.is(token.RPAREN) {
+            break
+        }
+        name := p.advance()
+        if name.Type != token.IDENTIFIER {
+            p.pushNewError("expected identifier", name)
+            return nil
+        }
+        imported := ast.ImportName{Name: name.Literal}
+        if p.match(token.AS) {
+            alias := p.advance()
+            if alias.Type != token.IDENTIFIER {
+                p.pushNewError("expected identifier", alias)
+                return nil
+            }
+            imported.Alias = alias.Literal
+        }
+        names = append(names, imported)
+        if !p.match(token.COMMA) {
+            break
+        }
+    }
+    if grouped && !p.match(token.RPAREN) {
+        p.pushNewError("expected )", p.peek())
+        return nil
+    }
+    if len(names) == 0 {
+        p.pushNewError("expected at least one name to import", p.peek())
+        return nil
+    }
+
     p.match(token.SEMICOLON)
 
-    return &ast.ImportStatement{Path: path.Literal, Name: name.Literal, PosInfo: p.tokToPos(importToken)}
+    return &ast.ImportStatement{Path: path.Literal, Names: names, PosInfo: p.tokToPos(fromToken)}
+}
+
+// moduleName returns the file name of path without its extension if it is a valid identifier
+func moduleName(path string) (string, bool) {
+    base := filepath.Base(filepath.FromSlash(path))
+    name := strings.TrimSuffix(base, filepath.Ext(base))
+    if name == "" || token.TypeFromIdent(name) != token.IDENTIFIER {
+        return "", false
+    }
+    for i, r := range name {
+        if !unicode.IsLetter(r) && (i == 0 || (r != '_' && !unicode.IsDigit(r))) {
+            return "", false
+        }
+    }
+    return name, true
 }
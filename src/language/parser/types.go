@@ -0,0 +1,37 @@
+package parser
+
+import (
+    "language/ast"
+    "language/token"
+)
+
+// typeAnnotation parses the type after the colon of a declaration, like int, int? or string | array,
+// the types are the ones of type patterns and T? is short for T | null
+func (p *Parser) typeAnnotation() *ast.TypeAnnotation {
+    annotation := &ast.TypeAnnotation{PosInfo: p.tokToPos(p.peek())}
+    for {
+        typeName := p.advance()
+        if typeName.Type == token.NULL {
+            typeName.Literal = "null"
+        } else if typeName.Type != token.IDENTIFIER || !patternTypeNames[typeName.Literal] {
+            p.pushNewError("expected type name", typeName)
+            return nil
+        }
+        annotation.Names = append(annotation.Names, typeName.Literal)
+        if p.match(token.QUESTION) {
+            annotation.Names = append(annotation.Names, "null")
+        }
+        if !p.match(token.BITOR) {
+            return annotation
+        }
+    }
+}
+
+// optionalTypeAnnotation parses a type annotation if the next token is a colon
+func (p *Parser) optionalTypeAnnotation() (*ast.TypeAnnotation, bool) {
+    if !p.match(token.COLON) {
+        return nil, true
+    }
+    annotation := p.typeAnnotation()
+    return annotation, annotation != nil
+}
@@ -0,0 +1,134 @@
+package project
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+const (
+    MANIFEST_FILE = "fml.json"
+    LOCK_FILE = "fml.lock"
+    VENDOR_DIR = "vendor"
+    DEFAULT_VERSION = "0.1.0"
+)
+
+// Dependency is a library used by a project, Path is a local directory relative to the manifest
+// and can be omitted if the sources are already vendored
+type Dependency struct {
+    Version string `json:"version,omitempty"`
+    Path string `json:"path,omitempty"`
+}
+
+// Manifest describes a project, it is read from the fml.json in the project root
+type Manifest struct {
+    Name string `json:"name"`
+    Version string `json:"version"`
+    Dependencies map[string]Dependency `json:"dependencies,omitempty"`
+    // Paths are searched for imports, they are relative to the project root
+    Paths []string `json:"paths,omitempty"`
+    // DisabledChecks are the rules of fml check which are not reported for the project
+    DisabledChecks []string `json:"disabledChecks,omitempty"`
+    // ReadOnlyModules forbids importers to assign to the variables of the modules of the project
+    ReadOnlyModules bool `json:"readOnlyModules,omitempty"`
+    Root string `json:"-"`
+}
+
+func New(name string, root string) *Manifest {
+    return &Manifest{Name: name, Version: DEFAULT_VERSION, Root: root}
+}
+
+func Load(path string) (*Manifest, error) {
+    content, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    manifest := &Manifest{}
+    if err := json.Unmarshal(content, manifest); err != nil {
+        return nil, fmt.Errorf("invalid manifest %s: %s", path, err)
+    }
+    if manifest.Name == "" {
+        return nil, fmt.Errorf("invalid manifest %s: the name is missing", path)
+    }
+    for name := range manifest.Dependencies {
+        if name == "" || strings.ContainsAny(name, "/\\") || name == "." || name == ".." {
+            return nil, fmt.Errorf("invalid manifest %s: invalid dependency name \"%s\"", path, name)
+        }
+    }
+    manifest.Root = filepath.Dir(path)
+    return manifest, nil
+}
+
+// Find loads the manifest of the project dir belongs to, it returns nil if dir is not part of a project
+func Find(dir string) (*Manifest, error) {
+    dir, err := filepath.Abs(dir)
+    if err != nil {
+        return nil, err
+    }
+    for {
+        path := filepath.Join(dir, MANIFEST_FILE)
+        if info, err := os.Stat(path); err == nil && !info.IsDir() {
+            return Load(path)
+        }
+        parent := filepath.Dir(dir)
+        if parent == dir {
+            return nil, nil
+        }
+        dir = parent
+    }
+}
+
+func (m *Manifest) Save() error {
+    content, err := json.MarshalIndent(m, "", "    ")
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(filepath.Join(m.Root, MANIFEST_FILE), append(content, '\n'), 0644)
+}
+
+// DependencyRoot returns the directory holding the sources of a dependency, vendored sources are preferred
+func (m *Manifest) DependencyRoot(name string) (string, error) {
+    dependency, ok := m.Dependencies[name]
+    if !ok {
+        return "", fmt.Errorf("unknown dependency %s", name)
+    }
+    vendored := filepath.Join(m.Root, VENDOR_DIR, name)
+    if info, err := os.Stat(vendored); err == nil && info.IsDir() {
+        return vendored, nil
+    }
+    if dependency.Path == "" {
+        return "", fmt.Errorf("dependency %s has no path and is not vendored", name)
+    }
+    return m.dependencyPath(dependency), nil
+}
+
+func (m *Manifest) dependencyPath(dependency Dependency) string {
+    path := filepath.FromSlash(dependency.Path)
+    if filepath.IsAbs(path) {
+        return path
+    }
+    return filepath.Join(m.Root, path)
+}
+
+// Resolve looks up an import path, a path starting with the name of a dependency is resolved inside of it,
+// otherwise the search paths are tried in order
+func (m *Manifest) Resolve(path string) (string, bool) {
+    parts := strings.SplitN(filepath.ToSlash(path), "/", 2)
+    if _, ok := m.Dependencies[parts[0]]; ok && len(parts) == 2 {
+        root, err := m.DependencyRoot(parts[0])
+        if err != nil {
+            return "", false
+        }
+        return filepath.Join(root, filepath.FromSlash(parts[1])), true
+    }
+    for _, searchPath := range m.Paths {
+        candidate := filepath.Join(m.Root, filepath.FromSlash(searchPath), filepath.FromSlash(path))
+        if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+            return candidate, true
+        }
+    }
+    return "", false
+}
@@ -0,0 +1,142 @@
+package project
+
+import (
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+    for name, content := range files {
+        path := filepath.Join(root, filepath.FromSlash(name))
+        if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+            t.Fatalf("could not create directory: %s", err)
+        }
+        if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+            t.Fatalf("could not write file: %s", err)
+        }
+    }
+}
+
+func tempDir(t *testing.T) string {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    return dir
+}
+
+func TestManifest(t *testing.T) {
+    dir := tempDir(t)
+    defer os.RemoveAll(dir)
+    writeFiles(t, dir, map[string]string{
+        "app/fml.json": `{"name": "app", "version": "1.0.0", "paths": ["lib"], "dependencies": {"re": {"path": "../regexp"}, "local": {}}}`,
+        "app/lib/util.fml": "",
+        "app/src/main.fml": "",
+        "app/vendor/local/local.fml": "",
+        "regexp/re.fml": "",
+    })
+
+    manifest, err := Find(filepath.Join(dir, "app", "src"))
+    if err != nil {
+        t.Fatalf("could not find manifest: %s", err)
+    }
+    if manifest == nil || manifest.Name != "app" || manifest.Version != "1.0.0" {
+        t.Fatalf("expected manifest of app but got %v", manifest)
+    }
+
+    tests := []struct {
+        path string
+        expected string
+        ok bool
+    }{
+        {"re/re.fml", filepath.Join(dir, "regexp", "re.fml"), true},
+        {"local/local.fml", filepath.Join(dir, "app", "vendor", "local", "local.fml"), true},
+        {"util.fml", filepath.Join(dir, "app", "lib", "util.fml"), true},
+        {"missing.fml", "", false},
+        {"re", "", false},
+    }
+    for _, tt := range tests {
+        resolved, ok := manifest.Resolve(tt.path)
+        if ok != tt.ok || resolved != tt.expected {
+            t.Fatalf("expected %s to resolve to %s (%t) but got %s (%t)", tt.path, tt.expected, tt.ok, resolved, ok)
+        }
+    }
+
+    noProject, err := Find(dir)
+    if err != nil || noProject != nil {
+        t.Fatalf("expected no manifest but got %v, %v", noProject, err)
+    }
+}
+
+func TestInvalidManifest(t *testing.T) {
+    dir := tempDir(t)
+    defer os.RemoveAll(dir)
+    tests := []struct {
+        content string
+        expected string
+    }{
+        {`{"version": "1.0.0"}`, "invalid manifest " + filepath.Join(dir, MANIFEST_FILE) + ": the name is missing"},
+        {`{"name": "app", "dependencies": {"a/b": {}}}`, "invalid manifest " + filepath.Join(dir, MANIFEST_FILE) + ": invalid dependency name \"a/b\""},
+    }
+    for _, tt := range tests {
+        writeFiles(t, dir, map[string]string{MANIFEST_FILE: tt.content})
+        _, err := Find(dir)
+        if err == nil || err.Error() != tt.expected {
+            t.Fatalf("expected error %s but got %v", tt.expected, err)
+        }
+    }
+}
+
+func TestVendor(t *testing.T) {
+    dir := tempDir(t)
+    defer os.RemoveAll(dir)
+    writeFiles(t, dir, map[string]string{
+        "regexp/re.fml": "export let re = 1;",
+        "regexp/sub/util.fml": "let util = 2;",
+    })
+    manifest := New("app", filepath.Join(dir, "app"))
+    manifest.Dependencies = map[string]Dependency{"re": Dependency{Version: "1.0.0", Path: "../regexp"}}
+    if err := os.MkdirAll(manifest.Root, 0755); err != nil {
+        t.Fatalf("could not create directory: %s", err)
+    }
+    if err := manifest.Save(); err != nil {
+        t.Fatalf("could not save manifest: %s", err)
+    }
+
+    lock, err := manifest.Vendor()
+    if err != nil {
+        t.Fatalf("could not vendor: %s", err)
+    }
+    if _, err := os.Stat(filepath.Join(manifest.Root, VENDOR_DIR, "re", "sub", "util.fml")); err != nil {
+        t.Fatalf("expected vendored file: %s", err)
+    }
+    sourceHash, err := HashDir(filepath.Join(dir, "regexp"))
+    if err != nil {
+        t.Fatalf("could not hash: %s", err)
+    }
+    if lock.Dependencies["re"].Hash != sourceHash || lock.Dependencies["re"].Version != "1.0.0" {
+        t.Fatalf("expected locked hash %s but got %v", sourceHash, lock.Dependencies["re"])
+    }
+
+    // vendored sources are used even if the path is gone
+    if err := os.RemoveAll(filepath.Join(dir, "regexp")); err != nil {
+        t.Fatalf("could not remove sources: %s", err)
+    }
+    loaded, err := Find(manifest.Root)
+    if err != nil {
+        t.Fatalf("could not find manifest: %s", err)
+    }
+    if resolved, ok := loaded.Resolve("re/re.fml"); !ok || resolved != filepath.Join(manifest.Root, VENDOR_DIR, "re", "re.fml") {
+        t.Fatalf("expected vendored path but got %s", resolved)
+    }
+    if err := loaded.Verify(); err != nil {
+        t.Fatalf("expected vendored sources to match: %s", err)
+    }
+
+    writeFiles(t, manifest.Root, map[string]string{"vendor/re/re.fml": "export let re = 2;"})
+    if err := loaded.Verify(); err == nil {
+        t.Fatalf("expected modified sources to fail the verification")
+    }
+}
@@ -0,0 +1,182 @@
+package project
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+// LockedDependency pins the content of a dependency, Hash covers all files of its sources
+type LockedDependency struct {
+    Version string `json:"version,omitempty"`
+    Hash string `json:"hash"`
+}
+
+// Lock is read from and written to the fml.lock in the project root
+type Lock struct {
+    Dependencies map[string]LockedDependency `json:"dependencies"`
+}
+
+func LoadLock(root string) (*Lock, error) {
+    content, err := ioutil.ReadFile(filepath.Join(root, LOCK_FILE))
+    if err != nil {
+        return nil, err
+    }
+    lock := &Lock{}
+    if err := json.Unmarshal(content, lock); err != nil {
+        return nil, fmt.Errorf("invalid lock file: %s", err)
+    }
+    return lock, nil
+}
+
+func (l *Lock) Save(root string) error {
+    content, err := json.MarshalIndent(l, "", "    ")
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(filepath.Join(root, LOCK_FILE), append(content, '\n'), 0644)
+}
+
+// Vendor copies the sources of all dependencies with a path into the vendor directory and writes the lock file,
+// dependencies without a path have to be vendored already
+func (m *Manifest) Vendor() (*Lock, error) {
+    lock := &Lock{Dependencies: map[string]LockedDependency{}}
+    for _, name := range m.dependencyNames() {
+        dependency := m.Dependencies[name]
+        vendored := filepath.Join(m.Root, VENDOR_DIR, name)
+        if dependency.Path != "" {
+            source := m.dependencyPath(dependency)
+            if info, err := os.Stat(source); err != nil || !info.IsDir() {
+                return nil, fmt.Errorf("dependency %s: %s is no directory", name, source)
+            }
+            if relative, err := filepath.Rel(source, vendored); err == nil && !strings.HasPrefix(relative, "..") {
+                return nil, fmt.Errorf("dependency %s: %s contains the vendor directory", name, source)
+            }
+            if err := os.RemoveAll(vendored); err != nil {
+                return nil, err
+            }
+            if err := copyDir(source, vendored); err != nil {
+                return nil, fmt.Errorf("dependency %s: %s", name, err)
+            }
+        } else if info, err := os.Stat(vendored); err != nil || !info.IsDir() {
+            return nil, fmt.Errorf("dependency %s has no path and is not vendored", name)
+        }
+        hash, err := HashDir(vendored)
+        if err != nil {
+            return nil, fmt.Errorf("dependency %s: %s", name, err)
+        }
+        lock.Dependencies[name] = LockedDependency{Version: dependency.Version, Hash: hash}
+    }
+    if err := lock.Save(m.Root); err != nil {
+        return nil, err
+    }
+    return lock, nil
+}
+
+// Verify checks that the vendored sources match the hashes of the lock file
+func (m *Manifest) Verify() error {
+    lock, err := LoadLock(m.Root)
+    if os.IsNotExist(err) {
+        return fmt.Errorf("%s not found, run mod vendor", LOCK_FILE)
+    }
+    if err != nil {
+        return err
+    }
+    for _, name := range m.dependencyNames() {
+        locked, ok := lock.Dependencies[name]
+        if !ok {
+            return fmt.Errorf("dependency %s is missing in %s, run mod vendor", name, LOCK_FILE)
+        }
+        hash, err := HashDir(filepath.Join(m.Root, VENDOR_DIR, name))
+        if err != nil {
+            return fmt.Errorf("dependency %s: %s", name, err)
+        }
+        if hash != locked.Hash {
+            return fmt.Errorf("dependency %s was modified, expected %s but got %s", name, locked.Hash, hash)
+        }
+    }
+    return nil
+}
+
+func (m *Manifest) dependencyNames() []string {
+    names := []string{}
+    for name := range m.Dependencies {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// HashDir hashes the relative paths and contents of all files below dir
+func HashDir(dir string) (string, error) {
+    files := []string{}
+    err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+        if err != nil {
+            return err
+        }
+        if !info.IsDir() {
+            files = append(files, path)
+        }
+        return nil
+    })
+    if err != nil {
+        return "", err
+    }
+    sort.Strings(files)
+
+    hash := sha256.New()
+    for _, path := range files {
+        relative, err := filepath.Rel(dir, path)
+        if err != nil {
+            return "", err
+        }
+        content, err := ioutil.ReadFile(path)
+        if err != nil {
+            return "", err
+        }
+        fmt.Fprintf(hash, "%s\x00%d\x00", filepath.ToSlash(relative), len(content))
+        hash.Write(content)
+    }
+    return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func copyDir(source string, target string) error {
+    return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+        if err != nil {
+            return err
+        }
+        relative, err := filepath.Rel(source, path)
+        if err != nil {
+            return err
+        }
+        destination := filepath.Join(target, relative)
+        if info.IsDir() {
+            return os.MkdirAll(destination, 0755)
+        }
+        return copyFile(path, destination, info.Mode())
+    })
+}
+
+func copyFile(source string, target string, mode os.FileMode) error {
+    in, err := os.Open(source)
+    if err != nil {
+        return err
+    }
+    defer in.Close()
+    out, err := os.OpenFile(target, os.O_CREATE | os.O_WRONLY | os.O_TRUNC, mode)
+    if err != nil {
+        return err
+    }
+    if _, err := io.Copy(out, in); err != nil {
+        out.Close()
+        return err
+    }
+    return out.Close()
+}
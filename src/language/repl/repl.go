@@ -16,7 +16,7 @@ import (
 
 func Start(in io.Reader, out io.Writer) {
     // signal interrupt code
-    channel := make(chan os.Signal)
+    channel := make(chan os.Signal, 1)
     signal.Notify(channel, os.Interrupt, syscall.SIGTERM)
     go func() {
         <- channel
@@ -46,6 +46,7 @@ func Start(in io.Reader, out io.Writer) {
             printErrors(errors, out)
             continue
         }
+        printWarnings(program.Warnings, out)
 
         evaluate(program, environment, out)
     }
@@ -75,4 +76,10 @@ func printErrors(errors []error, out io.Writer) {
     }
 }
 
+func printWarnings(warnings []string, out io.Writer) {
+    for _, warning := range warnings {
+        io.WriteString(out, "\t"+warning+"\n")
+    }
+}
+
 const PROMPT = "> "
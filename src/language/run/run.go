@@ -2,43 +2,91 @@ package run
 
 import (
     "fmt"
+    "io"
+    "os"
     "path/filepath"
     "language/eval"
     "language/object"
     "language/ast"
     "language/frontend"
+    "language/project"
 )
 
-func Run(path string) {
+const (
+    // INLINE_PATH is the path of code given with -e
+    INLINE_PATH = "<inline>"
+    // STDIN_PATH is the path of code read from stdin
+    STDIN_PATH = "<stdin>"
+)
+
+// ERRORS is where parser and uncaught runtime errors are printed to
+var ERRORS io.Writer = os.Stderr
+
+// Run evaluates the file at path with the script arguments args and returns the exit code of the process
+func Run(path string, args []string) int {
     program, errors := frontend.Build(path)
 
     if len(errors) > 0 {
         printErrors(errors)
-        return
+        return 1
+    }
+    absPath, err := filepath.Abs(path)
+    if err != nil {
+        printErrors([]error{err})
+        return 1
     }
-    evaluate(program, path)
+    return evaluate(program, absPath, filepath.Dir(absPath), args)
 }
 
-func evaluate(program *ast.Program, path string) {
-    absPath, err := filepath.Abs(path)
+// RunCode evaluates code which is not read from a file, its imports are relative to the working directory
+func RunCode(code string, path string, args []string) int {
+    program, errors := frontend.BuildCode(code, path)
+
+    if len(errors) > 0 {
+        printErrors(errors)
+        return 1
+    }
+    cwd, err := os.Getwd()
     if err != nil {
-        fmt.Printf(err.Error())
-        return
+        printErrors([]error{err})
+        return 1
     }
-    path = absPath
-    eval.MODULEPATH = filepath.Dir(path)
+    return evaluate(program, path, cwd, args)
+}
+
+func evaluate(program *ast.Program, path string, dir string, args []string) int {
+    _, result := evaluateModule(program, path, dir, args)
+    if isError(result) {
+        fmt.Fprintf(ERRORS, "\t%s\n", result.String())
+        return 1
+    }
+    return 0
+}
+
+// evaluateModule runs program as the main module and returns it with the result
+func evaluateModule(program *ast.Program, path string, dir string, args []string) (*object.Module, object.Object) {
+    eval.MODULEPATH = dir
+    eval.ARGS = args
+    manifest, err := project.Find(dir)
+    if err != nil {
+        return nil, &object.Error{Message: err.Error()}
+    }
+    eval.PROJECT = manifest
     mainModuleEnv := object.NewEnvironment()
-    mainModule := &object.Module{Path: path, Env: mainModuleEnv}
+    mainModule := &object.Module{Path: path, Env: mainModuleEnv, Loading: true}
     modules := make(map[string]*object.Module)
     modules[path] = mainModule
     result := eval.Eval(program, mainModuleEnv, modules)
-    if result.Type() == object.ERROR_OBJECT || result.Type() == object.PARSER_ERRORS_OBJECT {
-        fmt.Printf("\t%s\n", result.String())
-    }
+    mainModule.Loading = false
+    return mainModule, result
+}
+
+func isError(result object.Object) bool {
+    return result.Type() == object.ERROR_OBJECT || result.Type() == object.PARSER_ERRORS_OBJECT
 }
 
 func printErrors(errors []error) {
     for _, msg := range errors {
-        fmt.Printf("\t%s\n", msg.Error())
+        fmt.Fprintf(ERRORS, "\t%s\n", msg.Error())
     }
 }
@@ -0,0 +1,186 @@
+package run
+
+import (
+    "bytes"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "testing"
+    "language/eval"
+    "language/frontend"
+)
+
+func TestExitCodes(t *testing.T) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    defer os.RemoveAll(dir)
+    frontend.CACHE = false
+    var errors bytes.Buffer
+    ERRORS = &errors
+    defer func() {
+        ERRORS = os.Stderr
+    }()
+    files := map[string]string{
+        "ok.fml": "import \"os\"; if len(os.args) != 2 { error(\"expected 2 arguments\"); }",
+        "runtime.fml": "let a = 1 / 0;",
+        "parser.fml": "let = 1;",
+    }
+    for name, code := range files {
+        if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(code), 0644); err != nil {
+            t.Fatalf("could not write script: %s", err)
+        }
+    }
+
+    tests := []struct {
+        run func() int
+        expected int
+        message string
+    }{
+        {func() int { return Run(filepath.Join(dir, "ok.fml"), []string{"a", "b"}) }, 0, ""},
+        {func() int { return Run(filepath.Join(dir, "ok.fml"), []string{}) }, 1, "expected 2 arguments"},
+        {func() int { return Run(filepath.Join(dir, "runtime.fml"), []string{}) }, 1, "division by zero"},
+        {func() int { return Run(filepath.Join(dir, "parser.fml"), []string{}) }, 1, "Expected an identifier"},
+        {func() int { return Run(filepath.Join(dir, "missing.fml"), []string{}) }, 1, "no such file"},
+        {func() int { return RunCode("import \"os\"; let a = os.args[0] + 1;", INLINE_PATH, []string{"x"}) }, 1, INLINE_PATH},
+        {func() int { return RunCode("let a = 1;", STDIN_PATH, []string{}) }, 0, ""},
+    }
+
+    for i, tt := range tests {
+        errors.Reset()
+        code := tt.run()
+        if code != tt.expected {
+            t.Fatalf("test %d: expected exit code %d but got %d: %s", i, tt.expected, code, errors.String())
+        }
+        if tt.message == "" && errors.Len() > 0 || !strings.Contains(errors.String(), tt.message) {
+            t.Fatalf("test %d: expected error containing \"%s\" but got \"%s\"", i, tt.message, errors.String())
+        }
+    }
+}
+
+func TestRunTests(t *testing.T) {
+    dir, err := ioutil.TempDir("", "fml")
+    if err != nil {
+        t.Fatalf("could not create temporary directory: %s", err)
+    }
+    defer os.RemoveAll(dir)
+    frontend.CACHE = false
+    files := map[string]string{
+        "a_test.fml": "let helper = 1; fun testPass() { return helper; } fun testFail() { error(\"failed\"); } fun other() { error(\"no test\"); }",
+        "b.fml": "fun testIgnored() {}",
+        "sub/c_test.fml": "let a = 1 / 0;",
+    }
+    for name, code := range files {
+        path := filepath.Join(dir, filepath.FromSlash(name))
+        os.MkdirAll(filepath.Dir(path), 0755)
+        if err := ioutil.WriteFile(path, []byte(code), 0644); err != nil {
+            t.Fatalf("could not write script: %s", err)
+        }
+    }
+
+    tests, err := FindTests([]string{dir})
+    if err != nil {
+        t.Fatalf("could not find tests: %s", err)
+    }
+    expected := []string{filepath.Join(dir, "a_test.fml"), filepath.Join(dir, "sub", "c_test.fml")}
+    if strings.Join(tests, ",") != strings.Join(expected, ",") {
+        t.Fatalf("expected test files %v but got %v", expected, tests)
+    }
+
+    results := RunTests(tests[0])
+    if len(results) != 2 || results[0].Name != "testFail" || results[0].Passed() || !strings.Contains(results[0].Error, "failed") || results[1].Name != "testPass" || !results[1].Passed() {
+        t.Fatalf("expected testFail to fail and testPass to pass but got %v", results)
+    }
+    results = RunTests(tests[1])
+    if len(results) != 1 || results[0].Name != "<module>" || !strings.Contains(results[0].Error, "division by zero") {
+        t.Fatalf("expected the module to fail but got %v", results)
+    }
+}
+
+// TestExamples runs the example programs and compares their output with examples/expected
+func TestExamples(t *testing.T) {
+    frontend.CACHE = false
+    corelibrary, _ := filepath.Abs(filepath.Join("..", "corelibrary"))
+    oldFmlpath := eval.FMLPATH
+    eval.FMLPATH = corelibrary
+    var errors bytes.Buffer
+    ERRORS = &errors
+    defer func() {
+        eval.FMLPATH = oldFmlpath
+        ERRORS = os.Stderr
+    }()
+    // examples which are not run, with the reason
+    skipped := map[string]string{
+        "hangman.fml": "reads from the terminal",
+        "os.fml": "prints the working directory and the environment",
+        "project_euler_005.fml": "takes minutes",
+        "project_euler_010.fml": "takes minutes",
+    }
+    // examples iterating hashes, their lines are compared in any order
+    unordered := map[string]bool{
+        "loops.fml": true,
+    }
+    // examples ending with an uncaught error on purpose
+    exitCodes := map[string]int{
+        "exceptionhandling.fml": 1,
+    }
+
+    examples := filepath.Join("..", "examples")
+    paths, _ := filepath.Glob(filepath.Join(examples, "*.fml"))
+    mains, _ := filepath.Glob(filepath.Join(examples, "*", "main.fml"))
+    paths = append(paths, mains...)
+    if len(paths) == 0 {
+        t.Fatalf("expected example programs")
+    }
+    for _, path := range paths {
+        name, _ := filepath.Rel(examples, path)
+        name = filepath.ToSlash(name)
+        if _, ok := skipped[name]; ok {
+            continue
+        }
+        expected, err := ioutil.ReadFile(filepath.Join(examples, "expected", strings.TrimSuffix(name, ".fml") + ".out"))
+        if err != nil {
+            t.Errorf("missing the expected output of %s: %s", name, err)
+            continue
+        }
+        errors.Reset()
+        output, code := captureStdout(t, func() int { return Run(path, []string{}) })
+        if code != exitCodes[name] {
+            t.Errorf("expected %s to exit with %d but got %d: %s", name, exitCodes[name], code, errors.String())
+        }
+        if unordered[name] {
+            output, expected = sortedLines(output), []byte(sortedLines(string(expected)))
+        }
+        if output != string(expected) {
+            t.Errorf("expected the output of %s to be\n%s\nbut got\n%s", name, expected, output)
+        }
+    }
+}
+
+func sortedLines(text string) string {
+    lines := strings.Split(text, "\n")
+    sort.Strings(lines)
+    return strings.Join(lines, "\n")
+}
+
+// captureStdout returns what run prints to stdout
+func captureStdout(t *testing.T, run func() int) (string, int) {
+    reader, writer, err := os.Pipe()
+    if err != nil {
+        t.Fatalf("could not create pipe: %s", err)
+    }
+    stdout := os.Stdout
+    os.Stdout = writer
+    output := make(chan string)
+    go func() {
+        content, _ := ioutil.ReadAll(reader)
+        output <- string(content)
+    }()
+    code := run()
+    os.Stdout = stdout
+    writer.Close()
+    return <-output, code
+}
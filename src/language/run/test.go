@@ -0,0 +1,93 @@
+package run
+
+import (
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "language/ast"
+    "language/eval"
+    "language/frontend"
+    "language/object"
+)
+
+const (
+    // TEST_SUFFIX ends the names of test files
+    TEST_SUFFIX = "_test.fml"
+    // TEST_PREFIX starts the names of test functions
+    TEST_PREFIX = "test"
+)
+
+// TestResult is the outcome of a test function, Error is empty if it passed
+type TestResult struct {
+    Path string
+    Name string
+    Error string
+}
+
+func (r TestResult) Passed() bool {
+    return r.Error == ""
+}
+
+// FindTests returns the test files of paths, directories are searched recursively
+func FindTests(paths []string) ([]string, error) {
+    files := []string{}
+    for _, path := range paths {
+        info, err := os.Stat(path)
+        if err != nil {
+            return nil, err
+        }
+        if !info.IsDir() {
+            files = append(files, path)
+            continue
+        }
+        err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+            if err != nil {
+                return err
+            }
+            if !info.IsDir() && strings.HasSuffix(file, TEST_SUFFIX) {
+                files = append(files, file)
+            }
+            return nil
+        })
+        if err != nil {
+            return nil, err
+        }
+    }
+    sort.Strings(files)
+    return files, nil
+}
+
+// RunTests runs the test file at path and then each of its functions whose name starts with test,
+// a test fails with an uncaught error
+func RunTests(path string) []TestResult {
+    absPath, err := filepath.Abs(path)
+    if err != nil {
+        return []TestResult{{Path: path, Name: "<module>", Error: err.Error()}}
+    }
+    program, errors := frontend.Build(absPath)
+    if len(errors) > 0 {
+        return []TestResult{{Path: absPath, Name: "<module>", Error: (&object.ParserErrors{Errors: errors}).String()}}
+    }
+    module, result := evaluateModule(program, absPath, filepath.Dir(absPath), []string{})
+    if isError(result) {
+        return []TestResult{{Path: absPath, Name: "<module>", Error: result.String()}}
+    }
+
+    results := []TestResult{}
+    modules := map[string]*object.Module{absPath: module}
+    position := ast.PositionalInfo{Path: absPath}
+    for _, name := range module.Env.Names() {
+        value, _ := module.Env.Get(name)
+        if _, ok := value.(*object.Function); !ok || !strings.HasPrefix(name, TEST_PREFIX) {
+            continue
+        }
+        call := &ast.CallExpression{Function: &ast.IdentifierExpression{Name: name, PosInfo: position}, Arguments: []ast.Expression{}, PosInfo: position}
+        testResult := TestResult{Path: absPath, Name: name}
+        if result := eval.Eval(call, module.Env, modules); isError(result) {
+            testResult.Error = result.String()
+        }
+        results = append(results, testResult)
+    }
+    return results
+}
@@ -6,6 +6,7 @@ import (
     "strconv"
     "unicode"
     "errors"
+    "strings"
     "language/token"
 )
 
@@ -16,6 +17,11 @@ type Scanner struct {
     start_last_line int
     line_counter int
     filepath string
+    // doc are the lines of the /// comments before the next token, docLine is the line of the last one
+    doc []string
+    docLine int
+    moduleDoc string
+    scannedToken bool
 }
 
 const nullString string = "\x00"
@@ -24,7 +30,28 @@ func New(sourcecode string) *Scanner {
     return &Scanner{sourcecode: []rune(sourcecode), start_idx: 0, current_idx: 0, start_last_line: 0, line_counter: 1}
 }
 
+// NextToken returns the next token, the /// comments on the lines directly before it are its Doc
 func (s *Scanner) NextToken() token.Token {
+    result := s.scanToken()
+    if len(s.doc) > 0 {
+        doc := strings.Join(s.doc, "\n")
+        if result.Line == s.docLine + 1 {
+            result.Doc = doc
+        } else if !s.scannedToken {
+            s.moduleDoc = doc
+        }
+        s.doc = nil
+    }
+    s.scannedToken = true
+    return result
+}
+
+// ModuleDoc returns the /// comments at the start of the file which are separated from the first token by an empty line
+func (s *Scanner) ModuleDoc() string {
+    return s.moduleDoc
+}
+
+func (s *Scanner) scanToken() token.Token {
     s.skipWhitespace()
 
     s.start_idx = s.current_idx
@@ -48,21 +75,40 @@ func (s *Scanner) NextToken() token.Token {
         if s.match("=") {
             return s.createToken(token.MULTASSIGN)
         }
+        if s.match("*") {
+            return s.createToken(token.POW)
+        }
         return s.createToken(token.MULT)
+    case "~":
+        if s.match("/") {
+            return s.createToken(token.FLOORDIV)
+        }
+        return s.createToken(token.BITNOT)
+    case "^":
+        if s.match("=") {
+            return s.createToken(token.BITXORASSIGN)
+        }
+        return s.createToken(token.BITXOR)
     case "/":
         if s.match("=") {
             return s.createToken(token.DIVASSIGN)
         }
         if s.match("/") {
+            if s.peek() == "/" && s.peek2() != "/" {
+                if err := s.readDocComment(); err != nil {
+                    return s.createError(err.Error())
+                }
+                return s.scanToken()
+            }
             if err := s.readLineComment(); err != nil {
                 return s.createError(err.Error())
             }
-            return s.NextToken()
+            return s.scanToken()
         } else if s.match("*") {
             if err := s.readNestedMultilineComment(); err != nil {
                 return s.createError(err.Error())
             }
-            return s.NextToken()
+            return s.scanToken()
         }
         return s.createToken(token.DIV)
     case "%":
@@ -74,6 +120,9 @@ func (s *Scanner) NextToken() token.Token {
         if s.match("=") {
             return s.createToken(token.EQ)
         }
+        if s.match(">") {
+            return s.createToken(token.ARROW)
+        }
         return s.createToken(token.ASSIGN)
     case "!":
         if s.match("=") {
@@ -81,25 +130,43 @@ func (s *Scanner) NextToken() token.Token {
         }
         return s.createToken(token.NEG)
     case "<":
+        if s.match("<") {
+            if s.match("=") {
+                return s.createToken(token.SHLASSIGN)
+            }
+            return s.createToken(token.SHL)
+        }
         if s.match("=") {
             return s.createToken(token.LE)
         }
         return s.createToken(token.LT)
     case ">":
+        if s.match(">") {
+            if s.match("=") {
+                return s.createToken(token.SHRASSIGN)
+            }
+            return s.createToken(token.SHR)
+        }
         if s.match("=") {
             return s.createToken(token.GE)
         }
         return s.createToken(token.GT)
     case "&":
-        if !s.match("&") {
-            return s.createError("unexpectected &")
+        if s.match("&") {
+            return s.createToken(token.AND)
+        }
+        if s.match("=") {
+            return s.createToken(token.BITANDASSIGN)
         }
-        return s.createToken(token.AND)
+        return s.createToken(token.BITAND)
     case "|":
-        if !s.match("|") {
-            return s.createError("unexpected |")
+        if s.match("|") {
+            return s.createToken(token.OR)
+        }
+        if s.match("=") {
+            return s.createToken(token.BITORASSIGN)
         }
-        return s.createToken(token.OR)
+        return s.createToken(token.BITOR)
     case "(":
         return s.createToken(token.LPAREN)
     case ")":
@@ -114,9 +181,17 @@ func (s *Scanner) NextToken() token.Token {
         return s.createToken(token.RBRACE)
     case ".":
         if s.match(".") {
+            if s.match(".") {
+                return s.createToken(token.ELLIPSIS)
+            }
+            if s.match("=") {
+                return s.createToken(token.RANGEINCLUSIVE)
+            }
             return s.createToken(token.RANGE)
         }
         return s.createToken(token.DOT)
+    case "#":
+        return s.createToken(token.FROZEN)
     case ",":
         return s.createToken(token.COMMA)
     case ";":
@@ -144,8 +219,21 @@ func (s *Scanner) NextToken() token.Token {
     default:
         current_rune := []rune(current_character)[0]
         if unicode.IsDigit(current_rune) {
+            if current_character == "0" && s.readPrefixedNumber() {
+                if s.readNumberSuffix("n") {
+                    return s.createTokenWithLiteral(token.BIGINT)
+                }
+                return s.createTokenWithLiteral(token.INT)
+            }
             s.readNumber()
-            if s.readFloat() {
+            isFloat := s.readFloat()
+            if !isFloat && s.readNumberSuffix("n") {
+                return s.createTokenWithLiteral(token.BIGINT)
+            }
+            if s.readNumberSuffix("d") {
+                return s.createTokenWithLiteral(token.DECIMAL)
+            }
+            if isFloat {
                 return s.createTokenWithLiteral(token.FLOAT)
             }
             return s.createTokenWithLiteral(token.INT)
@@ -268,10 +356,28 @@ func (s *Scanner) currentColumn() int {
     return s.start_idx - s.start_last_line + 1
 }
 
+// readNumber reads digits, an underscore can separate two digits
 func (s *Scanner) readNumber() {
-    for s.isNum() {
+    for s.isNum() || (s.isUnderscore() && s.isNum2()) {
+        s.advance()
+    }
+}
+
+// readPrefixedNumber reads the rest of a 0x, 0b or 0o literal, the digits are validated by the parser
+func (s *Scanner) readPrefixedNumber() bool {
+    prefix := s.peek()
+    if prefix != "x" && prefix != "b" && prefix != "o" {
+        return false
+    }
+    s.advance()
+    isDigit := s.isNum
+    if prefix == "x" {
+        isDigit = s.isHex
+    }
+    for isDigit() || s.isUnderscore() {
         s.advance()
     }
+    return true
 }
 
 func (s *Scanner) readFloat() bool {
@@ -284,6 +390,19 @@ func (s *Scanner) readFloat() bool {
     return false
 }
 
+// readNumberSuffix consumes the suffix of a number literal, if it is not the start of an identifier
+func (s *Scanner) readNumberSuffix(suffix string) bool {
+    if s.peek() != suffix {
+        return false
+    }
+    next := []rune(s.peek2())[0]
+    if unicode.IsLetter(next) || unicode.IsDigit(next) || next == '_' {
+        return false
+    }
+    s.advance()
+    return true
+}
+
 func (s *Scanner) readIdentifier() {
     for s.isAlphaNumUnderscore() {
         s.advance()
@@ -362,6 +481,27 @@ func (s *Scanner) readLineComment() error {
     return nil
 }
 
+// readDocComment reads a /// comment, the comments of consecutive lines form one doc
+func (s *Scanner) readDocComment() error {
+    s.advance()
+    line := s.line_counter
+    start := s.current_idx
+    if err := s.readLineComment(); err != nil {
+        return err
+    }
+    text := strings.TrimSuffix(strings.TrimSuffix(string(s.sourcecode[start:s.current_idx]), "\n"), "\r")
+    text = strings.TrimPrefix(text, " ")
+    if len(s.doc) > 0 && line != s.docLine + 1 {
+        if !s.scannedToken && s.moduleDoc == "" {
+            s.moduleDoc = strings.Join(s.doc, "\n")
+        }
+        s.doc = nil
+    }
+    s.doc = append(s.doc, text)
+    s.docLine = line
+    return nil
+}
+
 func (s *Scanner) readNestedMultilineComment() error {
     for true {
         if s.isAtEnd() {
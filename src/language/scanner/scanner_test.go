@@ -36,6 +36,14 @@ func TestNextToken(t *testing.T) {
     += -= *= /= %=
     "\thello\\\"world\"\n"
     "\u263A"
+    0..=9 step 3
+    match [...] =>
+    a is b
+    ** ~/ *
+    12n 1.50d 3d 5nd 2dx
+    & | ^ ~ << >> &= |= ^= <<= >>= && ||
+    0xFF_ff 0b1010 0o17 1_000_000 1_000.5 0x1dn 0b11n 0b102
+    #[
     `
 
     tests := []struct {
@@ -107,6 +115,52 @@ func TestNextToken(t *testing.T) {
         {token.MODASSIGN, ""},
         {token.STRING, "\thello\\\"world\"\n"},
         {token.STRING, "\u263a"},
+        {token.INT, "0"},
+        {token.RANGEINCLUSIVE, ""},
+        {token.INT, "9"},
+        {token.STEP, ""},
+        {token.INT, "3"},
+        {token.MATCH, ""},
+        {token.LBRACKET, ""},
+        {token.ELLIPSIS, ""},
+        {token.RBRACKET, ""},
+        {token.ARROW, ""},
+        {token.IDENTIFIER, "a"},
+        {token.IS, ""},
+        {token.IDENTIFIER, "b"},
+        {token.POW, ""},
+        {token.FLOORDIV, ""},
+        {token.MULT, ""},
+        {token.BIGINT, "12n"},
+        {token.DECIMAL, "1.50d"},
+        {token.DECIMAL, "3d"},
+        {token.INT, "5"},
+        {token.IDENTIFIER, "nd"},
+        {token.INT, "2"},
+        {token.IDENTIFIER, "dx"},
+        {token.BITAND, ""},
+        {token.BITOR, ""},
+        {token.BITXOR, ""},
+        {token.BITNOT, ""},
+        {token.SHL, ""},
+        {token.SHR, ""},
+        {token.BITANDASSIGN, ""},
+        {token.BITORASSIGN, ""},
+        {token.BITXORASSIGN, ""},
+        {token.SHLASSIGN, ""},
+        {token.SHRASSIGN, ""},
+        {token.AND, ""},
+        {token.OR, ""},
+        {token.INT, "0xFF_ff"},
+        {token.INT, "0b1010"},
+        {token.INT, "0o17"},
+        {token.INT, "1_000_000"},
+        {token.FLOAT, "1_000.5"},
+        {token.BIGINT, "0x1dn"},
+        {token.BIGINT, "0b11n"},
+        {token.INT, "0b102"},
+        {token.FROZEN, ""},
+        {token.LBRACKET, ""},
     }
 
     scanner := New(input)
@@ -184,3 +238,28 @@ func TestPosition(t *testing.T) {
         t.Fatalf("EOF - Column was=%d, expected=%d", eofToken.Column, eofExpectedColumn)
     }
 }
+
+func TestDocComments(t *testing.T) {
+    input := "/// the module\n\n/// first line\n///\n/// second\nlet a = 1;\n//// not a doc\nlet b = 2;\n/// separated\n\nlet c = 3;\n"
+    expectedDocs := map[string]string{"a": "first line\n\nsecond", "b": "", "c": ""}
+
+    scanner := New(input)
+    lastKeyword := token.Token{}
+    for tok := scanner.NextToken(); tok.Type != token.EOF; tok = scanner.NextToken() {
+        if tok.Type == token.ERROR {
+            t.Fatalf("unexpected error %s", tok.Literal)
+        }
+        if tok.Type == token.IDENTIFIER {
+            if expected := expectedDocs[tok.Literal]; lastKeyword.Doc != expected {
+                t.Fatalf("expected doc %q for %s but got %q", expected, tok.Literal, lastKeyword.Doc)
+            }
+        } else if tok.Type == token.LET {
+            lastKeyword = tok
+        } else if tok.Doc != "" {
+            t.Fatalf("unexpected doc %q on %s", tok.Doc, tok.Type)
+        }
+    }
+    if scanner.ModuleDoc() != "the module" {
+        t.Fatalf("expected the module doc but got %q", scanner.ModuleDoc())
+    }
+}
@@ -7,6 +7,8 @@ type Token struct {
     Literal string
     Line int
     Column int
+    // Doc is the text of the /// comments directly before the token
+    Doc string
 }
 
 func New(the_type TokenType, literal string, line, column int) Token {
@@ -28,6 +30,8 @@ const (
     IDENTIFIER = "IDENTIFIER"
     INT = "INT"
     FLOAT = "FLOAT"
+    BIGINT = "BIGINT"
+    DECIMAL = "DECIMAL"
     STRING = "STRING"
     TRUE = "TRUE"
     FALSE = "FALSE"
@@ -41,6 +45,7 @@ const (
     IN = "IN"
     FUN = "FUN"
     RETURN = "RETURN"
+    YIELD = "YIELD"
     BREAK = "BREAK"
     CONTINUE = "CONTINUE"
     FOREVER = "FOREVER"
@@ -48,12 +53,24 @@ const (
     CATCH = "CATCH"
     IMPORT = "IMPORT"
     AS = "AS"
+    STEP = "STEP"
+    MATCH = "MATCH"
+    IS = "IS"
+    EXPORT = "EXPORT"
 
     ADD = "+"
     SUB = "-"
     MULT = "*"
     DIV = "/"
     MOD = "%"
+    POW = "**"
+    FLOORDIV = "~/"
+    BITAND = "&"
+    BITOR = "|"
+    BITXOR = "^"
+    BITNOT = "~"
+    SHL = "<<"
+    SHR = ">>"
     AND = "&&"
     OR = "||"
     EQ = "=="
@@ -71,11 +88,20 @@ const (
     NULLCOAL = "??"
     DOT = "."
     RANGE = ".."
+    RANGEINCLUSIVE = "..="
+    ELLIPSIS = "..."
+    ARROW = "=>"
+    FROZEN = "#"
     ADDASSIGN = "+="
     SUBASSIGN = "-="
     MULTASSIGN = "*="
     DIVASSIGN = "/="
     MODASSIGN = "%="
+    BITANDASSIGN = "&="
+    BITORASSIGN = "|="
+    BITXORASSIGN = "^="
+    SHLASSIGN = "<<="
+    SHRASSIGN = ">>="
 
     LPAREN = "("
     RPAREN = ")"
@@ -94,6 +120,7 @@ var reservedWords = map[string]TokenType{
     "in": IN,
     "fun": FUN,
     "return": RETURN,
+    "yield": YIELD,
     "break": BREAK,
     "continue": CONTINUE,
     "true": TRUE,
@@ -106,6 +133,20 @@ var reservedWords = map[string]TokenType{
     "catch": CATCH,
     "import": IMPORT,
     "as": AS,
+    "step": STEP,
+    "match": MATCH,
+    "is": IS,
+    "export": EXPORT,
+}
+
+// KeywordFromType returns the reserved word of a keyword token type
+func KeywordFromType(t TokenType) (string, bool) {
+    for word, wordType := range reservedWords {
+        if wordType == t && word != "and" && word != "or" {
+            return word, true
+        }
+    }
+    return "", false
 }
 
 func TypeFromIdent(value string) TokenType {